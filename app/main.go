@@ -5,6 +5,7 @@ import (
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/infrastructure/container"
 	"tugas_akhir_example/internal/infrastructure/mysql"
+	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
 	"tugas_akhir_example/internal/server/http"
@@ -22,6 +23,8 @@ func main() {
 	defer mysql.CloseDatabaseConnection(containerConf.Mysqldb)
 
 	utils.SetJWTSecretKey(containerConf.Apps.SecretJwt)
+	utils.SetJWTSessionRepository(repository.NewUserSessionRepository(containerConf.Mysqldb))
+	utils.SetUserRoleRepository(repository.NewUserRoleRepository(containerConf.Mysqldb))
 
 	app := fiber.New()
 	app.Use(logger.New())
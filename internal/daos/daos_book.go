@@ -14,4 +14,5 @@ type Book struct {
 type FilterBook struct {
 	Limit, Offset int
 	Title         string
+	Order         string
 }
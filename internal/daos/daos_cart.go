@@ -0,0 +1,26 @@
+package daos
+
+import (
+	"tugas_akhir_example/internal/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+type Cart struct {
+	gorm.Model
+	IdUser uint `gorm:"uniqueIndex"`
+
+	CartItems []*CartItem `gorm:"foreignKey:IdCart"`
+}
+
+type CartItem struct {
+	gorm.Model
+	IdCart        uint `gorm:"index"`
+	IdProduk      uint
+	IdVariant     *uint
+	Kuantitas     int
+	HargaKonsumen money.Rupiah
+
+	Produk  *Produk        `gorm:"foreignKey:IdProduk"`
+	Variant *ProdukVariant `gorm:"foreignKey:IdVariant"`
+}
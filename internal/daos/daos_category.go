@@ -6,3 +6,9 @@ type Category struct {
 	gorm.Model
 	NamaCategory string
 }
+
+type FilterCategory struct {
+	Limit, Offset int
+	NamaCategory  string
+	Order         string
+}
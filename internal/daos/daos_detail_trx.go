@@ -1,6 +1,10 @@
 package daos
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type DetailTrx struct {
 	gorm.Model
@@ -9,6 +13,17 @@ type DetailTrx struct {
 	IdToko      uint
 	Kuantitas   int
 	HargaTotal  int
+	Diskon      int
 
+	Trx       *Trx       `gorm:"foreignKey:IdTrx"`
 	LogProduk *LogProduk `gorm:"foreignKey:IdLogProduk"`
 }
+
+type FilterDetailTrx struct {
+	Limit, Offset  int
+	IdToko         uint
+	Status         string
+	KodeInvoice    string
+	TanggalMulai   time.Time
+	TanggalSelesai time.Time
+}
@@ -6,4 +6,24 @@ type FotoProduk struct {
 	gorm.Model
 	IdProduk uint
 	Url      string
+	Sizes    map[string]*FotoSize `gorm:"serializer:json;type:json"` // keyed by the name of the size, e.g. thumb, medium and large
+}
+
+// FotoSize is a photo resized and re-encoded to one of the standard sizes
+type FotoSize struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Urls returns the url of the photo along with the urls of each of its sizes
+func (f *FotoProduk) Urls() (res []string) {
+	res = append(res, f.Url)
+	for _, v := range f.Sizes {
+		if v.Url != f.Url {
+			res = append(res, v.Url)
+		}
+	}
+
+	return res
 }
@@ -0,0 +1,18 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyKey struct {
+	gorm.Model
+	Scope        string `gorm:"type:varchar(64);uniqueIndex"`
+	RequestHash  string `gorm:"type:varchar(64)"`
+	Completed    bool
+	StatusCode   int
+	ContentType  string    `gorm:"type:varchar(100)"`
+	ResponseBody string    `gorm:"type:mediumtext"`
+	ExpiredAt    time.Time `gorm:"index"`
+}
@@ -0,0 +1,9 @@
+package daos
+
+import "time"
+
+type InvoiceSequence struct {
+	Scope      string `gorm:"type:varchar(50);primaryKey"`
+	LastNumber int
+	UpdatedAt  time.Time
+}
@@ -1,19 +1,28 @@
 package daos
 
-import "gorm.io/gorm"
+import (
+	"tugas_akhir_example/internal/pkg/money"
+
+	"gorm.io/gorm"
+)
 
 type LogProduk struct {
 	gorm.Model
-	IdProduk      uint `gorm:"index"`
+	IdProduk      uint  `gorm:"index"`
+	IdVariant     *uint `gorm:"index"`
+	Sku           string
+	Opsi          map[string]string `gorm:"serializer:json;type:json"`
 	NamaProduk    string
 	Slug          string
-	HargaReseller string
-	HargaKonsumen string
+	HargaReseller money.Rupiah
+	HargaKonsumen money.Rupiah
+	Berat         int
 	Deskripsi     string `gorm:"type:text"`
 	IdToko        uint
 	IdCategory    uint
 
-	Produk   *Produk   `gorm:"foreignKey:IdProduk"`
-	Toko     *Toko     `gorm:"foreignKey:IdToko"`
-	Category *Category `gorm:"foreignKey:IdCategory"`
+	Produk   *Produk        `gorm:"foreignKey:IdProduk"`
+	Variant  *ProdukVariant `gorm:"foreignKey:IdVariant"`
+	Toko     *Toko          `gorm:"foreignKey:IdToko"`
+	Category *Category      `gorm:"foreignKey:IdCategory"`
 }
@@ -0,0 +1,36 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusFailed  = "failed"
+)
+
+type Payment struct {
+	gorm.Model
+	IdTrx     uint   `gorm:"index"`
+	Provider  string `gorm:"type:varchar(30)"`
+	Reference string `gorm:"type:varchar(100);uniqueIndex"`
+	Amount    int
+	Status    string `gorm:"type:varchar(20);default:pending"`
+	Instruksi string `gorm:"type:text"`
+	ExpiredAt *time.Time
+	PaidAt    *time.Time
+
+	Trx *Trx `gorm:"foreignKey:IdTrx"`
+}
+
+type PaymentEvent struct {
+	gorm.Model
+	Provider  string `gorm:"type:varchar(30);uniqueIndex:idx_payment_event_provider_event"`
+	EventId   string `gorm:"type:varchar(100);uniqueIndex:idx_payment_event_provider_event"`
+	Reference string `gorm:"type:varchar(100);index"`
+	Status    string `gorm:"type:varchar(20)"`
+	Payload   string `gorm:"type:text"`
+}
@@ -1,21 +1,30 @@
 package daos
 
-import "gorm.io/gorm"
+import (
+	"time"
+	"tugas_akhir_example/internal/pkg/money"
+
+	"gorm.io/gorm"
+)
 
 type Produk struct {
 	gorm.Model
-	NamaProduk    string
-	Slug          string
-	HargaReseller string
-	HargaKonsumen string
+	NamaProduk    string `gorm:"index:idx_produk_nama_fulltext,class:FULLTEXT;index:idx_produk_fulltext,class:FULLTEXT,priority:1"`
+	Slug          string `gorm:"size:191;uniqueIndex:idx_produk_slug"`
+	HargaReseller money.Rupiah
+	HargaKonsumen money.Rupiah
 	Stok          int
-	Deskripsi     string `gorm:"type:text"`
+	Berat         int    `gorm:"default:1000"` // gram
+	Deskripsi     string `gorm:"type:text;index:idx_produk_fulltext,class:FULLTEXT,priority:2"`
 	IdToko        uint
 	IdCategory    uint
 
-	FotoProduks []*FotoProduk `gorm:"foreignKey:IdProduk"`
-	Toko        *Toko         `gorm:"foreignKey:IdToko"`
-	Category    *Category     `gorm:"foreignKey:IdCategory"`
+	FotoProduks []*FotoProduk    `gorm:"foreignKey:IdProduk"`
+	Variants    []*ProdukVariant `gorm:"foreignKey:IdProduk"`
+	Toko        *Toko            `gorm:"foreignKey:IdToko"`
+	Category    *Category        `gorm:"foreignKey:IdCategory"`
+
+	RatingCounts []*RatingCount `gorm:"foreignKey:IdProduk"`
 }
 
 type FilterProduk struct {
@@ -23,6 +32,11 @@ type FilterProduk struct {
 	NamaProduk    string
 	CategoryId    uint
 	TokoId        uint
-	MaxHarga      int
-	MinHarga      int
+	MaxHarga      money.Rupiah
+	MinHarga      money.Rupiah
+	Order         string
+
+	// the page continues after the produk created at CursorCreatedAt having the CursorId, from the first produk when nil
+	CursorCreatedAt *time.Time
+	CursorId        uint
 }
@@ -0,0 +1,44 @@
+package daos
+
+import (
+	"tugas_akhir_example/internal/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+type ProdukVariant struct {
+	gorm.Model
+	IdProduk      uint              `gorm:"index"`
+	Sku           string            `gorm:"type:varchar(64);uniqueIndex"`
+	Opsi          map[string]string `gorm:"serializer:json;type:json"` // e.g. {"ukuran":"L","warna":"Merah"}
+	HargaReseller *money.Rupiah     // nil follows the harga of the produk
+	HargaKonsumen *money.Rupiah     // nil follows the harga of the produk
+	Stok          int
+
+	Produk             *Produk              `gorm:"foreignKey:IdProduk"`
+	FotoProdukVariants []*FotoProdukVariant `gorm:"foreignKey:IdProdukVariant"`
+}
+
+type FotoProdukVariant struct {
+	gorm.Model
+	IdProdukVariant uint `gorm:"index"`
+	Url             string
+}
+
+// HargaResellerOf returns the harga reseller of the variant, falling back to the one of the produk
+func (v *ProdukVariant) HargaResellerOf(produk *Produk) money.Rupiah {
+	if v.HargaReseller != nil {
+		return *v.HargaReseller
+	}
+
+	return produk.HargaReseller
+}
+
+// HargaKonsumenOf returns the harga konsumen of the variant, falling back to the one of the produk
+func (v *ProdukVariant) HargaKonsumenOf(produk *Produk) money.Rupiah {
+	if v.HargaKonsumen != nil {
+		return *v.HargaKonsumen
+	}
+
+	return produk.HargaKonsumen
+}
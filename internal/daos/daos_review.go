@@ -0,0 +1,54 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RatingMin = 1
+	RatingMax = 5
+)
+
+type Review struct {
+	gorm.Model
+	IdDetailTrx uint   `gorm:"uniqueIndex"`
+	IdUser      uint   `gorm:"index"`
+	IdProduk    uint   `gorm:"index"`
+	IdToko      uint   `gorm:"index"`
+	Rating      int    `gorm:"type:tinyint"`
+	Ulasan      string `gorm:"type:text"`
+	Balasan     string `gorm:"type:text"`
+	DibalasAt   *time.Time
+
+	FotoReviews []*FotoReview `gorm:"foreignKey:IdReview"`
+	User        *User         `gorm:"foreignKey:IdUser"`
+	DetailTrx   *DetailTrx    `gorm:"foreignKey:IdDetailTrx"`
+}
+
+type FotoReview struct {
+	gorm.Model
+	IdReview uint `gorm:"index"`
+	Url      string
+}
+
+// RatingCount is the number of reviews giving the rating to the produk or the toko, read from the review table
+type RatingCount struct {
+	IdProduk uint
+	IdToko   uint
+	Rating   int
+	Jumlah   int64
+}
+
+// TableName returns the table the rating counts are read from
+func (RatingCount) TableName() string {
+	return "reviews"
+}
+
+type FilterReview struct {
+	Limit, Offset int
+	IdProduk      uint
+	Rating        int
+	Order         string
+}
@@ -0,0 +1,17 @@
+package daos
+
+import "time"
+
+const (
+	SlugTipeProduk = "produk"
+	SlugTipeToko   = "toko"
+)
+
+// SlugHistory is a previous slug of a produk or toko, kept so the old slug keeps resolving after a rename
+type SlugHistory struct {
+	ID        uint   `gorm:"primarykey"`
+	Tipe      string `gorm:"size:20;uniqueIndex:idx_slug_history_tipe_slug,priority:1"`
+	Slug      string `gorm:"size:191;uniqueIndex:idx_slug_history_tipe_slug,priority:2"`
+	IdRef     uint   `gorm:"index"`
+	CreatedAt time.Time
+}
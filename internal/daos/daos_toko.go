@@ -6,10 +6,15 @@ type Toko struct {
 	gorm.Model
 	IdUser   uint
 	NamaToko string
+	Slug     string `gorm:"size:191;uniqueIndex:idx_toko_slug"`
 	UrlFoto  string
+
+	RatingCounts   []*RatingCount   `gorm:"foreignKey:IdToko"`
+	FollowerCounts []*FollowerCount `gorm:"foreignKey:IdToko"`
 }
 
 type FilterToko struct {
 	Limit, Offset int
 	NamaToko      string
+	Order         string
 }
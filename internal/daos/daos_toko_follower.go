@@ -0,0 +1,21 @@
+package daos
+
+import "time"
+
+type TokoFollower struct {
+	ID        uint `gorm:"primarykey"`
+	IdUser    uint `gorm:"uniqueIndex:idx_toko_follower_user_toko,priority:1"`
+	IdToko    uint `gorm:"uniqueIndex:idx_toko_follower_user_toko,priority:2;index"`
+	CreatedAt time.Time
+}
+
+// FollowerCount is the number of users following the toko, read from the tokofollower table
+type FollowerCount struct {
+	IdToko uint
+	Jumlah int64
+}
+
+// TableName returns the table the follower counts are read from
+func (FollowerCount) TableName() string {
+	return "toko_followers"
+}
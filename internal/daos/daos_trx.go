@@ -1,21 +1,51 @@
 package daos
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TrxStatusPending   = "pending"
+	TrxStatusPaid      = "paid"
+	TrxStatusPacked    = "packed"
+	TrxStatusShipped   = "shipped"
+	TrxStatusDelivered = "delivered"
+	TrxStatusCancelled = "cancelled"
+	TrxStatusRefunded  = "refunded"
+)
 
 type Trx struct {
 	gorm.Model
 	IdUser           uint
 	AlamatPengiriman uint
 	HargaTotal       int
-	KodeInvoice      string
+	OngkirTotal      int
+	Diskon           int
+	IdVoucher        *uint
+	KodeInvoice      string `gorm:"type:varchar(50);uniqueIndex"`
 	MethodBayar      string
+	Status           string `gorm:"type:varchar(20);default:pending;index"`
 
-	User       *User        `gorm:"foreignKey:IdUser"`
-	DetailTrxs []*DetailTrx `gorm:"foreignKey:IdTrx"`
-	Alamat     *Alamat      `gorm:"foreignKey:AlamatPengiriman"`
+	User            *User               `gorm:"foreignKey:IdUser"`
+	DetailTrxs      []*DetailTrx        `gorm:"foreignKey:IdTrx"`
+	Alamat          *Alamat             `gorm:"foreignKey:AlamatPengiriman"`
+	StatusHistories []*TrxStatusHistory `gorm:"foreignKey:IdTrx"`
+	Payment         *Payment            `gorm:"foreignKey:IdTrx"`
+	Pengirimans     []*TrxPengiriman    `gorm:"foreignKey:IdTrx"`
+	Voucher         *Voucher            `gorm:"foreignKey:IdVoucher"`
+	VoucherUsage    *VoucherUsage       `gorm:"foreignKey:IdTrx"`
 }
 
 type FilterTrx struct {
 	Limit, Offset int
+	IdUser        uint
 	KodeInvoice   string
+	Status        string
+	Order         string
+
+	// the page continues after the trx created at CursorCreatedAt having the CursorId, from the first trx when nil
+	CursorCreatedAt *time.Time
+	CursorId        uint
 }
@@ -0,0 +1,26 @@
+package daos
+
+import "gorm.io/gorm"
+
+// TrxPengiriman is the shipment of the lines of one toko of a trx. Its status follows the trx until the seller of the toko
+// packs and ships it, the trx moving along once none of its pengirimans is left behind
+type TrxPengiriman struct {
+	gorm.Model
+	IdTrx   uint `gorm:"index"`
+	IdToko  uint
+	Kurir   string `gorm:"type:varchar(30)"`
+	Layanan string `gorm:"type:varchar(30)"`
+	Asal    string `gorm:"type:varchar(10)"`
+	Tujuan  string `gorm:"type:varchar(10)"`
+	Berat   int
+	Ongkir  int
+	Etd     string `gorm:"type:varchar(30)"`
+	Status  string `gorm:"type:varchar(20);default:pending"`
+
+	Toko *Toko `gorm:"foreignKey:IdToko"`
+}
+
+// TableName overrides the table name used by the trx pengiriman data
+func (TrxPengiriman) TableName() string {
+	return "trx_pengiriman"
+}
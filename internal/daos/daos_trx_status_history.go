@@ -0,0 +1,27 @@
+package daos
+
+import "gorm.io/gorm"
+
+const (
+	TrxActorBuyer  = "buyer"
+	TrxActorSeller = "seller"
+	TrxActorAdmin  = "admin"
+	TrxActorSystem = "system"
+)
+
+// TrxStatusHistory records a move of the status of a trx, or of the pengiriman of the toko when IdToko is set
+type TrxStatusHistory struct {
+	gorm.Model
+	IdTrx      uint `gorm:"index"`
+	IdToko     *uint
+	IdUser     uint
+	Peran      string `gorm:"type:varchar(20)"`
+	StatusLama string `gorm:"type:varchar(20)"`
+	StatusBaru string `gorm:"type:varchar(20)"`
+	Alasan     string `gorm:"type:text"`
+}
+
+// TableName overrides the table name used by the trx status history data
+func (TrxStatusHistory) TableName() string {
+	return "trx_status_history"
+}
@@ -18,8 +18,11 @@ type User struct {
 	Email        string `gorm:"unique"`
 	IdProvinsi   string
 	IdKota       string
-	IsAdmin      bool
 
-	Toko    *Toko     `gorm:"foreignKey:IdUser"`
-	Alamats []*Alamat `gorm:"foreignKey:IdUser"`
+	NotelpVerifiedAt *time.Time
+	EmailVerifiedAt  *time.Time
+
+	Toko    *Toko       `gorm:"foreignKey:IdUser"`
+	Alamats []*Alamat   `gorm:"foreignKey:IdUser"`
+	Roles   []*UserRole `gorm:"foreignKey:IdUser"`
 }
@@ -0,0 +1,26 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OtpPurposeVerifyNotelp  = "verify_notelp"
+	OtpPurposeVerifyEmail   = "verify_email"
+	OtpPurposeResetPassword = "reset_password"
+)
+
+// UserOtp is a one time password sent to the notelp or the email of the user, only the hmac of its code is stored.
+// Issuing an otp consumes the previous one of the same purpose, so only the latest can be used
+type UserOtp struct {
+	gorm.Model
+	IdUser     uint   `gorm:"index:idx_user_otp_user_purpose,priority:1"`
+	Purpose    string `gorm:"type:varchar(20);index:idx_user_otp_user_purpose,priority:2"`
+	Target     string // the notelp or the email the code was sent to
+	CodeHash   string `gorm:"type:varchar(64)"`
+	Attempts   int
+	ExpiredAt  time.Time
+	ConsumedAt *time.Time
+}
@@ -0,0 +1,11 @@
+package daos
+
+import "time"
+
+// UserRole is a role granted to the user, the permissions of each role are listed by the rbac package
+type UserRole struct {
+	ID        uint   `gorm:"primarykey"`
+	IdUser    uint   `gorm:"uniqueIndex:idx_user_role_user_role,priority:1"`
+	Role      string `gorm:"type:varchar(20);uniqueIndex:idx_user_role_user_role,priority:2"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession is a refresh token issued to the user, only its sha256 hash is stored. The refresh tokens rotated from the same login
+// share the family, which the access tokens refer to and which is revoked as a whole on logout or when a rotated refresh token is used again
+type UserSession struct {
+	gorm.Model
+	IdUser           uint      `gorm:"index"`
+	Family           string    `gorm:"type:varchar(64);index"`
+	RefreshTokenHash string    `gorm:"type:varchar(64);uniqueIndex"`
+	ExpiredAt        time.Time `gorm:"index"`
+	RotatedAt        *time.Time
+	RevokedAt        *time.Time
+}
@@ -0,0 +1,48 @@
+package daos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	VoucherTipePercentage = "percentage"
+	VoucherTipeFixed      = "fixed"
+)
+
+type Voucher struct {
+	gorm.Model
+	Kode          string `gorm:"type:varchar(50);uniqueIndex"`
+	Deskripsi     string `gorm:"type:text"`
+	Tipe          string `gorm:"type:varchar(20)"`
+	Nilai         int
+	MinBelanja    int
+	MaxDiskon     int // 0 means no cap
+	KuotaGlobal   int // 0 means unlimited
+	KuotaPerUser  int // 0 means unlimited
+	Terpakai      int
+	BerlakuMulai  time.Time
+	BerlakuSampai time.Time
+	Aktif         *bool `gorm:"default:true"`
+	IdToko        *uint `gorm:"index"` // nil for the platform vouchers managed by the admins
+	IdCategory    *uint
+	IdPembuat     uint
+
+	Toko     *Toko     `gorm:"foreignKey:IdToko"`
+	Category *Category `gorm:"foreignKey:IdCategory"`
+}
+
+type VoucherUsage struct {
+	gorm.Model
+	IdVoucher uint `gorm:"index"`
+	IdUser    uint `gorm:"index"`
+	IdTrx     uint `gorm:"index"`
+	Diskon    int
+}
+
+type FilterVoucher struct {
+	Limit, Offset int
+	IdToko        *uint
+	Kode          string
+}
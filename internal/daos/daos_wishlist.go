@@ -0,0 +1,18 @@
+package daos
+
+import "time"
+
+type Wishlist struct {
+	ID        uint `gorm:"primarykey"`
+	IdUser    uint `gorm:"uniqueIndex:idx_wishlist_user_produk,priority:1"`
+	IdProduk  uint `gorm:"uniqueIndex:idx_wishlist_user_produk,priority:2;index"`
+	CreatedAt time.Time
+
+	Produk *Produk `gorm:"foreignKey:IdProduk"`
+}
+
+type FilterWishlist struct {
+	Limit, Offset int
+	IdUser        uint
+	Order         string
+}
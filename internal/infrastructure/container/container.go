@@ -5,8 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/infrastructure/mysql"
+	"tugas_akhir_example/internal/infrastructure/notifier"
+	"tugas_akhir_example/internal/infrastructure/payment"
+	"tugas_akhir_example/internal/infrastructure/ratelimit"
+	"tugas_akhir_example/internal/infrastructure/search"
+	"tugas_akhir_example/internal/infrastructure/shipping"
+	"tugas_akhir_example/internal/infrastructure/storage"
 
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
@@ -18,8 +25,14 @@ const currentfilepath = "internal/infrastructure/container/container.go"
 
 type (
 	Container struct {
-		Mysqldb *gorm.DB
-		Apps    *Apps
+		Mysqldb  *gorm.DB
+		Apps     *Apps
+		Payments *payment.Registry
+		Shipping shipping.ShippingRateProvider
+		Searcher search.ProductSearcher
+		Storage  storage.BlobStorage
+		Notifier notifier.Notifier
+		Limiter  *ratelimit.Limiter
 	}
 
 	Apps struct {
@@ -29,6 +42,15 @@ type (
 		Address   string `mapstructure:"address"`
 		HttpPort  int    `mapstructure:"httpport"`
 		SecretJwt string `mapstructure:"secretJwt"`
+
+		IdempotencyTtl  time.Duration `mapstructure:"idempotencyTtl"`
+		AccessTokenTtl  time.Duration `mapstructure:"accessTokenTtl"`
+		RefreshTokenTtl time.Duration `mapstructure:"refreshTokenTtl"`
+
+		OtpTtl                  time.Duration `mapstructure:"otpTtl"`
+		OtpResendInterval       time.Duration `mapstructure:"otpResendInterval"`
+		OtpMaxAttempts          int           `mapstructure:"otpMaxAttempts"`
+		RequireVerifiedCheckout bool          `mapstructure:"requireVerifiedCheckout"`
 	}
 )
 
@@ -83,10 +105,37 @@ func AppsInit(v *viper.Viper) (apps Apps) {
 func InitContainer() (cont *Container) {
 	apps := AppsInit(v)
 	mysqldb := mysql.DatabaseInit(v)
+	payments, err := payment.ProviderInit(v)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("failed init payment provider : %s", err.Error()))
+	}
+	shippingProvider := shipping.ProviderInit()
+	searcher, err := search.ProviderInit(v, mysqldb)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("failed init search provider : %s", err.Error()))
+	}
+	blobStorage, err := storage.ProviderInit(v)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("failed init storage provider : %s", err.Error()))
+	}
+	notifierProvider, err := notifier.ProviderInit(v)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("failed init notifier provider : %s", err.Error()))
+	}
+	limiter, err := ratelimit.ProviderInit(v)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("failed init rate limit provider : %s", err.Error()))
+	}
 
 	return &Container{
-		Apps:    &apps,
-		Mysqldb: mysqldb,
+		Apps:     &apps,
+		Mysqldb:  mysqldb,
+		Payments: payments,
+		Shipping: shippingProvider,
+		Searcher: searcher,
+		Storage:  blobStorage,
+		Notifier: notifierProvider,
+		Limiter:  limiter,
 	}
 
 }
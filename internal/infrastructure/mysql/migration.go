@@ -3,15 +3,32 @@ package mysql
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/infrastructure/mysql/seed"
+	"tugas_akhir_example/internal/pkg/money"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/pkg/slug"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RunMigration runs database migrations and seeds mock data to the database
 func RunMigration(mysqlDB *gorm.DB) {
+	if err := migrateHargaToRupiah(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed Harga Migrated : %s", err.Error()))
+	}
+
+	if err := migrateSlugs(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed Slug Migrated : %s", err.Error()))
+	}
+
+	if err := migrateTrxPengirimanStatus(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed Trx Pengiriman Status Migrated : %s", err.Error()))
+	}
+
 	err := mysqlDB.AutoMigrate(
 		&daos.Category{},
 		&daos.User{},
@@ -20,14 +37,51 @@ func RunMigration(mysqlDB *gorm.DB) {
 		&daos.Produk{},
 		&daos.LogProduk{},
 		&daos.FotoProduk{},
+		&daos.ProdukVariant{},
+		&daos.FotoProdukVariant{},
 		&daos.Trx{},
 		&daos.DetailTrx{},
+		&daos.InvoiceSequence{},
+		&daos.TrxPengiriman{},
+		&daos.Voucher{},
+		&daos.VoucherUsage{},
+		&daos.TrxStatusHistory{},
+		&daos.Payment{},
+		&daos.PaymentEvent{},
+		&daos.IdempotencyKey{},
+		&daos.Cart{},
+		&daos.CartItem{},
+		&daos.Review{},
+		&daos.FotoReview{},
+		&daos.Wishlist{},
+		&daos.TokoFollower{},
+		&daos.SlugHistory{},
+		&daos.UserSession{},
+		&daos.UserOtp{},
+		&daos.UserRole{},
 		&daos.Book{},
 	)
+	if err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed Database Migrated : %s", err.Error()))
+	}
+
+	if err := createKeysetIndexes(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed Keyset Index Migrated : %s", err.Error()))
+	}
+
+	// the column is dropped a run after the backfill, so the former release still finds it when the deploy is rolled back
+	if err := dropUserIsAdmin(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed User Is Admin Dropped : %s", err.Error()))
+	}
+
+	if err := migrateUserRoles(mysqlDB); err != nil {
+		helper.Logger(currentfilepath, helper.LoggerLevelPanic, fmt.Sprintf("Failed User Role Migrated : %s", err.Error()))
+	}
 
 	SeedData(mysqlDB,
 		seed.CategorySeed,
 		seed.UserSeed,
+		seed.UserRoleSeed,
 		seed.TokoSeed,
 		seed.AlamatSeed,
 		seed.ProdukSeed,
@@ -38,10 +92,6 @@ func RunMigration(mysqlDB *gorm.DB) {
 		seed.BookSeed,
 	)
 
-	if err != nil {
-		helper.Logger(currentfilepath, helper.LoggerLevelError, fmt.Sprintf("Failed Database Migrated : %s", err.Error()))
-	}
-
 	helper.Logger(currentfilepath, helper.LoggerLevelInfo, "Database Migrated")
 }
 
@@ -58,3 +108,219 @@ func SeedData(mysqlDB *gorm.DB, seeds ...interface{}) {
 		}
 	}
 }
+
+// keysetIndexes lists the indexes backing the listings continued by cursor, which are sorted by created_at and id
+var keysetIndexes = []struct {
+	model                interface{}
+	table, name, columns string
+}{
+	{&daos.Produk{}, "produks", "idx_produk_created_at_id", "created_at, id"},
+	{&daos.Trx{}, "trxes", "idx_trx_user_created_at_id", "id_user, created_at, id"},
+}
+
+// createKeysetIndexes creates the keyset indexes missing from the database
+func createKeysetIndexes(mysqlDB *gorm.DB) error {
+	for _, v := range keysetIndexes {
+		if mysqlDB.Migrator().HasIndex(v.model, v.name) {
+			continue
+		}
+
+		if err := mysqlDB.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", v.name, v.table, v.columns)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateUserRoles replaces the former is_admin column of the user table by roles, granting the default roles
+// to the users having none along with the admin role to the users flagged as admin. The column is left for dropUserIsAdmin
+func migrateUserRoles(mysqlDB *gorm.DB) error {
+	if !mysqlDB.Migrator().HasColumn(&daos.User{}, "is_admin") {
+		return nil
+	}
+
+	return mysqlDB.Transaction(func(tx *gorm.DB) error {
+		userIds := []uint{}
+		if err := tx.Model(&daos.User{}).Unscoped().Where("id NOT IN (?)", tx.Model(&daos.UserRole{}).Select("id_user")).Pluck("id", &userIds).Error; err != nil {
+			return err
+		}
+
+		adminIds := []uint{}
+		if err := tx.Model(&daos.User{}).Unscoped().Where("is_admin = ?", true).Pluck("id", &adminIds).Error; err != nil {
+			return err
+		}
+
+		roles := []*daos.UserRole{}
+		for _, id := range userIds {
+			for _, role := range rbac.DefaultRoles {
+				roles = append(roles, &daos.UserRole{IdUser: id, Role: role})
+			}
+		}
+		for _, id := range adminIds {
+			roles = append(roles, &daos.UserRole{IdUser: id, Role: rbac.RoleAdmin})
+		}
+
+		if len(roles) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(roles, 500).Error
+	})
+}
+
+// dropUserIsAdmin drops the former is_admin column of the user table once every user has roles, i.e. once a former run
+// of migrateUserRoles granted them. Running before migrateUserRoles, it never drops the column in the run backfilling it
+func dropUserIsAdmin(mysqlDB *gorm.DB) error {
+	if !mysqlDB.Migrator().HasColumn(&daos.User{}, "is_admin") {
+		return nil
+	}
+
+	var count int64
+	if err := mysqlDB.Model(&daos.User{}).Unscoped().Where("id NOT IN (?)", mysqlDB.Model(&daos.UserRole{}).Select("id_user")).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return mysqlDB.Migrator().DropColumn(&daos.User{}, "is_admin")
+}
+
+// migrateTrxPengirimanStatus adds the status column to the trx pengiriman table and gives the pengirimans still pending
+// the status of their trx, as the pengirimans were only moved along with their trx before having a status of their own.
+// A pengiriman is never left pending once its trx moved on, so the filling is safe to run again
+func migrateTrxPengirimanStatus(mysqlDB *gorm.DB) error {
+	if !mysqlDB.Migrator().HasTable(&daos.TrxPengiriman{}) {
+		return nil
+	}
+
+	if !mysqlDB.Migrator().HasColumn(&daos.TrxPengiriman{}, "Status") {
+		if err := mysqlDB.Migrator().AddColumn(&daos.TrxPengiriman{}, "Status"); err != nil {
+			return err
+		}
+	}
+
+	return mysqlDB.Exec("UPDATE trx_pengiriman JOIN trxes ON trxes.id = trx_pengiriman.id_trx SET trx_pengiriman.status = trxes.status "+
+		"WHERE trx_pengiriman.status = ? AND trxes.status <> ?", daos.TrxStatusPending, daos.TrxStatusPending).Error
+}
+
+// slugModels lists the tables having a unique slug index, along with the column the slug is derived from
+var slugModels = []struct {
+	model               interface{}
+	tipe, index, column string
+}{
+	{&daos.Produk{}, daos.SlugTipeProduk, "idx_produk_slug", "nama_produk"},
+	{&daos.Toko{}, daos.SlugTipeToko, "idx_toko_slug", "nama_toko"},
+}
+
+// migrateSlugs fills the slug of the produk and toko data missing one, having an invalid one or sharing it with other data,
+// so AutoMigrate can create the unique slug indexes. The slugs already valid and unique are kept as is
+func migrateSlugs(mysqlDB *gorm.DB) error {
+	for _, v := range slugModels {
+		if !mysqlDB.Migrator().HasTable(v.model) || mysqlDB.Migrator().HasIndex(v.model, v.index) {
+			continue
+		}
+
+		if !mysqlDB.Migrator().HasColumn(v.model, "Slug") {
+			if err := mysqlDB.Migrator().AddColumn(v.model, "Slug"); err != nil {
+				return err
+			}
+		}
+
+		err := mysqlDB.Transaction(func(tx *gorm.DB) error {
+			rows := []struct {
+				ID   uint
+				Nama string
+				Slug string
+			}{}
+			if err := tx.Model(v.model).Unscoped().Select(fmt.Sprintf("id, %s AS nama, slug", v.column)).Order("id").Find(&rows).Error; err != nil {
+				return err
+			}
+
+			taken := map[string]struct{}{}
+			for _, row := range rows {
+				res := row.Slug
+				if _, ok := taken[res]; ok || res == "" || res != slug.Make(res) {
+					base := slug.Make(row.Nama)
+					if base == "" {
+						base = v.tipe
+					}
+					res = slug.Unique(base, taken)
+				}
+				taken[res] = struct{}{}
+
+				if res == row.Slug {
+					continue
+				}
+
+				if err := tx.Model(v.model).Unscoped().Where("id = ?", row.ID).UpdateColumn("slug", res).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHargaToRupiah normalizes the harga columns of the produk and logproduk tables still stored as strings,
+// so AutoMigrate can alter them into integer columns without losing the amounts written as "Rp 75.000"
+func migrateHargaToRupiah(mysqlDB *gorm.DB) error {
+	for _, model := range []interface{}{&daos.Produk{}, &daos.LogProduk{}} {
+		if !mysqlDB.Migrator().HasTable(model) {
+			continue
+		}
+
+		columnTypes, err := mysqlDB.Migrator().ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+
+		for _, columnType := range columnTypes {
+			if columnType.Name() != "harga_reseller" && columnType.Name() != "harga_konsumen" {
+				continue
+			}
+
+			typeName := strings.ToLower(columnType.DatabaseTypeName())
+			if !strings.Contains(typeName, "char") && !strings.Contains(typeName, "text") {
+				continue
+			}
+
+			err := mysqlDB.Transaction(func(tx *gorm.DB) error {
+				rows := []struct {
+					ID    uint
+					Harga string
+				}{}
+				if err := tx.Model(model).Unscoped().Select(fmt.Sprintf("id, %s AS harga", columnType.Name())).Find(&rows).Error; err != nil {
+					return err
+				}
+
+				for _, row := range rows {
+					harga := money.Rupiah(0)
+					if strings.TrimSpace(row.Harga) != "" {
+						harga, err = money.Parse(row.Harga)
+						if err != nil {
+							return fmt.Errorf("id %d kolom %s: %w", row.ID, columnType.Name(), err)
+						}
+					}
+
+					if err := tx.Model(model).Unscoped().Where("id = ?", row.ID).UpdateColumn(columnType.Name(), fmt.Sprint(int64(harga))).Error; err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/pkg/testfixture"
+)
+
+func TestMigrateUserRoles(t *testing.T) {
+	db := testfixture.OpenDB(t, &daos.User{}, &daos.UserRole{})
+
+	// the user table as it was before the roles, flagging the admins with is_admin
+	if !db.Migrator().HasColumn(&daos.User{}, "is_admin") {
+		if err := db.Exec("ALTER TABLE users ADD is_admin boolean").Error; err != nil {
+			t.Fatalf("add is_admin: %s", err)
+		}
+	}
+
+	newUser := func(isAdmin bool) *daos.User {
+		user := &daos.User{Notelp: testfixture.Name("notelp"), Email: testfixture.Name("email")}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("create user: %s", err)
+		}
+		if err := db.Model(user).UpdateColumn("is_admin", isAdmin).Error; err != nil {
+			t.Fatalf("flag user: %s", err)
+		}
+		return user
+	}
+	admin, buyer := newUser(true), newUser(false)
+
+	// a user already granted roles keeps them as they are
+	withRoles := newUser(false)
+	if err := db.Create(&daos.UserRole{IdUser: withRoles.ID, Role: rbac.RoleSupport}).Error; err != nil {
+		t.Fatalf("create user role: %s", err)
+	}
+
+	rolesOf := func(user *daos.User) string {
+		roles := []string{}
+		if err := db.Model(&daos.UserRole{}).Where("id_user = ?", user.ID).Pluck("role", &roles).Error; err != nil {
+			t.Fatalf("read roles: %s", err)
+		}
+		sort.Strings(roles)
+		return fmt.Sprint(roles)
+	}
+	hasIsAdmin := func() bool {
+		return db.Migrator().HasColumn(&daos.User{}, "is_admin")
+	}
+
+	if err := dropUserIsAdmin(db); err != nil {
+		t.Fatalf("dropUserIsAdmin error = %v", err)
+	}
+	if !hasIsAdmin() {
+		t.Fatalf("is_admin dropped before the users were granted their roles")
+	}
+
+	// run twice, as on every start until the column is dropped
+	for i := 0; i < 2; i++ {
+		if err := migrateUserRoles(db); err != nil {
+			t.Fatalf("migrateUserRoles error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		user *daos.User
+		want string
+	}{
+		{"admin", admin, fmt.Sprint([]string{rbac.RoleAdmin, rbac.RoleBuyer, rbac.RoleSeller})},
+		{"buyer", buyer, fmt.Sprint([]string{rbac.RoleBuyer, rbac.RoleSeller})},
+		{"user with roles", withRoles, fmt.Sprint([]string{rbac.RoleSupport})},
+	}
+	for _, tt := range tests {
+		if got := rolesOf(tt.user); got != tt.want {
+			t.Errorf("%s roles = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if !hasIsAdmin() {
+		t.Fatalf("is_admin dropped by the run backfilling the roles")
+	}
+
+	// the next run finds every user granted its roles
+	if err := dropUserIsAdmin(db); err != nil {
+		t.Fatalf("dropUserIsAdmin error = %v", err)
+	}
+	if hasIsAdmin() {
+		t.Errorf("is_admin kept once every user was granted its roles")
+	}
+	if err := migrateUserRoles(db); err != nil {
+		t.Errorf("migrateUserRoles without is_admin error = %v", err)
+	}
+}
@@ -8,8 +8,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukA",
 		Slug:          "produk-a",
-		HargaReseller: "50000",
-		HargaKonsumen: "75000",
+		HargaReseller: 50000,
+		HargaKonsumen: 75000,
 		Stok:          10,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        1,
@@ -18,8 +18,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukB",
 		Slug:          "produk-b",
-		HargaReseller: "75000",
-		HargaKonsumen: "100000",
+		HargaReseller: 75000,
+		HargaKonsumen: 100000,
 		Stok:          25,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        2,
@@ -28,8 +28,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukC",
 		Slug:          "produk-c",
-		HargaReseller: "10000",
-		HargaKonsumen: "20000",
+		HargaReseller: 10000,
+		HargaKonsumen: 20000,
 		Stok:          5,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        3,
@@ -38,8 +38,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukD",
 		Slug:          "produk-d",
-		HargaReseller: "5000",
-		HargaKonsumen: "6000",
+		HargaReseller: 5000,
+		HargaKonsumen: 6000,
 		Stok:          1,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        4,
@@ -48,8 +48,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukE",
 		Slug:          "produk-e",
-		HargaReseller: "15000",
-		HargaKonsumen: "17500",
+		HargaReseller: 15000,
+		HargaKonsumen: 17500,
 		Stok:          1,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        5,
@@ -58,8 +58,8 @@ var ProdukSeed = []daos.Produk{
 	{
 		NamaProduk:    "ProdukF",
 		Slug:          "produk-f",
-		HargaReseller: "25000",
-		HargaKonsumen: "30000",
+		HargaReseller: 25000,
+		HargaKonsumen: 30000,
 		Stok:          10,
 		Deskripsi:     "Suatu deskripsi yang menjelaskan produk",
 		IdToko:        2,
@@ -8,26 +8,31 @@ var TokoSeed = []daos.Toko{
 	{
 		IdUser:   1,
 		NamaToko: "tokoA",
+		Slug:     "tokoa",
 		UrlFoto:  "/static/images/toko/1.png",
 	},
 	{
 		IdUser:   2,
 		NamaToko: "contohtoko",
+		Slug:     "contohtoko",
 		UrlFoto:  "/static/images/toko/2.png",
 	},
 	{
 		IdUser:   3,
 		NamaToko: "contohtoko",
+		Slug:     "contohtoko-2",
 		UrlFoto:  "/static/images/toko/3.png",
 	},
 	{
 		IdUser:   4,
 		NamaToko: "contohtoko",
+		Slug:     "contohtoko-3",
 		UrlFoto:  "/static/images/toko/4.png",
 	},
 	{
 		IdUser:   5,
 		NamaToko: "contohtoko",
+		Slug:     "contohtoko-4",
 		UrlFoto:  "/static/images/toko/5.png",
 	},
 }
@@ -10,13 +10,13 @@ var TrxSeed = []daos.Trx{
 		AlamatPengiriman: 1,
 		HargaTotal:       75000,
 		KodeInvoice:      "Kode-A",
-		MethodBayar:      "bca",
+		MethodBayar:      "bank_transfer",
 	},
 	{
 		IdUser:           2,
 		AlamatPengiriman: 2,
 		HargaTotal:       5000,
 		KodeInvoice:      "Kode-B",
-		MethodBayar:      "bca",
+		MethodBayar:      "bank_transfer",
 	},
 }
@@ -18,7 +18,6 @@ var UserSeed = []daos.User{
 		Email:        "reza@example.com",
 		IdProvinsi:   "11",
 		IdKota:       "1101",
-		IsAdmin:      true,
 	},
 	{
 		Nama:         "UserB",
@@ -31,7 +30,6 @@ var UserSeed = []daos.User{
 		Email:        "testa@example.com",
 		IdProvinsi:   "11",
 		IdKota:       "1101",
-		IsAdmin:      false,
 	},
 	{
 		Nama:         "UserC",
@@ -44,7 +42,6 @@ var UserSeed = []daos.User{
 		Email:        "test2a@example.com",
 		IdProvinsi:   "11",
 		IdKota:       "1101",
-		IsAdmin:      false,
 	},
 	{
 		Nama:         "UserD",
@@ -57,7 +54,6 @@ var UserSeed = []daos.User{
 		Email:        "testca@example.com",
 		IdProvinsi:   "11",
 		IdKota:       "1101",
-		IsAdmin:      false,
 	},
 	{
 		Nama:         "UserE",
@@ -70,6 +66,5 @@ var UserSeed = []daos.User{
 		Email:        "testea@example.com",
 		IdProvinsi:   "11",
 		IdKota:       "1101",
-		IsAdmin:      false,
 	},
 }
@@ -0,0 +1,20 @@
+package seed
+
+import (
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/rbac"
+)
+
+var UserRoleSeed = []daos.UserRole{
+	{IdUser: 1, Role: rbac.RoleAdmin},
+	{IdUser: 1, Role: rbac.RoleBuyer},
+	{IdUser: 1, Role: rbac.RoleSeller},
+	{IdUser: 2, Role: rbac.RoleBuyer},
+	{IdUser: 2, Role: rbac.RoleSeller},
+	{IdUser: 3, Role: rbac.RoleBuyer},
+	{IdUser: 3, Role: rbac.RoleSeller},
+	{IdUser: 4, Role: rbac.RoleBuyer},
+	{IdUser: 4, Role: rbac.RoleSeller},
+	{IdUser: 5, Role: rbac.RoleBuyer},
+	{IdUser: 5, Role: rbac.RoleSeller},
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+const (
+	ProviderLog  = "log"
+	ProviderHTTP = "http"
+	ProviderSMTP = "smtp"
+)
+
+type NotifierConf struct {
+	SMSProvider string `mapstructure:"notifier_smsProvider"`
+	SMSUrl      string `mapstructure:"notifier_smsUrl"`
+	SMSApiKey   string `mapstructure:"notifier_smsApiKey"`
+	SMSSender   string `mapstructure:"notifier_smsSender"`
+
+	EmailProvider string `mapstructure:"notifier_emailProvider"`
+	SMTPHost      string `mapstructure:"notifier_smtpHost"`
+	SMTPPort      int    `mapstructure:"notifier_smtpPort"`
+	SMTPUsername  string `mapstructure:"notifier_smtpUsername"`
+	SMTPPassword  string `mapstructure:"notifier_smtpPassword"`
+	EmailFrom     string `mapstructure:"notifier_emailFrom"`
+}
+
+// Message is a text sent to a notelp by sms or to an email address
+type Message struct {
+	Channel string
+	To      string
+	Subject string // only used by the email channel
+	Body    string
+}
+
+// Notifier sends the messages to the users
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) (err error)
+}
+
+// ProviderInit initializes the notifier sending the messages of each channel through the provider chosen by the configuration,
+// the log provider being the default so no message leaves the app until a provider is configured
+func ProviderInit(v *viper.Viper) (Notifier, error) {
+	conf := NotifierConf{}
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	var sms Notifier
+	switch conf.SMSProvider {
+	case "", ProviderLog:
+		sms = NewLogNotifier()
+	case ProviderHTTP:
+		var err error
+		sms, err = NewSMSNotifier(&conf)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("sms provider %s is not supported", conf.SMSProvider)
+	}
+
+	var email Notifier
+	switch conf.EmailProvider {
+	case "", ProviderLog:
+		email = NewLogNotifier()
+	case ProviderSMTP:
+		var err error
+		email, err = NewEmailNotifier(&conf)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("email provider %s is not supported", conf.EmailProvider)
+	}
+
+	return NewChannelNotifier(map[string]Notifier{
+		ChannelSMS:   sms,
+		ChannelEmail: email,
+	}), nil
+}
+
+// ChannelNotifier sends each message through the notifier of its channel
+type ChannelNotifier struct {
+	notifiers map[string]Notifier
+}
+
+// NewChannelNotifier returns the notifier dispatching the messages to the notifiers keyed by their channels
+func NewChannelNotifier(notifiers map[string]Notifier) *ChannelNotifier {
+	return &ChannelNotifier{
+		notifiers: notifiers,
+	}
+}
+
+// Notify sends the message through the notifier of its channel
+func (n *ChannelNotifier) Notify(ctx context.Context, msg *Message) (err error) {
+	notifier, ok := n.notifiers[msg.Channel]
+	if !ok {
+		return fmt.Errorf("channel %s tidak didukung", msg.Channel)
+	}
+
+	if msg.To == "" || strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("penerima %q tidak valid", msg.To)
+	}
+
+	return notifier.Notify(ctx, msg)
+}
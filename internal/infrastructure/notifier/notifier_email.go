@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailNotifier sends the messages as plain text emails through an smtp server
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewEmailNotifier returns the notifier sending through the smtp server of the configuration,
+// authenticating only when a username is configured
+func NewEmailNotifier(conf *NotifierConf) (*EmailNotifier, error) {
+	if conf.SMTPHost == "" || conf.EmailFrom == "" {
+		return nil, errors.New("notifier_smtpHost and notifier_emailFrom are required")
+	}
+
+	port := conf.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	n := &EmailNotifier{
+		addr: net.JoinHostPort(conf.SMTPHost, strconv.Itoa(port)),
+		from: conf.EmailFrom,
+	}
+	if conf.SMTPUsername != "" {
+		n.auth = smtp.PlainAuth("", conf.SMTPUsername, conf.SMTPPassword, conf.SMTPHost)
+	}
+
+	return n, nil
+}
+
+// Notify sends the message as an email
+func (n *EmailNotifier) Notify(ctx context.Context, msg *Message) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body := strings.Builder{}
+	fmt.Fprintf(&body, "From: %s\r\n", n.from)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{msg.To}, []byte(body.String()))
+}
@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"tugas_akhir_example/internal/helper"
+)
+
+const currentfilepath = "internal/infrastructure/notifier/notifier_log.go"
+
+// LogNotifier writes the messages to the log instead of sending them, for the local development
+type LogNotifier struct{}
+
+// NewLogNotifier returns the notifier logging the messages
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the message
+func (n *LogNotifier) Notify(ctx context.Context, msg *Message) (err error) {
+	helper.Logger(currentfilepath, helper.LoggerLevelInfo, fmt.Sprintf("%s to %s : %s", msg.Channel, msg.To, msg.Body))
+	return nil
+}
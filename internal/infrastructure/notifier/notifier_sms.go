@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SMSNotifier sends the messages through an sms gateway accepting a json post of the sender, the notelp and the text,
+// authenticated by a bearer api key
+type SMSNotifier struct {
+	client *http.Client
+	url    string
+	apiKey string
+	sender string
+}
+
+type smsReq struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// NewSMSNotifier returns the notifier posting to the sms gateway of the configuration
+func NewSMSNotifier(conf *NotifierConf) (*SMSNotifier, error) {
+	if conf.SMSUrl == "" || conf.SMSApiKey == "" {
+		return nil, errors.New("notifier_smsUrl and notifier_smsApiKey are required")
+	}
+
+	return &SMSNotifier{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    conf.SMSUrl,
+		apiKey: conf.SMSApiKey,
+		sender: conf.SMSSender,
+	}, nil
+}
+
+// Notify posts the message to the sms gateway
+func (n *SMSNotifier) Notify(ctx context.Context, msg *Message) (err error) {
+	body, err := json.Marshal(&smsReq{
+		From:    n.sender,
+		To:      msg.To,
+		Message: msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.apiKey)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sms gateway: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"tugas_akhir_example/internal/helper"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	MethodBankTransfer = "bank_transfer"
+	MethodEwallet      = "ewallet"
+	MethodCOD          = "cod"
+	MethodMock         = "mock"
+)
+
+const (
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusFailed  = "failed"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type PaymentConf struct {
+	BankTransferWebhookSecret string `mapstructure:"payment_bankTransferWebhookSecret"`
+	EwalletWebhookSecret      string `mapstructure:"payment_ewalletWebhookSecret"`
+	CODWebhookSecret          string `mapstructure:"payment_codWebhookSecret"`
+	MockWebhookSecret         string `mapstructure:"payment_mockWebhookSecret"`
+	VaPrefix                  string `mapstructure:"payment_vaPrefix"`
+	EwalletCheckoutUrl        string `mapstructure:"payment_ewalletCheckoutUrl"`
+	MockEnabled               bool   `mapstructure:"payment_mockEnabled"`
+}
+
+type IntentReq struct {
+	Amount       int
+	NamaPenerima string
+	Notelp       string
+}
+
+type Intent struct {
+	Provider  string
+	Reference string
+	Amount    int
+	Status    string
+	Instruksi string
+	ExpiredAt time.Time
+}
+
+type WebhookEvent struct {
+	EventId   string
+	Reference string
+	Status    string
+}
+
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(ctx context.Context, req *IntentReq) (res *Intent, err error)
+	ParseWebhook(signature string, body []byte) (res *WebhookEvent, err error)
+}
+
+type Registry struct {
+	providers map[string]PaymentProvider
+}
+
+const currentfilepath = "internal/infrastructure/payment/payment.go"
+
+// ProviderInit initializes the registry of every supported payment provider, each verifying its webhooks with a secret of its own.
+// It fails when the secret of a provider offered is missing, as its webhooks could then be signed by anyone
+func ProviderInit(v *viper.Viper) (*Registry, error) {
+	var paymentConf PaymentConf
+	err := v.Unmarshal(&paymentConf)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[string]string{
+		MethodBankTransfer: paymentConf.BankTransferWebhookSecret,
+		MethodEwallet:      paymentConf.EwalletWebhookSecret,
+		MethodCOD:          paymentConf.CODWebhookSecret,
+	}
+
+	// the mock provider settles the payments without any money moving, so it is only offered when explicitly enabled
+	if paymentConf.MockEnabled {
+		helper.Logger(currentfilepath, helper.LoggerLevelWarn, "mock payment provider is enabled, never enable it in production")
+		secrets[MethodMock] = paymentConf.MockWebhookSecret
+	}
+
+	for _, method := range []string{MethodBankTransfer, MethodEwallet, MethodCOD, MethodMock} {
+		if secret, ok := secrets[method]; ok && secret == "" {
+			return nil, fmt.Errorf("webhook secret of the %s payment provider is not set", method)
+		}
+	}
+
+	providers := []PaymentProvider{
+		NewBankTransferProvider(secrets[MethodBankTransfer], paymentConf.VaPrefix),
+		NewEwalletProvider(secrets[MethodEwallet], paymentConf.EwalletCheckoutUrl),
+		NewCODProvider(secrets[MethodCOD]),
+	}
+	if paymentConf.MockEnabled {
+		providers = append(providers, NewMockProvider(secrets[MethodMock]))
+	}
+
+	return NewRegistry(providers...), nil
+}
+
+// NewRegistry returns a registry holding the given providers keyed by their names
+func NewRegistry(providers ...PaymentProvider) *Registry {
+	registry := &Registry{
+		providers: map[string]PaymentProvider{},
+	}
+	for _, v := range providers {
+		registry.providers[v.Name()] = v
+	}
+
+	return registry
+}
+
+// Get returns the provider having the name
+func (r *Registry) Get(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("method bayar %s tidak didukung", name)
+	}
+
+	return provider, nil
+}
+
+// Sign returns the hex encoded hmac-sha256 signature of the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks whether the signature matches the body
+func verifySignature(secret, signature string, body []byte) error {
+	if !hmac.Equal([]byte(Sign(secret, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// newReference returns a random reference having the prefix
+func newReference(prefix string) (string, error) {
+	buff := make([]byte, 8)
+	if _, err := rand.Read(buff); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s_%s", prefix, hex.EncodeToString(buff)), nil
+}
@@ -0,0 +1,102 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// newTestPaymentConf returns the configuration holding a distinct webhook secret for each provider
+func newTestPaymentConf() *viper.Viper {
+	v := viper.New()
+	v.Set("payment_bankTransferWebhookSecret", "bank transfer secret")
+	v.Set("payment_ewalletWebhookSecret", "ewallet secret")
+	v.Set("payment_codWebhookSecret", "cod secret")
+	v.Set("payment_mockWebhookSecret", "mock secret")
+	return v
+}
+
+func TestProviderInitMockProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockEnabled interface{}
+		want        bool
+	}{
+		{"unset", nil, false},
+		{"disabled", false, false},
+		{"enabled", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestPaymentConf()
+			if tt.mockEnabled != nil {
+				v.Set("payment_mockEnabled", tt.mockEnabled)
+			}
+
+			registry, err := ProviderInit(v)
+			if err != nil {
+				t.Fatalf("ProviderInit error = %v", err)
+			}
+			if _, err := registry.Get(MethodMock); (err == nil) != tt.want {
+				t.Errorf("Get(%q) error = %v, want the provider offered = %v", MethodMock, err, tt.want)
+			}
+			if _, err := registry.Get(MethodBankTransfer); err != nil {
+				t.Errorf("Get(%q) error = %v", MethodBankTransfer, err)
+			}
+		})
+	}
+}
+
+func TestProviderInitWebhookSecrets(t *testing.T) {
+	v := newTestPaymentConf()
+	v.Set("payment_mockEnabled", true)
+
+	registry, err := ProviderInit(v)
+	if err != nil {
+		t.Fatalf("ProviderInit error = %v", err)
+	}
+
+	// each provider only accepts the webhooks signed with its own secret
+	body := []byte(`{"event_id":"evt_1","reference":"ref_1","status":"paid"}`)
+	secrets := map[string]string{
+		MethodBankTransfer: "bank transfer secret",
+		MethodEwallet:      "ewallet secret",
+		MethodCOD:          "cod secret",
+		MethodMock:         "mock secret",
+	}
+	for method := range secrets {
+		provider, err := registry.Get(method)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", method, err)
+		}
+
+		for signer, secret := range secrets {
+			_, err := provider.ParseWebhook(Sign(secret, body), body)
+			if signer == method && err != nil {
+				t.Errorf("%s webhook signed with its secret error = %v", method, err)
+			}
+			if signer != method && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("%s webhook signed with the %s secret error = %v, want ErrInvalidSignature", method, signer, err)
+			}
+		}
+	}
+
+	// a provider offered without its secret stops the app from starting
+	for _, key := range []string{"payment_bankTransferWebhookSecret", "payment_ewalletWebhookSecret", "payment_codWebhookSecret", "payment_mockWebhookSecret"} {
+		v := newTestPaymentConf()
+		v.Set("payment_mockEnabled", true)
+		v.Set(key, "")
+		if _, err := ProviderInit(v); err == nil {
+			t.Errorf("ProviderInit without %s succeeded", key)
+		}
+	}
+
+	// the secret of the mock provider is only required when it is enabled
+	v = newTestPaymentConf()
+	v.Set("payment_mockWebhookSecret", "")
+	if _, err := ProviderInit(v); err != nil {
+		t.Errorf("ProviderInit without the mock provider nor its secret error = %v", err)
+	}
+}
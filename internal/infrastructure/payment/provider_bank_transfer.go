@@ -0,0 +1,84 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+type BankTransferProvider struct {
+	secret   string
+	vaPrefix string
+}
+
+type bankTransferWebhook struct {
+	TransactionId     string `json:"transaction_id"`
+	VaNumber          string `json:"va_number"`
+	Reference         string `json:"reference"`
+	TransactionStatus string `json:"transaction_status"`
+}
+
+// NewBankTransferProvider returns the provider paying the trx through a bank virtual account
+func NewBankTransferProvider(secret, vaPrefix string) PaymentProvider {
+	return &BankTransferProvider{
+		secret:   secret,
+		vaPrefix: vaPrefix,
+	}
+}
+
+// Name returns the method bayar handled by the provider
+func (p *BankTransferProvider) Name() string {
+	return MethodBankTransfer
+}
+
+// CreateIntent issues a virtual account number the buyer has to transfer the amount to
+func (p *BankTransferProvider) CreateIntent(ctx context.Context, req *IntentReq) (res *Intent, err error) {
+	reference, err := newReference("va")
+	if err != nil {
+		return nil, err
+	}
+
+	vaSuffix, err := rand.Int(rand.Reader, big.NewInt(1e10))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Intent{
+		Provider:  p.Name(),
+		Reference: reference,
+		Amount:    req.Amount,
+		Status:    StatusPending,
+		Instruksi: fmt.Sprintf("Transfer Rp%d ke virtual account %s%010d", req.Amount, p.vaPrefix, vaSuffix.Int64()),
+		ExpiredAt: time.Now().Add(24 * time.Hour),
+	}, nil
+}
+
+// ParseWebhook verifies and parses the settlement notification sent by the bank
+func (p *BankTransferProvider) ParseWebhook(signature string, body []byte) (res *WebhookEvent, err error) {
+	if err := verifySignature(p.secret, signature, body); err != nil {
+		return nil, err
+	}
+
+	payload := &bankTransferWebhook{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, err
+	}
+
+	res = &WebhookEvent{
+		EventId:   payload.TransactionId,
+		Reference: payload.Reference,
+	}
+	switch payload.TransactionStatus {
+	case "settlement":
+		res.Status = StatusPaid
+	case "expire", "cancel":
+		res.Status = StatusFailed
+	default:
+		res.Status = StatusPending
+	}
+
+	return res, nil
+}
@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type CODProvider struct {
+	secret string
+}
+
+type codWebhook struct {
+	EventId   string `json:"event_id"`
+	Reference string `json:"reference"`
+	Collected bool   `json:"collected"`
+}
+
+// NewCODProvider returns the provider collecting the payment in cash when the trx is delivered
+func NewCODProvider(secret string) PaymentProvider {
+	return &CODProvider{
+		secret: secret,
+	}
+}
+
+// Name returns the method bayar handled by the provider
+func (p *CODProvider) Name() string {
+	return MethodCOD
+}
+
+// CreateIntent records the amount the courier has to collect on delivery
+func (p *CODProvider) CreateIntent(ctx context.Context, req *IntentReq) (res *Intent, err error) {
+	reference, err := newReference("cod")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Intent{
+		Provider:  p.Name(),
+		Reference: reference,
+		Amount:    req.Amount,
+		Status:    StatusPending,
+		Instruksi: fmt.Sprintf("Siapkan Rp%d untuk dibayarkan kepada kurir saat pesanan diterima", req.Amount),
+	}, nil
+}
+
+// ParseWebhook verifies and parses the cash collection report sent by the courier
+func (p *CODProvider) ParseWebhook(signature string, body []byte) (res *WebhookEvent, err error) {
+	if err := verifySignature(p.secret, signature, body); err != nil {
+		return nil, err
+	}
+
+	payload := &codWebhook{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, err
+	}
+
+	res = &WebhookEvent{
+		EventId:   payload.EventId,
+		Reference: payload.Reference,
+		Status:    StatusFailed,
+	}
+	if payload.Collected {
+		res.Status = StatusPaid
+	}
+
+	return res, nil
+}
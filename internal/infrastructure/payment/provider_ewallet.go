@@ -0,0 +1,76 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type EwalletProvider struct {
+	secret      string
+	checkoutUrl string
+}
+
+type ewalletWebhook struct {
+	Id          string `json:"id"`
+	ReferenceId string `json:"reference_id"`
+	Status      string `json:"status"`
+}
+
+// NewEwalletProvider returns the provider paying the trx through an e-wallet checkout page
+func NewEwalletProvider(secret, checkoutUrl string) PaymentProvider {
+	return &EwalletProvider{
+		secret:      secret,
+		checkoutUrl: checkoutUrl,
+	}
+}
+
+// Name returns the method bayar handled by the provider
+func (p *EwalletProvider) Name() string {
+	return MethodEwallet
+}
+
+// CreateIntent issues the checkout page the buyer has to open to authorize the payment
+func (p *EwalletProvider) CreateIntent(ctx context.Context, req *IntentReq) (res *Intent, err error) {
+	reference, err := newReference("ew")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Intent{
+		Provider:  p.Name(),
+		Reference: reference,
+		Amount:    req.Amount,
+		Status:    StatusPending,
+		Instruksi: fmt.Sprintf("%s/%s", p.checkoutUrl, reference),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil
+}
+
+// ParseWebhook verifies and parses the charge notification sent by the e-wallet
+func (p *EwalletProvider) ParseWebhook(signature string, body []byte) (res *WebhookEvent, err error) {
+	if err := verifySignature(p.secret, signature, body); err != nil {
+		return nil, err
+	}
+
+	payload := &ewalletWebhook{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, err
+	}
+
+	res = &WebhookEvent{
+		EventId:   payload.Id,
+		Reference: payload.ReferenceId,
+	}
+	switch payload.Status {
+	case "SUCCEEDED":
+		res.Status = StatusPaid
+	case "FAILED", "VOIDED":
+		res.Status = StatusFailed
+	default:
+		res.Status = StatusPending
+	}
+
+	return res, nil
+}
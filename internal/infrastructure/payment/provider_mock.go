@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type MockProvider struct {
+	secret string
+}
+
+type mockWebhook struct {
+	EventId   string `json:"event_id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+}
+
+// NewMockProvider returns the provider running the whole payment flow locally without any external service
+func NewMockProvider(secret string) PaymentProvider {
+	return &MockProvider{
+		secret: secret,
+	}
+}
+
+// Name returns the method bayar handled by the provider
+func (p *MockProvider) Name() string {
+	return MethodMock
+}
+
+// CreateIntent issues a local payment that is settled by posting a signed webhook
+func (p *MockProvider) CreateIntent(ctx context.Context, req *IntentReq) (res *Intent, err error) {
+	reference, err := newReference("mock")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Intent{
+		Provider:  p.Name(),
+		Reference: reference,
+		Amount:    req.Amount,
+		Status:    StatusPending,
+		Instruksi: fmt.Sprintf("POST {\"event_id\":\"...\",\"reference\":\"%s\",\"status\":\"paid\"} ke /api/v1/payment/webhook/%s", reference, p.Name()),
+		ExpiredAt: time.Now().Add(24 * time.Hour),
+	}, nil
+}
+
+// ParseWebhook verifies and parses the webhook posted to settle the local payment
+func (p *MockProvider) ParseWebhook(signature string, body []byte) (res *WebhookEvent, err error) {
+	if err := verifySignature(p.secret, signature, body); err != nil {
+		return nil, err
+	}
+
+	payload := &mockWebhook{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, err
+	}
+
+	res = &WebhookEvent{
+		EventId:   payload.EventId,
+		Reference: payload.Reference,
+		Status:    StatusPending,
+	}
+	if payload.Status == StatusPaid || payload.Status == StatusFailed {
+		res.Status = payload.Status
+	}
+
+	return res, nil
+}
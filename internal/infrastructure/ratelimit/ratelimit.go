@@ -0,0 +1,211 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ProviderMemory = "memory"
+	ProviderRedis  = "redis"
+)
+
+// failureWindow is how long a failed attempt counts towards the lockout of its key
+const failureWindow = 24 * time.Hour
+
+type RateLimitConf struct {
+	Provider      string `mapstructure:"ratelimit_provider"`
+	RedisAddr     string `mapstructure:"ratelimit_redisAddr"`
+	RedisPassword string `mapstructure:"ratelimit_redisPassword"`
+	RedisDb       int    `mapstructure:"ratelimit_redisDb"`
+
+	Login            string        `mapstructure:"ratelimit_login"`
+	LoginNotelp      string        `mapstructure:"ratelimit_loginNotelp"`
+	Register         string        `mapstructure:"ratelimit_register"`
+	LockoutThreshold int           `mapstructure:"ratelimit_lockoutThreshold"`
+	LockoutBase      time.Duration `mapstructure:"ratelimit_lockoutBase"`
+	LockoutMax       time.Duration `mapstructure:"ratelimit_lockoutMax"`
+}
+
+// Result is the state of the sliding window of a key after a hit has been taken
+type Result struct {
+	Allowed    bool
+	Count      int           // the hits within the window, including the taken hit when allowed
+	RetryAfter time.Duration // the wait before a hit is allowed again, 0 when allowed
+}
+
+// Store keeps the hits of the sliding windows and the locks of the keys, shared by every instance of the app when
+// the store is shared
+type Store interface {
+	// Take records a hit of the key at now unless its window already holds limit hits, a limit of 0 recording it unconditionally
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (res *Result, err error)
+	// Reset forgets the hits of the key
+	Reset(ctx context.Context, key string) (err error)
+	// Lock locks the key for the duration
+	Lock(ctx context.Context, key string, duration time.Duration) (err error)
+	// LockedFor returns the remaining duration of the lock of the key, 0 when the key is not locked
+	LockedFor(ctx context.Context, key string) (res time.Duration, err error)
+}
+
+// Rule allows Limit hits within any Window long period
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule parses a rule written as limit/window, e.g. 10/1m for 10 hits a minute
+func ParseRule(s string) (res Rule, err error) {
+	limit, window, found := strings.Cut(s, "/")
+	if !found {
+		return res, fmt.Errorf("rate limit %q must be written as limit/window, e.g. 10/1m", s)
+	}
+
+	res.Limit, err = strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || res.Limit <= 0 {
+		return res, fmt.Errorf("rate limit %q must have a positive limit", s)
+	}
+
+	res.Window, err = time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || res.Window <= 0 {
+		return res, fmt.Errorf("rate limit %q must have a positive window", s)
+	}
+
+	return res, nil
+}
+
+// Rules lists the rate limits of the auth paths and the progressive lockout of the failed logins
+type Rules struct {
+	Login       Rule // per ip
+	LoginNotelp Rule // per notelp
+	Register    Rule // per ip
+
+	// LockoutThreshold failed attempts lock the key for LockoutBase, each further failure doubling the lockout up to LockoutMax
+	LockoutThreshold int
+	LockoutBase      time.Duration
+	LockoutMax       time.Duration
+}
+
+// Limiter applies the rules on the sliding windows of the store
+type Limiter struct {
+	store Store
+	Rules Rules
+}
+
+// ProviderInit initializes the limiter on the store chosen by the configuration, the memory store being the default.
+// The memory store is only suited to a single instance of the app
+func ProviderInit(v *viper.Viper) (*Limiter, error) {
+	conf := RateLimitConf{}
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	rules, err := conf.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	switch conf.Provider {
+	case "", ProviderMemory:
+		return NewLimiter(NewMemoryStore(), rules), nil
+	case ProviderRedis:
+		store, err := NewRedisStore(&conf)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewLimiter(store, rules), nil
+	default:
+		return nil, fmt.Errorf("rate limit provider %s is not supported", conf.Provider)
+	}
+}
+
+// rules returns the rules of the configuration, defaulting the missing ones
+func (conf *RateLimitConf) rules() (res Rules, err error) {
+	res = Rules{
+		Login:            Rule{Limit: 20, Window: 5 * time.Minute},
+		LoginNotelp:      Rule{Limit: 10, Window: 15 * time.Minute},
+		Register:         Rule{Limit: 5, Window: time.Hour},
+		LockoutThreshold: conf.LockoutThreshold,
+		LockoutBase:      conf.LockoutBase,
+		LockoutMax:       conf.LockoutMax,
+	}
+
+	for _, v := range []struct {
+		conf string
+		rule *Rule
+	}{
+		{conf.Login, &res.Login},
+		{conf.LoginNotelp, &res.LoginNotelp},
+		{conf.Register, &res.Register},
+	} {
+		if v.conf == "" {
+			continue
+		}
+
+		if *v.rule, err = ParseRule(v.conf); err != nil {
+			return res, err
+		}
+	}
+
+	if res.LockoutThreshold <= 0 {
+		res.LockoutThreshold = 5
+	}
+	if res.LockoutBase <= 0 {
+		res.LockoutBase = time.Minute
+	}
+	if res.LockoutMax < res.LockoutBase {
+		res.LockoutMax = time.Hour
+	}
+
+	return res, nil
+}
+
+// NewLimiter returns the limiter applying the rules on the store
+func NewLimiter(store Store, rules Rules) *Limiter {
+	return &Limiter{
+		store: store,
+		Rules: rules,
+	}
+}
+
+// Allow takes a hit of the key within the limit of the rule
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (res *Result, err error) {
+	return l.store.Take(ctx, "hit:"+key, rule.Limit, rule.Window, time.Now())
+}
+
+// LockedFor returns the remaining lockout of the key, 0 when the key is not locked out
+func (l *Limiter) LockedFor(ctx context.Context, key string) (res time.Duration, err error) {
+	return l.store.LockedFor(ctx, "lock:"+key)
+}
+
+// Fail records a failed attempt of the key and returns the lockout it results in, 0 until the failures reach the threshold
+func (l *Limiter) Fail(ctx context.Context, key string) (res time.Duration, err error) {
+	failures, err := l.store.Take(ctx, "fail:"+key, 0, failureWindow, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if failures.Count < l.Rules.LockoutThreshold {
+		return 0, nil
+	}
+
+	res = l.Rules.LockoutBase
+	for i := l.Rules.LockoutThreshold; i < failures.Count && res < l.Rules.LockoutMax; i++ {
+		res *= 2
+	}
+	if res > l.Rules.LockoutMax {
+		res = l.Rules.LockoutMax
+	}
+
+	return res, l.store.Lock(ctx, "lock:"+key, res)
+}
+
+// Succeed forgets the failed attempts of the key
+func (l *Limiter) Succeed(ctx context.Context, key string) (err error) {
+	return l.store.Reset(ctx, "fail:"+key)
+}
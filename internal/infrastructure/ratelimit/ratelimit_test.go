@@ -0,0 +1,270 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// testStores returns the memory store and the redis store on a fake server, so both are held to the same behavior
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	redis, err := NewRedisStore(&RateLimitConf{RedisAddr: newFakeRedis(t, "").addr()})
+	if err != nil {
+		t.Fatalf("NewRedisStore error = %v", err)
+	}
+
+	return map[string]Store{
+		ProviderMemory: NewMemoryStore(),
+		ProviderRedis:  redis,
+	}
+}
+
+func TestStoreTake(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+			tests := []struct {
+				name           string
+				at             time.Duration
+				wantAllowed    bool
+				wantCount      int
+				wantRetryAfter time.Duration
+			}{
+				{"first hit", 0, true, 1, 0},
+				{"second hit", 10 * time.Second, true, 2, 0},
+				{"last hit of the limit", 20 * time.Second, true, 3, 0},
+				{"over the limit until the first hit slides out", 30 * time.Second, false, 3, 30 * time.Second},
+				{"just before the first hit slides out", time.Minute - time.Millisecond, false, 3, time.Millisecond},
+				{"the first hit slid out", time.Minute, true, 3, 0},
+				{"the second hit is now the oldest", time.Minute + time.Second, false, 3, 9 * time.Second},
+				{"every hit slid out", 3 * time.Minute, true, 1, 0},
+			}
+
+			for _, tt := range tests {
+				res, err := store.Take(ctx, "hit:1.2.3.4", 3, time.Minute, start.Add(tt.at))
+				if err != nil {
+					t.Fatalf("%s: Take error = %v", tt.name, err)
+				}
+
+				if res.Allowed != tt.wantAllowed || res.Count != tt.wantCount || res.RetryAfter != tt.wantRetryAfter {
+					t.Errorf("%s: Take = %+v, want allowed %v, count %d, retry after %s", tt.name, res, tt.wantAllowed, tt.wantCount, tt.wantRetryAfter)
+				}
+			}
+
+			// the windows of the keys are apart
+			if res, err := store.Take(ctx, "hit:5.6.7.8", 3, time.Minute, start.Add(3*time.Minute)); err != nil || !res.Allowed || res.Count != 1 {
+				t.Errorf("Take of another key = %+v, %v, want the first hit", res, err)
+			}
+
+			// a limit of 0 records every hit
+			for i := 1; i <= 5; i++ {
+				res, err := store.Take(ctx, "fail:0811", 0, time.Hour, start.Add(time.Duration(i)*time.Second))
+				if err != nil || !res.Allowed || res.Count != i {
+					t.Errorf("Take without limit %d = %+v, %v, want allowed with count %d", i, res, err, i)
+				}
+			}
+
+			if err := store.Reset(ctx, "fail:0811"); err != nil {
+				t.Fatalf("Reset error = %v", err)
+			}
+			if res, err := store.Take(ctx, "fail:0811", 0, time.Hour, start.Add(10*time.Second)); err != nil || res.Count != 1 {
+				t.Errorf("Take after Reset = %+v, %v, want count 1", res, err)
+			}
+		})
+	}
+}
+
+func TestStoreLock(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if res, err := store.LockedFor(ctx, "lock:0811"); err != nil || res != 0 {
+				t.Errorf("LockedFor of an unlocked key = %s, %v, want 0", res, err)
+			}
+
+			if err := store.Lock(ctx, "lock:0811", time.Minute); err != nil {
+				t.Fatalf("Lock error = %v", err)
+			}
+			if res, err := store.LockedFor(ctx, "lock:0811"); err != nil || res <= 59*time.Second || res > time.Minute {
+				t.Errorf("LockedFor = %s, %v, want about 1m", res, err)
+			}
+
+			if err := store.Lock(ctx, "lock:0812", 20*time.Millisecond); err != nil {
+				t.Fatalf("Lock error = %v", err)
+			}
+			time.Sleep(30 * time.Millisecond)
+			if res, err := store.LockedFor(ctx, "lock:0812"); err != nil || res != 0 {
+				t.Errorf("LockedFor of an expired lock = %s, %v, want 0", res, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	start := time.Now()
+
+	for _, key := range []string{"a", "b"} {
+		if _, err := store.Take(ctx, key, 5, time.Second, start); err != nil {
+			t.Fatalf("Take error = %v", err)
+		}
+	}
+	if err := store.Lock(ctx, "lock:a", time.Millisecond); err != nil {
+		t.Fatalf("Lock error = %v", err)
+	}
+
+	// the windows without any hit left and the expired locks are dropped once the sweep interval passed
+	if _, err := store.Take(ctx, "c", 5, time.Second, start.Add(memorySweepInterval+time.Second)); err != nil {
+		t.Fatalf("Take error = %v", err)
+	}
+	if len(store.windows) != 1 || store.windows["c"] == nil {
+		t.Errorf("windows after sweep = %v, want c only", store.windows)
+	}
+	if len(store.locks) != 0 {
+		t.Errorf("locks after sweep = %v, want none", store.locks)
+	}
+}
+
+func TestLimiterLockout(t *testing.T) {
+	rules := Rules{
+		LockoutThreshold: 3,
+		LockoutBase:      time.Minute,
+		LockoutMax:       10 * time.Minute,
+	}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			limiter := NewLimiter(store, rules)
+			ctx := context.Background()
+
+			// the lockout starts at the threshold and doubles with each further failure up to the max
+			for i, want := range []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+				res, err := limiter.Fail(ctx, "0811")
+				if err != nil {
+					t.Fatalf("Fail error = %v", err)
+				}
+				if res != want {
+					t.Errorf("failure %d lockout = %s, want %s", i+1, res, want)
+				}
+
+				locked, err := limiter.LockedFor(ctx, "0811")
+				if err != nil {
+					t.Fatalf("LockedFor error = %v", err)
+				}
+				if want == 0 && locked != 0 || want != 0 && (locked <= want-time.Second || locked > want) {
+					t.Errorf("failure %d locked for %s, want %s", i+1, locked, want)
+				}
+			}
+
+			// a success forgets the failures, the next failure being the first again
+			if err := limiter.Succeed(ctx, "0811"); err != nil {
+				t.Fatalf("Succeed error = %v", err)
+			}
+			for i := 0; i < rules.LockoutThreshold-1; i++ {
+				if res, err := limiter.Fail(ctx, "0811"); err != nil || res != 0 {
+					t.Errorf("failure %d after Succeed lockout = %s, %v, want 0", i+1, res, err)
+				}
+			}
+			if res, err := limiter.Fail(ctx, "0811"); err != nil || res != time.Minute {
+				t.Errorf("failure at the threshold after Succeed lockout = %s, %v, want 1m", res, err)
+			}
+
+			// the failures of a key do not lock another one
+			if locked, err := limiter.LockedFor(ctx, "0812"); err != nil || locked != 0 {
+				t.Errorf("other key locked for %s, %v, want 0", locked, err)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), Rules{})
+	rule := Rule{Limit: 2, Window: time.Minute}
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		res, err := limiter.Allow(ctx, "login:1.2.3.4", rule)
+		if err != nil {
+			t.Fatalf("Allow error = %v", err)
+		}
+		if res.Allowed != want {
+			t.Errorf("hit %d allowed = %v, want %v", i+1, res.Allowed, want)
+		}
+		if !want && (res.RetryAfter <= 0 || res.RetryAfter > time.Minute) {
+			t.Errorf("hit %d retry after %s, want within 1m", i+1, res.RetryAfter)
+		}
+	}
+
+	// the hits and the failures of a key are counted apart
+	if res, err := limiter.Fail(ctx, "login:1.2.3.4"); err != nil || res != 0 {
+		t.Errorf("Fail = %s, %v, want 0", res, err)
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rule
+		wantErr bool
+	}{
+		{"10/1m", Rule{Limit: 10, Window: time.Minute}, false},
+		{" 5 / 1h ", Rule{Limit: 5, Window: time.Hour}, false},
+		{"10", Rule{}, true},
+		{"0/1m", Rule{}, true},
+		{"10/0s", Rule{}, true},
+		{"x/1m", Rule{}, true},
+		{"10/minute", Rule{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRule(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestProviderInit(t *testing.T) {
+	v := viper.New()
+	v.Set("ratelimit_login", "3/10s")
+
+	limiter, err := ProviderInit(v)
+	if err != nil {
+		t.Fatalf("ProviderInit error = %v", err)
+	}
+
+	if _, ok := limiter.store.(*MemoryStore); !ok {
+		t.Errorf("store = %T, want *MemoryStore", limiter.store)
+	}
+
+	want := Rules{
+		Login:            Rule{Limit: 3, Window: 10 * time.Second},
+		LoginNotelp:      Rule{Limit: 10, Window: 15 * time.Minute},
+		Register:         Rule{Limit: 5, Window: time.Hour},
+		LockoutThreshold: 5,
+		LockoutBase:      time.Minute,
+		LockoutMax:       time.Hour,
+	}
+	if limiter.Rules != want {
+		t.Errorf("rules = %+v, want %+v", limiter.Rules, want)
+	}
+
+	for _, provider := range []string{ProviderRedis, "memcached"} {
+		v.Set("ratelimit_provider", provider)
+		if _, err := ProviderInit(v); err == nil {
+			t.Errorf("ProviderInit of %s without its configuration succeeded", provider)
+		}
+	}
+}
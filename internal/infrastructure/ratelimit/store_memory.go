@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often the expired windows and locks are dropped
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps the windows and the locks in the memory of the app, so each instance of the app limits on its own
+type MemoryStore struct {
+	mu        sync.Mutex
+	windows   map[string]*memoryWindow
+	locks     map[string]time.Time
+	lastSweep time.Time
+}
+
+type memoryWindow struct {
+	hits   []time.Time // oldest first
+	window time.Duration
+}
+
+// NewMemoryStore returns an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		windows: map[string]*memoryWindow{},
+		locks:   map[string]time.Time{},
+	}
+}
+
+// Take records a hit of the key at now unless its window already holds limit hits
+func (s *MemoryStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (res *Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &memoryWindow{}
+		s.windows[key] = w
+	}
+	w.window = window
+	w.prune(now)
+
+	if limit > 0 && len(w.hits) >= limit {
+		return &Result{
+			Allowed:    false,
+			Count:      len(w.hits),
+			RetryAfter: w.hits[0].Add(window).Sub(now),
+		}, nil
+	}
+
+	w.hits = append(w.hits, now)
+	return &Result{
+		Allowed: true,
+		Count:   len(w.hits),
+	}, nil
+}
+
+// Reset forgets the hits of the key
+func (s *MemoryStore) Reset(ctx context.Context, key string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.windows, key)
+	return nil
+}
+
+// Lock locks the key for the duration
+func (s *MemoryStore) Lock(ctx context.Context, key string, duration time.Duration) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[key] = time.Now().Add(duration)
+	return nil
+}
+
+// LockedFor returns the remaining duration of the lock of the key
+func (s *MemoryStore) LockedFor(ctx context.Context, key string) (res time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if res = time.Until(s.locks[key]); res <= 0 {
+		delete(s.locks, key)
+		return 0, nil
+	}
+
+	return res, nil
+}
+
+// sweep drops the windows without any hit left and the expired locks, at most once per memorySweepInterval
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, w := range s.windows {
+		if w.prune(now); len(w.hits) == 0 {
+			delete(s.windows, key)
+		}
+	}
+
+	for key, until := range s.locks {
+		if !until.After(now) {
+			delete(s.locks, key)
+		}
+	}
+}
+
+// prune drops the hits which slid out of the window ending at now
+func (w *memoryWindow) prune(now time.Time) {
+	i := 0
+	for i < len(w.hits) && !w.hits[i].After(now.Add(-w.window)) {
+		i++
+	}
+	w.hits = w.hits[i:]
+}
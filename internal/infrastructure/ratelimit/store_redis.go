@@ -0,0 +1,264 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	redisKeyPrefix = "ratelimit:"
+	redisTimeout   = 2 * time.Second
+	redisPoolSize  = 8
+)
+
+// redisTakeScript prunes the sorted set of the hits of the window and adds the hit under the limit in a single step,
+// returning whether it was allowed, the hits of the window and the milliseconds before a hit is allowed again
+const redisTakeScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if limit > 0 and count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, count, tonumber(oldest[2]) + window - now}
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, count + 1, 0}
+`
+
+// RedisStore keeps the windows as sorted sets and the locks as expiring keys of a redis compatible server,
+// such as redis, valkey or keydb, so every instance of the app shares them
+type RedisStore struct {
+	addr     string
+	password string
+	db       int
+	conns    chan *redisConn
+	seq      uint64
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// redisError is an error replied by the server, which leaves the connection usable
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// NewRedisStore returns the store on the redis server of the configuration
+func NewRedisStore(conf *RateLimitConf) (*RedisStore, error) {
+	if conf.RedisAddr == "" {
+		return nil, errors.New("ratelimit_redisAddr is required")
+	}
+
+	return &RedisStore{
+		addr:     conf.RedisAddr,
+		password: conf.RedisPassword,
+		db:       conf.RedisDb,
+		conns:    make(chan *redisConn, redisPoolSize),
+	}, nil
+}
+
+// Take records a hit of the key at now unless its window already holds limit hits
+func (s *RedisStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (res *Result, err error) {
+	// the member only has to be unique, the score holds the time of the hit
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), atomic.AddUint64(&s.seq, 1))
+
+	reply, err := s.do(ctx, "EVAL", redisTakeScript, "1", redisKeyPrefix+key,
+		strconv.FormatInt(now.UnixMilli(), 10), strconv.FormatInt(window.Milliseconds(), 10), strconv.Itoa(limit), member)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("redis: unexpected reply %v", reply)
+	}
+
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	retryAfter, _ := values[2].(int64)
+
+	return &Result{
+		Allowed:    allowed == 1,
+		Count:      int(count),
+		RetryAfter: time.Duration(retryAfter) * time.Millisecond,
+	}, nil
+}
+
+// Reset forgets the hits of the key
+func (s *RedisStore) Reset(ctx context.Context, key string) (err error) {
+	_, err = s.do(ctx, "DEL", redisKeyPrefix+key)
+	return err
+}
+
+// Lock locks the key for the duration
+func (s *RedisStore) Lock(ctx context.Context, key string, duration time.Duration) (err error) {
+	_, err = s.do(ctx, "SET", redisKeyPrefix+key, "1", "PX", strconv.FormatInt(duration.Milliseconds(), 10))
+	return err
+}
+
+// LockedFor returns the remaining duration of the lock of the key
+func (s *RedisStore) LockedFor(ctx context.Context, key string) (res time.Duration, err error) {
+	reply, err := s.do(ctx, "PTTL", redisKeyPrefix+key)
+	if err != nil {
+		return 0, err
+	}
+
+	// a missing key replies -2
+	ttl, _ := reply.(int64)
+	if ttl <= 0 {
+		return 0, nil
+	}
+
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+// do sends the command on a pooled connection and returns its reply
+func (s *RedisStore) do(ctx context.Context, args ...string) (reply interface{}, err error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err = c.do(ctx, args...)
+
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case s.conns <- c:
+	default:
+		c.conn.Close()
+	}
+
+	return reply, err
+}
+
+// conn returns an idle connection of the pool, or else a new one authenticated and on the configured db
+func (s *RedisStore) conn(ctx context.Context) (res *redisConn, err error) {
+	select {
+	case res = <-s.conns:
+		return res, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &redisConn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	if s.password != "" {
+		if _, err := res.do(ctx, "AUTH", s.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := res.do(ctx, "SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// do writes the command as a resp array of bulk strings and reads its reply
+func (c *redisConn) do(ctx context.Context, args ...string) (reply interface{}, err error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	buff := make([]byte, 0, 64)
+	buff = append(buff, '*')
+	buff = strconv.AppendInt(buff, int64(len(args)), 10)
+	buff = append(buff, '\r', '\n')
+	for _, v := range args {
+		buff = append(buff, '$')
+		buff = strconv.AppendInt(buff, int64(len(v)), 10)
+		buff = append(buff, '\r', '\n')
+		buff = append(buff, v...)
+		buff = append(buff, '\r', '\n')
+	}
+
+	if _, err := c.conn.Write(buff); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+// readReply reads a resp reply: a simple string, an error, an integer, a bulk string or an array of them
+func (c *redisConn) readReply() (reply interface{}, err error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, redisError(value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		values := make([]interface{}, n)
+		for i := range values {
+			values[i], err = c.readReply()
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", kind)
+	}
+}
@@ -0,0 +1,343 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a stand-in of a redis server speaking resp, emulating the commands sent by the RedisStore:
+// the take script on sorted sets of scores, DEL, SET PX and PTTL. DROP closes the connection without replying
+type fakeRedis struct {
+	t        *testing.T
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	zsets    map[string][]int64
+	expires  map[string]time.Time
+	conns    int
+	commands []string
+}
+
+// newFakeRedis returns a fake redis server listening on a local port until the end of the test,
+// requiring the password unless empty
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	f := &fakeRedis{
+		t:        t,
+		ln:       ln,
+		password: password,
+		zsets:    map[string][]int64{},
+		expires:  map[string]time.Time{},
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			f.mu.Lock()
+			f.conns++
+			f.mu.Unlock()
+
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.ln.Addr().String()
+}
+
+// stats returns the connections accepted and the names of the commands received so far
+func (f *fakeRedis) stats() (conns int, commands []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.conns, append([]string{}, f.commands...)
+}
+
+// serve answers the commands of the connection, each a resp array of bulk strings
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				f.t.Errorf("fake redis: %s", err)
+			}
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args[0])
+		f.mu.Unlock()
+
+		var reply string
+		switch {
+		case args[0] == "DROP":
+			return
+		case args[0] == "AUTH":
+			if len(args) == 2 && args[1] == f.password {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		default:
+			reply = f.reply(args)
+		}
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// reply executes the command and returns its resp reply
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case args[0] == "SELECT" && len(args) == 2:
+		return "+OK\r\n"
+	case args[0] == "EVAL" && len(args) == 8 && args[1] == redisTakeScript && args[2] == "1":
+		key := args[3]
+		now, _ := strconv.ParseInt(args[4], 10, 64)
+		window, _ := strconv.ParseInt(args[5], 10, 64)
+		limit, _ := strconv.ParseInt(args[6], 10, 64)
+
+		// ZREMRANGEBYSCORE key -inf now-window, the scores being kept in order
+		scores := f.zsets[key]
+		for len(scores) > 0 && scores[0] <= now-window {
+			scores = scores[1:]
+		}
+		f.zsets[key] = scores
+
+		count := int64(len(scores))
+		if limit > 0 && count >= limit {
+			return fmt.Sprintf("*3\r\n:0\r\n:%d\r\n:%d\r\n", count, scores[0]+window-now)
+		}
+
+		f.zsets[key] = append(scores, now)
+		return fmt.Sprintf("*3\r\n:1\r\n:%d\r\n:0\r\n", count+1)
+	case args[0] == "DEL" && len(args) == 2:
+		_, inZsets := f.zsets[args[1]]
+		_, inExpires := f.expires[args[1]]
+		delete(f.zsets, args[1])
+		delete(f.expires, args[1])
+		if inZsets || inExpires {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case args[0] == "SET" && len(args) == 5 && args[3] == "PX":
+		ms, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil || ms <= 0 {
+			return "-ERR invalid expire time in 'set' command\r\n"
+		}
+		f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return "+OK\r\n"
+	case args[0] == "PTTL" && len(args) == 2:
+		until, ok := f.expires[args[1]]
+		if !ok || !until.After(time.Now()) {
+			delete(f.expires, args[1])
+			return ":-2\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(until).Milliseconds())
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// readCommand reads a resp array of bulk strings, reading each bulk string by its length as the take script spans lines
+func readCommand(r *bufio.Reader) (args []string, err error) {
+	n, err := readLength(r, '*')
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < n; i++ {
+		size, err := readLength(r, '$')
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if string(data[size:]) != "\r\n" {
+			return nil, fmt.Errorf("bulk string not terminated by crlf")
+		}
+		args = append(args, string(data[:size]))
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
+
+// readLength reads a line made of the prefix and a length
+func readLength(r *bufio.Reader, prefix byte) (res int, err error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasSuffix(line, "\r\n") || line[0] != prefix {
+		return 0, fmt.Errorf("malformed line %q", line)
+	}
+
+	return strconv.Atoi(line[1 : len(line)-2])
+}
+
+func TestRedisStoreConnections(t *testing.T) {
+	server := newFakeRedis(t, "rahasia")
+	store, err := NewRedisStore(&RateLimitConf{RedisAddr: server.addr(), RedisPassword: "rahasia", RedisDb: 2})
+	if err != nil {
+		t.Fatalf("NewRedisStore error = %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.Take(ctx, "hit:1.2.3.4", 10, time.Minute, time.Now()); err != nil {
+			t.Fatalf("Take error = %v", err)
+		}
+	}
+
+	// the connection is authenticated and switched to the db once, then reused
+	conns, commands := server.stats()
+	if conns != 1 {
+		t.Errorf("%d connections, want 1", conns)
+	}
+	if want := "AUTH SELECT EVAL EVAL EVAL"; strings.Join(commands, " ") != want {
+		t.Errorf("commands = %v, want %s", commands, want)
+	}
+
+	// an error reply is returned as such and leaves the connection usable
+	_, err = store.do(ctx, "BOGUS")
+	var replyErr redisError
+	if !errors.As(err, &replyErr) || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("do(BOGUS) error = %v, want the error reply", err)
+	}
+	if _, err := store.LockedFor(ctx, "lock:0811"); err != nil {
+		t.Fatalf("LockedFor error = %v", err)
+	}
+	if conns, _ := server.stats(); conns != 1 {
+		t.Errorf("%d connections after an error reply, want 1", conns)
+	}
+
+	// a broken connection is dropped from the pool and the next command dials a new one
+	if _, err := store.do(ctx, "DROP"); err == nil {
+		t.Errorf("do(DROP) succeeded")
+	}
+	if _, err := store.LockedFor(ctx, "lock:0811"); err != nil {
+		t.Fatalf("LockedFor after a dropped connection error = %v", err)
+	}
+	if conns, _ := server.stats(); conns != 2 {
+		t.Errorf("%d connections after a dropped one, want 2", conns)
+	}
+}
+
+func TestRedisStoreWrongPassword(t *testing.T) {
+	server := newFakeRedis(t, "rahasia")
+	store, err := NewRedisStore(&RateLimitConf{RedisAddr: server.addr(), RedisPassword: "salah"})
+	if err != nil {
+		t.Fatalf("NewRedisStore error = %v", err)
+	}
+
+	if _, err := store.Take(context.Background(), "hit:1.2.3.4", 10, time.Minute, time.Now()); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Take error = %v, want WRONGPASS", err)
+	}
+	if len(store.conns) != 0 {
+		t.Errorf("connection refused by the server kept in the pool")
+	}
+}
+
+func TestRedisStoreUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	store, err := NewRedisStore(&RateLimitConf{RedisAddr: addr})
+	if err != nil {
+		t.Fatalf("NewRedisStore error = %v", err)
+	}
+	if _, err := store.LockedFor(context.Background(), "lock:0811"); err == nil {
+		t.Errorf("LockedFor on an unreachable server succeeded")
+	}
+
+	if _, err := NewRedisStore(&RateLimitConf{}); err == nil {
+		t.Errorf("NewRedisStore without an address succeeded")
+	}
+}
+
+func TestRedisConnReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    interface{}
+		wantErr string
+	}{
+		{"simple string", "+OK\r\n", "OK", ""},
+		{"integer", ":1500\r\n", int64(1500), ""},
+		{"negative integer", ":-2\r\n", int64(-2), ""},
+		{"bulk string", "$5\r\nhello\r\n", "hello", ""},
+		{"bulk string holding crlf", "$7\r\nhel\r\nlo\r\n", "hel\r\nlo", ""},
+		{"null bulk string", "$-1\r\n", nil, ""},
+		{"array", "*3\r\n:1\r\n:3\r\n:0\r\n", []interface{}{int64(1), int64(3), int64(0)}, ""},
+		{"error", "-ERR wrong number of arguments\r\n", nil, "redis: ERR wrong number of arguments"},
+		{"missing cr", "+OK\n", nil, "malformed reply"},
+		{"unknown type", "?1\r\n", nil, "unexpected reply type"},
+		{"truncated bulk string", "$5\r\nhel", nil, "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &redisConn{r: bufio.NewReader(strings.NewReader(tt.in))}
+			got, err := c.readReply()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("readReply error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readReply error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("readReply = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
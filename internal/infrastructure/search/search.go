@@ -0,0 +1,263 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/money"
+	"unicode"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	ProviderMySQL  = "mysql"
+	ProviderMemory = "memory"
+)
+
+// HargaBucketBounds are the lower bounds of the harga facet buckets, the last bucket has no upper bound
+var HargaBucketBounds = []money.Rupiah{0, 50000, 100000, 250000, 500000, 1000000}
+
+type SearchConf struct {
+	Provider string `mapstructure:"search_provider"`
+}
+
+type Query struct {
+	Keyword       string
+	CategoryId    uint
+	TokoId        uint
+	MinHarga      money.Rupiah
+	MaxHarga      money.Rupiah
+	Limit, Offset int
+}
+
+type Hit struct {
+	IdProduk uint
+	Score    float64
+}
+
+type FacetCount struct {
+	Id    uint
+	Nama  string
+	Count int
+}
+
+type HargaBucket struct {
+	Min   money.Rupiah
+	Max   money.Rupiah // 0 means the bucket has no upper bound
+	Count int
+}
+
+type Result struct {
+	Hits         []*Hit
+	Total        int
+	Keyword      string // the keyword after its typos got corrected
+	Categories   []*FacetCount
+	Tokos        []*FacetCount
+	HargaBuckets []*HargaBucket
+}
+
+// Document is the searchable data of a produk
+type Document struct {
+	IdProduk      uint
+	NamaProduk    string
+	Deskripsi     string
+	IdCategory    uint
+	NamaCategory  string
+	IdToko        uint
+	NamaToko      string
+	HargaKonsumen money.Rupiah
+}
+
+type ProductSearcher interface {
+	Search(ctx context.Context, query *Query) (res *Result, err error)
+	Index(ctx context.Context, docs ...*Document) (err error)
+	Remove(ctx context.Context, idProduks ...uint) (err error)
+}
+
+// ProviderInit initializes the product searcher chosen by the configuration, mysql being the default
+func ProviderInit(v *viper.Viper, db *gorm.DB) (ProductSearcher, error) {
+	conf := SearchConf{}
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	switch conf.Provider {
+	case "", ProviderMySQL:
+		return NewMySQLSearcher(db), nil
+	case ProviderMemory:
+		produks := []*daos.Produk{}
+		if err := db.Preload("Toko").Preload("Category").Find(&produks).Error; err != nil {
+			return nil, err
+		}
+
+		searcher := NewMemorySearcher()
+		docs := []*Document{}
+		for _, v := range produks {
+			docs = append(docs, DocumentFromProduk(v))
+		}
+
+		return searcher, searcher.Index(context.Background(), docs...)
+	default:
+		return nil, fmt.Errorf("search provider %s is not supported", conf.Provider)
+	}
+}
+
+// DocumentFromProduk returns the searchable data of the produk
+func DocumentFromProduk(data *daos.Produk) *Document {
+	res := &Document{
+		IdProduk:      data.ID,
+		NamaProduk:    data.NamaProduk,
+		Deskripsi:     data.Deskripsi,
+		IdCategory:    data.IdCategory,
+		IdToko:        data.IdToko,
+		HargaKonsumen: data.HargaKonsumen,
+	}
+
+	if data.Category != nil {
+		res.NamaCategory = data.Category.NamaCategory
+	}
+
+	if data.Toko != nil {
+		res.NamaToko = data.Toko.NamaToko
+	}
+
+	return res
+}
+
+// hargaBucketIndex returns the index of the harga facet bucket holding the harga
+func hargaBucketIndex(harga money.Rupiah) int {
+	for i := len(HargaBucketBounds) - 1; i > 0; i-- {
+		if harga >= HargaBucketBounds[i] {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// newHargaBuckets returns the empty harga facet buckets
+func newHargaBuckets() (res []*HargaBucket) {
+	for i, v := range HargaBucketBounds {
+		bucket := &HargaBucket{Min: v}
+		if i+1 < len(HargaBucketBounds) {
+			bucket.Max = HargaBucketBounds[i+1] - 1
+		}
+		res = append(res, bucket)
+	}
+
+	return res
+}
+
+// tokenize splits the text into lowercase words made of letters and digits
+func tokenize(text string) (res []string) {
+	for _, v := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(v)) >= 2 {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// maxTypos returns how many typos are tolerated in a term, longer terms tolerating more of them
+func maxTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// correctTerm returns the term itself when some word of the vocabulary starts with it,
+// otherwise the closest word of the vocabulary within the typo tolerance, or the term when there is none
+func correctTerm(term string, vocabulary map[string]struct{}) string {
+	best, bestDistance := "", maxTypos(term)
+	for word := range vocabulary {
+		if strings.HasPrefix(word, term) {
+			return term
+		}
+
+		// the limit sits above the best distance so a tie is told apart from a word stopped at the limit
+		distance := editDistance(term, word, bestDistance+1)
+		if distance > bestDistance {
+			continue
+		}
+
+		if best == "" || distance < bestDistance || word < best {
+			best, bestDistance = word, distance
+		}
+	}
+
+	if best == "" {
+		return term
+	}
+
+	return best
+}
+
+// editDistance returns the optimal string alignment distance between both words, where swapping two adjacent letters
+// counts as a single typo, stopping early once it reaches the limit
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff >= limit || -diff >= limit {
+		return limit
+	}
+
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if rowMin >= limit {
+			return limit
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	if prev[len(rb)] > limit {
+		return limit
+	}
+
+	return prev[len(rb)]
+}
+
+// minInt returns the smallest of the values
+func minInt(values ...int) int {
+	res := values[0]
+	for _, v := range values[1:] {
+		if v < res {
+			res = v
+		}
+	}
+
+	return res
+}
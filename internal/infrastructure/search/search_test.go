@@ -0,0 +1,99 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"tugas_akhir_example/internal/pkg/money"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Kaos Polos Hitam", []string{"kaos", "polos", "hitam"}},
+		{"kaos-polos, ukuran XL!", []string{"kaos", "polos", "ukuran", "xl"}},
+		{"a b cd 1 23", []string{"cd", "23"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"kaos", "kaos", 3, 0},
+		{"kaos", "kasos", 3, 1},
+		{"kemija", "kemeja", 3, 1},
+		{"kemeja", "kemej", 3, 1},
+		{"sepaut", "sepatu", 3, 1}, // swapping adjacent letters is one typo
+		{"spetau", "sepatu", 3, 2},
+		{"", "ab", 5, 2},
+		{"abc", "xyz", 2, 2}, // capped at the limit
+		{"a", "abcd", 2, 2},  // too different in length
+		{"sepatu", "tas", 9, 5},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestCorrectTerm(t *testing.T) {
+	vocabulary := map[string]struct{}{}
+	for _, v := range []string{"kaos", "kemeja", "sepatu", "lari", "baju", "bahu", "celana"} {
+		vocabulary[v] = struct{}{}
+	}
+
+	tests := []struct {
+		term, want string
+	}{
+		{"kaos", "kaos"},
+		{"kem", "kem"},       // a prefix of some word is kept as typed
+		{"kemija", "kemeja"}, // one typo within the 1 tolerated by 6 letters
+		{"sepaut", "sepatu"},
+		{"kaoss", "kaos"},
+		{"celenaa", "celana"}, // two typos within the 2 tolerated by 7 letters
+		{"spetau", "spetau"},  // two typos beyond the 1 tolerated by 6 letters
+		{"lri", "lri"},        // no typo tolerated by 3 letters
+		{"bagu", "bahu"},      // ties go to the first word alphabetically
+		{"xyzw", "xyzw"},
+	}
+
+	for _, tt := range tests {
+		if got := correctTerm(tt.term, vocabulary); got != tt.want {
+			t.Errorf("correctTerm(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestHargaBucketIndex(t *testing.T) {
+	tests := []struct {
+		harga money.Rupiah
+		want  int
+	}{
+		{0, 0},
+		{49999, 0},
+		{50000, 1},
+		{120000, 2},
+		{999999, 4},
+		{1000000, 5},
+		{50000000, 5},
+	}
+
+	for _, tt := range tests {
+		if got := hargaBucketIndex(tt.harga); got != tt.want {
+			t.Errorf("hargaBucketIndex(%d) = %d, want %d", tt.harga, got, tt.want)
+		}
+	}
+}
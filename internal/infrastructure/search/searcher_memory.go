@@ -0,0 +1,220 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const namaWeight = 2.0 // a term found in the nama produk weighs more than one found in the deskripsi
+
+type posting struct {
+	nama      int
+	deskripsi int
+}
+
+// MemorySearcher searches the produks through an inverted index kept in the memory of the process
+type MemorySearcher struct {
+	mu       sync.RWMutex
+	docs     map[uint]*Document
+	postings map[string]map[uint]*posting
+}
+
+// NewMemorySearcher returns an empty in-memory product searcher
+func NewMemorySearcher() *MemorySearcher {
+	return &MemorySearcher{
+		docs:     map[uint]*Document{},
+		postings: map[string]map[uint]*posting{},
+	}
+}
+
+// Index puts the documents into the index, replacing the ones already indexed
+func (s *MemorySearcher) Index(ctx context.Context, docs ...*Document) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range docs {
+		s.remove(doc.IdProduk)
+
+		s.docs[doc.IdProduk] = doc
+		for _, term := range tokenize(doc.NamaProduk) {
+			s.posting(term, doc.IdProduk).nama++
+		}
+
+		for _, term := range tokenize(doc.Deskripsi) {
+			s.posting(term, doc.IdProduk).deskripsi++
+		}
+	}
+
+	return nil
+}
+
+// Remove takes the documents of the produks out of the index
+func (s *MemorySearcher) Remove(ctx context.Context, idProduks ...uint) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range idProduks {
+		s.remove(id)
+	}
+
+	return nil
+}
+
+// Search ranks the documents matching the query by tf-idf and counts the facets of the matches
+func (s *MemorySearcher) Search(ctx context.Context, query *Query) (res *Result, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vocabulary := map[string]struct{}{}
+	for term := range s.postings {
+		vocabulary[term] = struct{}{}
+	}
+
+	terms := []string{}
+	for _, term := range tokenize(query.Keyword) {
+		terms = append(terms, correctTerm(term, vocabulary))
+	}
+
+	scores := map[uint]float64{}
+	if len(terms) == 0 {
+		for id := range s.docs {
+			scores[id] = 0
+		}
+	}
+
+	for _, term := range terms {
+		for word, postings := range s.postings {
+			if !strings.HasPrefix(word, term) {
+				continue
+			}
+
+			idf := math.Log(1 + float64(len(s.docs))/float64(len(postings)))
+			for id, p := range postings {
+				scores[id] += idf * (namaWeight*float64(p.nama) + float64(p.deskripsi))
+			}
+		}
+	}
+
+	res = &Result{
+		Hits:         []*Hit{},
+		Keyword:      strings.Join(terms, " "),
+		HargaBuckets: newHargaBuckets(),
+	}
+
+	categories, tokos := map[uint]*FacetCount{}, map[uint]*FacetCount{}
+	for id, score := range scores {
+		doc := s.docs[id]
+		inCategory := query.CategoryId == 0 || doc.IdCategory == query.CategoryId
+		inToko := query.TokoId == 0 || doc.IdToko == query.TokoId
+		inHarga := (query.MinHarga == 0 || doc.HargaKonsumen >= query.MinHarga) && (query.MaxHarga == 0 || doc.HargaKonsumen <= query.MaxHarga)
+
+		// every facet is counted without its own filter so the other choices stay visible
+		if inToko && inHarga {
+			countFacet(categories, doc.IdCategory, doc.NamaCategory)
+		}
+
+		if inCategory && inHarga {
+			countFacet(tokos, doc.IdToko, doc.NamaToko)
+		}
+
+		if inCategory && inToko {
+			res.HargaBuckets[hargaBucketIndex(doc.HargaKonsumen)].Count++
+		}
+
+		if inCategory && inToko && inHarga {
+			res.Hits = append(res.Hits, &Hit{IdProduk: id, Score: score})
+		}
+	}
+
+	sort.Slice(res.Hits, func(i, j int) bool {
+		if res.Hits[i].Score != res.Hits[j].Score {
+			return res.Hits[i].Score > res.Hits[j].Score
+		}
+
+		return res.Hits[i].IdProduk > res.Hits[j].IdProduk
+	})
+
+	res.Total = len(res.Hits)
+	res.Categories = sortedFacets(categories)
+	res.Tokos = sortedFacets(tokos)
+
+	if query.Offset >= len(res.Hits) {
+		res.Hits = []*Hit{}
+	} else {
+		res.Hits = res.Hits[query.Offset:]
+	}
+
+	if query.Limit > 0 && len(res.Hits) > query.Limit {
+		res.Hits = res.Hits[:query.Limit]
+	}
+
+	return res, nil
+}
+
+// posting returns the posting of the term in the document, creating it when missing
+func (s *MemorySearcher) posting(term string, id uint) *posting {
+	postings, ok := s.postings[term]
+	if !ok {
+		postings = map[uint]*posting{}
+		s.postings[term] = postings
+	}
+
+	p, ok := postings[id]
+	if !ok {
+		p = &posting{}
+		postings[id] = p
+	}
+
+	return p
+}
+
+// remove takes the document out of the index, the caller must hold the lock
+func (s *MemorySearcher) remove(id uint) {
+	doc, ok := s.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range append(tokenize(doc.NamaProduk), tokenize(doc.Deskripsi)...) {
+		if postings, ok := s.postings[term]; ok {
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(s.postings, term)
+			}
+		}
+	}
+
+	delete(s.docs, id)
+}
+
+// countFacet counts one more match of the facet value
+func countFacet(facets map[uint]*FacetCount, id uint, nama string) {
+	facet, ok := facets[id]
+	if !ok {
+		facet = &FacetCount{Id: id, Nama: nama}
+		facets[id] = facet
+	}
+
+	facet.Count++
+}
+
+// sortedFacets returns the facet values ordered from the most matched one
+func sortedFacets(facets map[uint]*FacetCount) (res []*FacetCount) {
+	res = []*FacetCount{}
+	for _, v := range facets {
+		res = append(res, v)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+
+		return res[i].Id < res[j].Id
+	})
+
+	return res
+}
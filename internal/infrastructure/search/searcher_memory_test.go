@@ -0,0 +1,264 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"tugas_akhir_example/internal/pkg/money"
+)
+
+// testDocuments are the produks of two tokos across three categories
+var testDocuments = []*Document{
+	{IdProduk: 1, NamaProduk: "Kaos Polos Hitam", Deskripsi: "kaos katun yang nyaman", IdCategory: 1, NamaCategory: "Pakaian", IdToko: 1, NamaToko: "Toko A", HargaKonsumen: 45000},
+	{IdProduk: 2, NamaProduk: "Kemeja Flanel", Deskripsi: "cocok dipadukan dengan kaos", IdCategory: 1, NamaCategory: "Pakaian", IdToko: 2, NamaToko: "Toko B", HargaKonsumen: 120000},
+	{IdProduk: 3, NamaProduk: "Sepatu Lari", Deskripsi: "sepatu ringan untuk lari pagi", IdCategory: 2, NamaCategory: "Sepatu", IdToko: 2, NamaToko: "Toko B", HargaKonsumen: 550000},
+	{IdProduk: 4, NamaProduk: "Tas Ransel", Deskripsi: "muat laptop 14 inci", IdCategory: 3, NamaCategory: "Tas", IdToko: 1, NamaToko: "Toko A", HargaKonsumen: 300000},
+	{IdProduk: 5, NamaProduk: "Laptop Bekas", Deskripsi: "mulus dan murah", IdCategory: 4, NamaCategory: "Elektronik", IdToko: 1, NamaToko: "Toko A", HargaKonsumen: 2500000},
+}
+
+// newTestSearcher returns a memory searcher holding the test documents
+func newTestSearcher(t *testing.T) *MemorySearcher {
+	t.Helper()
+
+	searcher := NewMemorySearcher()
+	if err := searcher.Index(context.Background(), testDocuments...); err != nil {
+		t.Fatalf("Index error = %v", err)
+	}
+
+	return searcher
+}
+
+// hitIds returns the produk ids of the hits in their order
+func hitIds(res *Result) (ids []uint) {
+	ids = []uint{}
+	for _, v := range res.Hits {
+		ids = append(ids, v.IdProduk)
+	}
+
+	return ids
+}
+
+// facetCounts returns the count of each facet value by its id
+func facetCounts(facets []*FacetCount) map[uint]int {
+	res := map[uint]int{}
+	for _, v := range facets {
+		res[v.Id] = v.Count
+	}
+
+	return res
+}
+
+func TestMemorySearcherSearch(t *testing.T) {
+	searcher := newTestSearcher(t)
+
+	tests := []struct {
+		name        string
+		query       *Query
+		wantIds     []uint
+		wantTotal   int
+		wantKeyword string
+	}{
+		{
+			name:      "term in nama and deskripsi ranks above term in deskripsi only",
+			query:     &Query{Keyword: "kaos"},
+			wantIds:   []uint{1, 2},
+			wantTotal: 2,
+		},
+		{
+			name:      "term in nama ranks above term in deskripsi",
+			query:     &Query{Keyword: "laptop"},
+			wantIds:   []uint{5, 4},
+			wantTotal: 2,
+		},
+		{
+			name:      "every term adds to the score",
+			query:     &Query{Keyword: "sepatu lari"},
+			wantIds:   []uint{3},
+			wantTotal: 1,
+		},
+		{
+			name:      "prefix of a word",
+			query:     &Query{Keyword: "kem"},
+			wantIds:   []uint{2},
+			wantTotal: 1,
+		},
+		{
+			name:        "typo within the tolerance is corrected",
+			query:       &Query{Keyword: "kemija"},
+			wantIds:     []uint{2},
+			wantTotal:   1,
+			wantKeyword: "kemeja",
+		},
+		{
+			name:        "swapped letters are corrected",
+			query:       &Query{Keyword: "sepaut"},
+			wantIds:     []uint{3},
+			wantTotal:   1,
+			wantKeyword: "sepatu",
+		},
+		{
+			name:        "typo beyond the tolerance matches nothing",
+			query:       &Query{Keyword: "spetau"},
+			wantIds:     []uint{},
+			wantTotal:   0,
+			wantKeyword: "spetau",
+		},
+		{
+			name:      "filters narrow the hits",
+			query:     &Query{Keyword: "kaos", TokoId: 2},
+			wantIds:   []uint{2},
+			wantTotal: 1,
+		},
+		{
+			name:      "harga range",
+			query:     &Query{MinHarga: 100000, MaxHarga: 550000},
+			wantIds:   []uint{4, 3, 2},
+			wantTotal: 3,
+		},
+		{
+			name:      "no keyword lists the newest produks first",
+			query:     &Query{},
+			wantIds:   []uint{5, 4, 3, 2, 1},
+			wantTotal: 5,
+		},
+		{
+			name:      "offset and limit page through the hits",
+			query:     &Query{Offset: 1, Limit: 2},
+			wantIds:   []uint{4, 3},
+			wantTotal: 5,
+		},
+		{
+			name:      "last page shorter than the limit",
+			query:     &Query{Offset: 4, Limit: 2},
+			wantIds:   []uint{1},
+			wantTotal: 5,
+		},
+		{
+			name:      "offset past the hits",
+			query:     &Query{Offset: 10, Limit: 2},
+			wantIds:   []uint{},
+			wantTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := searcher.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search error = %v", err)
+			}
+
+			if got := hitIds(res); !reflect.DeepEqual(got, tt.wantIds) {
+				t.Errorf("hits = %v, want %v", got, tt.wantIds)
+			}
+			if res.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", res.Total, tt.wantTotal)
+			}
+
+			wantKeyword := tt.wantKeyword
+			if wantKeyword == "" {
+				wantKeyword = tt.query.Keyword
+			}
+			if res.Keyword != wantKeyword {
+				t.Errorf("keyword = %q, want %q", res.Keyword, wantKeyword)
+			}
+		})
+	}
+}
+
+func TestMemorySearcherFacets(t *testing.T) {
+	searcher := newTestSearcher(t)
+
+	res, err := searcher.Search(context.Background(), &Query{CategoryId: 1, TokoId: 1})
+	if err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+
+	if got := hitIds(res); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("hits = %v, want [1]", got)
+	}
+
+	// the categories are counted within toko 1 whatever the category filter
+	if got, want := facetCounts(res.Categories), map[uint]int{1: 1, 3: 1, 4: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("category facets = %v, want %v", got, want)
+	}
+
+	// the tokos are counted within category 1 whatever the toko filter
+	if got, want := facetCounts(res.Tokos), map[uint]int{1: 1, 2: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("toko facets = %v, want %v", got, want)
+	}
+
+	// the harga buckets are counted within category 1 and toko 1
+	buckets := map[money.Rupiah]int{}
+	for _, v := range res.HargaBuckets {
+		buckets[v.Min] = v.Count
+	}
+	if want := (map[money.Rupiah]int{0: 1, 50000: 0, 100000: 0, 250000: 0, 500000: 0, 1000000: 0}); !reflect.DeepEqual(buckets, want) {
+		t.Errorf("harga buckets = %v, want %v", buckets, want)
+	}
+
+	// the harga filter leaves the harga buckets alone but narrows the other facets
+	res, err = searcher.Search(context.Background(), &Query{MinHarga: 250000})
+	if err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+
+	if got, want := facetCounts(res.Tokos), map[uint]int{1: 2, 2: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("toko facets = %v, want %v", got, want)
+	}
+	total := 0
+	for _, v := range res.HargaBuckets {
+		total += v.Count
+	}
+	if total != len(testDocuments) {
+		t.Errorf("harga buckets count %d produks, want %d", total, len(testDocuments))
+	}
+
+	// the facets are ordered from the most matched value
+	if len(res.Tokos) != 2 || res.Tokos[0].Id != 1 || res.Tokos[0].Nama != "Toko A" {
+		t.Errorf("toko facets = %+v, want toko 1 first", res.Tokos)
+	}
+}
+
+func TestMemorySearcherIndexAndRemove(t *testing.T) {
+	searcher := newTestSearcher(t)
+
+	// indexing a produk again replaces its terms
+	renamed := *testDocuments[0]
+	renamed.NamaProduk = "Hoodie Hitam"
+	renamed.Deskripsi = "hangat"
+	if err := searcher.Index(context.Background(), &renamed); err != nil {
+		t.Fatalf("Index error = %v", err)
+	}
+
+	res, err := searcher.Search(context.Background(), &Query{Keyword: "kaos"})
+	if err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+	if got := hitIds(res); !reflect.DeepEqual(got, []uint{2}) {
+		t.Errorf("hits for kaos = %v, want [2]", got)
+	}
+
+	res, err = searcher.Search(context.Background(), &Query{Keyword: "hoodie"})
+	if err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+	if got := hitIds(res); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("hits for hoodie = %v, want [1]", got)
+	}
+
+	if err := searcher.Remove(context.Background(), 1, 2); err != nil {
+		t.Fatalf("Remove error = %v", err)
+	}
+
+	res, err = searcher.Search(context.Background(), &Query{})
+	if err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+	if got := hitIds(res); !reflect.DeepEqual(got, []uint{5, 4, 3}) {
+		t.Errorf("hits after remove = %v, want [5 4 3]", got)
+	}
+	if _, ok := searcher.postings["hoodie"]; ok {
+		t.Errorf("postings of removed produk left in the index")
+	}
+}
@@ -0,0 +1,206 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+const vocabularyTtl = 5 * time.Minute
+
+// MySQLSearcher searches the produks through the fulltext indexes of the produk table
+type MySQLSearcher struct {
+	db *gorm.DB
+
+	mu           sync.Mutex
+	vocabulary   map[string]struct{}
+	vocabularyAt time.Time
+}
+
+// NewMySQLSearcher returns the product searcher backed by the mysql fulltext indexes
+func NewMySQLSearcher(db *gorm.DB) *MySQLSearcher {
+	return &MySQLSearcher{
+		db: db,
+	}
+}
+
+// Index only refreshes the vocabulary used for the typo correction since the produk table is indexed by mysql itself
+func (s *MySQLSearcher) Index(ctx context.Context, docs ...*Document) (err error) {
+	s.resetVocabulary()
+	return nil
+}
+
+// Remove only refreshes the vocabulary used for the typo correction since the produk table is indexed by mysql itself
+func (s *MySQLSearcher) Remove(ctx context.Context, idProduks ...uint) (err error) {
+	s.resetVocabulary()
+	return nil
+}
+
+// Search ranks the produks matching the query by their fulltext relevance and counts the facets of the matches
+func (s *MySQLSearcher) Search(ctx context.Context, query *Query) (res *Result, err error) {
+	terms := tokenize(query.Keyword)
+	if len(terms) > 0 {
+		vocabulary, err := s.getVocabulary(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, term := range terms {
+			terms[i] = correctTerm(term, vocabulary)
+		}
+	}
+
+	against := ""
+	if len(terms) > 0 {
+		against = strings.Join(terms, "* ") + "*"
+	}
+
+	// every facet is counted without its own filter so the other choices stay visible
+	filtered := func(byCategory, byToko, byHarga bool) *gorm.DB {
+		tx := s.db.WithContext(ctx).Model(&daos.Produk{})
+		if against != "" {
+			tx = tx.Where("MATCH(produks.nama_produk, produks.deskripsi) AGAINST (? IN BOOLEAN MODE)", against)
+		}
+
+		if byCategory && query.CategoryId > 0 {
+			tx = tx.Where("produks.id_category = ?", query.CategoryId)
+		}
+
+		if byToko && query.TokoId > 0 {
+			tx = tx.Where("produks.id_toko = ?", query.TokoId)
+		}
+
+		if byHarga && query.MinHarga > 0 {
+			tx = tx.Where("produks.harga_konsumen >= ?", query.MinHarga)
+		}
+
+		if byHarga && query.MaxHarga > 0 {
+			tx = tx.Where("produks.harga_konsumen <= ?", query.MaxHarga)
+		}
+
+		return tx
+	}
+
+	res = &Result{
+		Hits:         []*Hit{},
+		Keyword:      strings.Join(terms, " "),
+		Categories:   []*FacetCount{},
+		Tokos:        []*FacetCount{},
+		HargaBuckets: newHargaBuckets(),
+	}
+
+	var total int64
+	if err := filtered(true, true, true).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	res.Total = int(total)
+
+	tx := filtered(true, true, true)
+	if against != "" {
+		tx = tx.Select("produks.id AS id_produk, MATCH(produks.nama_produk) AGAINST (? IN BOOLEAN MODE) * ? + MATCH(produks.nama_produk, produks.deskripsi) AGAINST (? IN BOOLEAN MODE) AS score", against, namaWeight, against)
+	} else {
+		tx = tx.Select("produks.id AS id_produk, 0 AS score")
+	}
+
+	tx = tx.Order("score desc, produks.id desc").Offset(query.Offset)
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	if err := tx.Scan(&res.Hits).Error; err != nil {
+		return nil, err
+	}
+
+	err = filtered(false, true, true).
+		Select("produks.id_category AS id, categories.nama_category AS nama, COUNT(*) AS count").
+		Joins("LEFT JOIN categories ON categories.id = produks.id_category").
+		Group("produks.id_category, categories.nama_category").
+		Order("count desc, id asc").
+		Scan(&res.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = filtered(true, false, true).
+		Select("produks.id_toko AS id, tokos.nama_toko AS nama, COUNT(*) AS count").
+		Joins("LEFT JOIN tokos ON tokos.id = produks.id_toko").
+		Group("produks.id_toko, tokos.nama_toko").
+		Order("count desc, id asc").
+		Scan(&res.Tokos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bucketCase := "CASE"
+	for i := len(HargaBucketBounds) - 1; i > 0; i-- {
+		bucketCase += fmt.Sprintf(" WHEN produks.harga_konsumen >= %d THEN %d", HargaBucketBounds[i], i)
+	}
+	bucketCase += " ELSE 0 END"
+
+	bucketCounts := []struct {
+		Bucket int
+		Count  int
+	}{}
+	err = filtered(true, true, false).
+		Select(fmt.Sprintf("%s AS bucket, COUNT(*) AS count", bucketCase)).
+		Group("bucket").
+		Scan(&bucketCounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range bucketCounts {
+		res.HargaBuckets[v.Bucket].Count = v.Count
+	}
+
+	return res, nil
+}
+
+// getVocabulary returns the words of every nama and deskripsi produk, loading them again once they get stale
+func (s *MySQLSearcher) getVocabulary(ctx context.Context) (res map[string]struct{}, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.vocabulary != nil && time.Since(s.vocabularyAt) < vocabularyTtl {
+		return s.vocabulary, nil
+	}
+
+	rows, err := s.db.WithContext(ctx).Model(&daos.Produk{}).Select("nama_produk, deskripsi").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res = map[string]struct{}{}
+	for rows.Next() {
+		var namaProduk, deskripsi sql.NullString
+		if err := rows.Scan(&namaProduk, &deskripsi); err != nil {
+			return nil, err
+		}
+
+		for _, term := range append(tokenize(namaProduk.String), tokenize(deskripsi.String)...) {
+			res[term] = struct{}{}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.vocabulary, s.vocabularyAt = res, time.Now()
+	return res, nil
+}
+
+// resetVocabulary makes the next search load the vocabulary again
+func (s *MySQLSearcher) resetVocabulary() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vocabulary = nil
+}
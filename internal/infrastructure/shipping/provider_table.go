@@ -0,0 +1,98 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	ZonaDalamKota     = "dalam_kota"
+	ZonaDalamProvinsi = "dalam_provinsi"
+	ZonaDalamPulau    = "dalam_pulau"
+	ZonaAntarPulau    = "antar_pulau"
+	ZonaTimur         = "indonesia_timur"
+)
+
+const tableRateKurir = "LOCAL"
+
+type tableRate struct {
+	perKg int
+	etd   string
+}
+
+// tableRates lists the price per kg and the estimated delivery time of every service on every zona
+var tableRates = map[string]map[string]tableRate{
+	ServiceREG: {
+		ZonaDalamKota:     {perKg: 8000, etd: "1-2 hari"},
+		ZonaDalamProvinsi: {perKg: 11000, etd: "2-3 hari"},
+		ZonaDalamPulau:    {perKg: 17000, etd: "2-4 hari"},
+		ZonaAntarPulau:    {perKg: 28000, etd: "3-6 hari"},
+		ZonaTimur:         {perKg: 45000, etd: "5-9 hari"},
+	},
+	ServiceYES: {
+		ZonaDalamKota:     {perKg: 15000, etd: "1 hari"},
+		ZonaDalamProvinsi: {perKg: 20000, etd: "1 hari"},
+		ZonaDalamPulau:    {perKg: 30000, etd: "1 hari"},
+		ZonaAntarPulau:    {perKg: 52000, etd: "1-2 hari"},
+	},
+}
+
+var serviceDeskripsis = map[string]string{
+	ServiceREG: "Layanan Reguler",
+	ServiceYES: "Yakin Esok Sampai",
+}
+
+type TableRateProvider struct{}
+
+// NewTableRateProvider returns the provider pricing the shipment with the local zona table
+func NewTableRateProvider() ShippingRateProvider {
+	return &TableRateProvider{}
+}
+
+// GetRates returns the rate of every service delivering the berat from the asal kota to the tujuan kota
+func (p *TableRateProvider) GetRates(ctx context.Context, req *RateReq) (res []*Rate, err error) {
+	if len(req.Asal) < 2 || len(req.Tujuan) < 2 {
+		return nil, errors.New("kota asal dan tujuan pengiriman wajib diisi")
+	}
+
+	zona := getZona(req.Asal, req.Tujuan)
+	kg := (req.Berat + 999) / 1000
+	if kg < 1 {
+		kg = 1
+	}
+
+	for _, layanan := range []string{ServiceREG, ServiceYES} {
+		rate, ok := tableRates[layanan][zona]
+		if !ok {
+			continue
+		}
+
+		res = append(res, &Rate{
+			Kurir:     tableRateKurir,
+			Layanan:   layanan,
+			Deskripsi: serviceDeskripsis[layanan],
+			Zona:      zona,
+			Ongkir:    rate.perKg * kg,
+			Etd:       rate.etd,
+		})
+	}
+
+	return res, nil
+}
+
+// getZona returns the zona between the two kota based on their id, whose first two digits are the provinsi
+// and whose first digit is the island group (1 sumatera, 3 jawa, 5 bali nusa tenggara, 6 kalimantan, 7 sulawesi, 8 maluku, 9 papua)
+func getZona(asal, tujuan string) string {
+	switch {
+	case asal == tujuan:
+		return ZonaDalamKota
+	case asal[:2] == tujuan[:2]:
+		return ZonaDalamProvinsi
+	case asal[0] == tujuan[0]:
+		return ZonaDalamPulau
+	case asal[0] >= '8' || tujuan[0] >= '8':
+		return ZonaTimur
+	default:
+		return ZonaAntarPulau
+	}
+}
@@ -0,0 +1,46 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	ServiceREG = "REG"
+	ServiceYES = "YES"
+)
+
+type RateReq struct {
+	Asal   string // id kota of the sender
+	Tujuan string // id kota of the receiver
+	Berat  int    // gram
+}
+
+type Rate struct {
+	Kurir     string
+	Layanan   string
+	Deskripsi string
+	Zona      string
+	Ongkir    int
+	Etd       string
+}
+
+type ShippingRateProvider interface {
+	GetRates(ctx context.Context, req *RateReq) (res []*Rate, err error)
+}
+
+// ProviderInit initializes the shipping rate provider used by the app
+func ProviderInit() ShippingRateProvider {
+	return NewTableRateProvider()
+}
+
+// FindRate returns the rate of the layanan among the rates
+func FindRate(rates []*Rate, layanan string) (*Rate, error) {
+	for _, v := range rates {
+		if v.Layanan == layanan {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("layanan pengiriman %s tidak tersedia", layanan)
+}
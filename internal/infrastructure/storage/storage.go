@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ProviderLocal = "local"
+	ProviderS3    = "s3"
+)
+
+var ErrNotFound = errors.New("blob tidak ditemukan")
+
+type StorageConf struct {
+	Provider string `mapstructure:"storage_provider"`
+
+	LocalDir     string `mapstructure:"storage_localDir"`
+	LocalBaseUrl string `mapstructure:"storage_localBaseUrl"`
+
+	S3Endpoint  string `mapstructure:"storage_s3Endpoint"`
+	S3Region    string `mapstructure:"storage_s3Region"`
+	S3Bucket    string `mapstructure:"storage_s3Bucket"`
+	S3AccessKey string `mapstructure:"storage_s3AccessKey"`
+	S3SecretKey string `mapstructure:"storage_s3SecretKey"`
+	S3PathStyle bool   `mapstructure:"storage_s3PathStyle"`
+	S3BaseUrl   string `mapstructure:"storage_s3BaseUrl"`
+}
+
+// BlobStorage stores the uploaded files under keys such as images/produk/1700000000_thumb.jpg, serving each of them on a public url
+type BlobStorage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (url string, err error)
+	Delete(ctx context.Context, key string) (err error)
+	URL(key string) string
+	// Key returns the key of the blob served on the url, ok is false when the url is not served by the storage
+	Key(url string) (key string, ok bool)
+}
+
+// ProviderInit initializes the blob storage chosen by the configuration, the local filesystem being the default
+func ProviderInit(v *viper.Viper) (BlobStorage, error) {
+	conf := StorageConf{}
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	switch conf.Provider {
+	case "", ProviderLocal:
+		if conf.LocalDir == "" {
+			conf.LocalDir = "./static"
+		}
+		if conf.LocalBaseUrl == "" {
+			conf.LocalBaseUrl = "/static"
+		}
+
+		return NewLocalStorage(conf.LocalDir, conf.LocalBaseUrl), nil
+	case ProviderS3:
+		return NewS3Storage(&conf)
+	default:
+		return nil, fmt.Errorf("storage provider %s is not supported", conf.Provider)
+	}
+}
+
+// keyFromUrl returns the key of the url starting with the base url
+func keyFromUrl(baseUrl, url string) (key string, ok bool) {
+	prefix := strings.TrimSuffix(baseUrl, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+
+	key = strings.TrimPrefix(url, prefix)
+	return key, key != ""
+}
+
+// validKey reports whether the key is relative and stays inside the storage
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores the blobs as files of a directory of the filesystem, served by the app itself
+type LocalStorage struct {
+	dir     string
+	baseUrl string
+}
+
+// NewLocalStorage returns the blob storage writing to the directory whose files are served on the base url, e.g. ./static on /static
+func NewLocalStorage(dir, baseUrl string) *LocalStorage {
+	return &LocalStorage{
+		dir:     dir,
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+	}
+}
+
+// Put writes the data to the file of the key, creating its directory when missing
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) (url string, err error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("key %q tidak valid", key)
+	}
+
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+// Delete removes the file of the key
+func (s *LocalStorage) Delete(ctx context.Context, key string) (err error) {
+	if !validKey(key) {
+		return fmt.Errorf("key %q tidak valid", key)
+	}
+
+	err = os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// URL returns the url the file of the key is served on
+func (s *LocalStorage) URL(key string) string {
+	return s.baseUrl + "/" + key
+}
+
+// Key returns the key of the file served on the url
+func (s *LocalStorage) Key(url string) (key string, ok bool) {
+	return keyFromUrl(s.baseUrl, url)
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"a.jpg", true},
+		{"images/produk/1700000000_thumb.jpg", true},
+		{"images/..jpg", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../a.jpg", false},
+		{"images/../../a.jpg", false},
+		{"images/..", false},
+		{"images/./a.jpg", false},
+		{"images//a.jpg", false},
+		{"images/", false},
+		{"..\\a.jpg", false},
+		{"images\\a.jpg", false},
+	}
+
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "static")
+	s := NewLocalStorage(dir, "/static/")
+	ctx := context.Background()
+
+	url, err := s.Put(ctx, "images/produk/a.jpg", []byte("jpeg data"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Put error = %v", err)
+	}
+	if url != "/static/images/produk/a.jpg" {
+		t.Errorf("Put url = %s, want /static/images/produk/a.jpg", url)
+	}
+	if got, ok := s.Key(url); !ok || got != "images/produk/a.jpg" {
+		t.Errorf("Key(%s) = %s, %v, want images/produk/a.jpg", url, got, ok)
+	}
+	if _, ok := s.Key("/other/a.jpg"); ok {
+		t.Errorf("Key of a url not served by the storage is ok")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "images", "produk", "a.jpg"))
+	if err != nil || string(data) != "jpeg data" {
+		t.Errorf("stored file = %q, %v, want %q", data, err, "jpeg data")
+	}
+
+	// the keys escaping the directory are refused before touching the filesystem
+	for _, key := range []string{"../escape.jpg", "images/../../escape.jpg", "/escape.jpg"} {
+		if _, err := s.Put(ctx, key, []byte("jpeg data"), "image/jpeg"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.jpg")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file written outside the storage directory")
+	}
+
+	outside := filepath.Join(root, "outside.jpg")
+	if err := os.WriteFile(outside, []byte("jpeg data"), 0644); err != nil {
+		t.Fatalf("write file: %s", err)
+	}
+	if err := s.Delete(ctx, "../outside.jpg"); err == nil {
+		t.Errorf("Delete of a key outside the directory succeeded")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the storage directory deleted: %v", err)
+	}
+
+	if err := s.Delete(ctx, "images/produk/a.jpg"); err != nil {
+		t.Fatalf("Delete error = %v", err)
+	}
+	if err := s.Delete(ctx, "images/produk/a.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of a missing file error = %v, want ErrNotFound", err)
+	}
+}
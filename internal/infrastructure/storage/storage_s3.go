@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const s3Service = "s3"
+
+// S3Storage stores the blobs as objects of a bucket of an s3 compatible service, such as aws s3 or minio,
+// signing its requests with aws signature version 4. The objects are expected to be publicly readable,
+// either through the bucket policy or a cdn configured as the base url
+type S3Storage struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	baseUrl   string
+}
+
+// NewS3Storage returns the blob storage writing to the bucket of the configuration
+func NewS3Storage(conf *StorageConf) (*S3Storage, error) {
+	if conf.S3Endpoint == "" || conf.S3Bucket == "" || conf.S3AccessKey == "" || conf.S3SecretKey == "" {
+		return nil, errors.New("storage_s3Endpoint, storage_s3Bucket, storage_s3AccessKey and storage_s3SecretKey are required")
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(conf.S3Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage_s3Endpoint %q must be an absolute url", conf.S3Endpoint)
+	}
+
+	s := &S3Storage{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		endpoint:  endpoint,
+		region:    conf.S3Region,
+		bucket:    conf.S3Bucket,
+		accessKey: conf.S3AccessKey,
+		secretKey: conf.S3SecretKey,
+		pathStyle: conf.S3PathStyle,
+		baseUrl:   strings.TrimSuffix(conf.S3BaseUrl, "/"),
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+	if s.baseUrl == "" {
+		s.baseUrl = strings.TrimSuffix(s.bucketUrl().String(), "/")
+	}
+
+	return s, nil
+}
+
+// Put uploads the data as the object of the key
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) (url string, err error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("key %q tidak valid", key)
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if err := s.do(ctx, http.MethodPut, key, data, header); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+// Delete deletes the object of the key
+func (s *S3Storage) Delete(ctx context.Context, key string) (err error) {
+	if !validKey(key) {
+		return fmt.Errorf("key %q tidak valid", key)
+	}
+
+	return s.do(ctx, http.MethodDelete, key, nil, http.Header{})
+}
+
+// URL returns the public url of the object of the key
+func (s *S3Storage) URL(key string) string {
+	return s.baseUrl + "/" + key
+}
+
+// Key returns the key of the object served on the url
+func (s *S3Storage) Key(url string) (key string, ok bool) {
+	return keyFromUrl(s.baseUrl, url)
+}
+
+// bucketUrl returns the url of the bucket, either as a path of the endpoint or as its subdomain
+func (s *S3Storage) bucketUrl() *url.URL {
+	res := *s.endpoint
+	if s.pathStyle {
+		res.Path = strings.TrimSuffix(res.Path, "/") + "/" + s.bucket
+	} else {
+		res.Host = s.bucket + "." + res.Host
+	}
+
+	return &res
+}
+
+// do sends the signed request of the object of the key
+func (s *S3Storage) do(ctx context.Context, method, key string, body []byte, header http.Header) error {
+	objectUrl := s.bucketUrl()
+	objectUrl.Path = strings.TrimSuffix(objectUrl.Path, "/") + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, method, objectUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("s3 %s %s: status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// sign adds the aws signature version 4 authorization header to the request,
+// see https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		"", // no query string
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, s.region, s3Service)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath uri encodes each segment of the path, keeping the slashes and the unreserved characters only
+func s3EscapePath(path string) string {
+	res := strings.Builder{}
+	for _, c := range []byte(path) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~', c == '/':
+			res.WriteByte(c)
+		default:
+			fmt.Fprintf(&res, "%%%02X", c)
+		}
+	}
+
+	return res.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
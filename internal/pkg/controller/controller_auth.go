@@ -4,6 +4,7 @@ import (
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/pkg/dto"
 	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,6 +12,8 @@ import (
 type AuthController interface {
 	RegisterUsers(ctx *fiber.Ctx) error
 	LoginUsers(ctx *fiber.Ctx) error
+	RefreshToken(ctx *fiber.Ctx) error
+	LogoutUsers(ctx *fiber.Ctx) error
 }
 
 type AuthControllerImpl struct {
@@ -81,3 +84,52 @@ func (uc *AuthControllerImpl) LoginUsers(ctx *fiber.Ctx) error {
 		Data:       loginResp,
 	})
 }
+
+// RefreshToken handles the delivery logic to refresh the access token of the user
+func (uc *AuthControllerImpl) RefreshToken(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := new(dto.AuthReqRefresh)
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.authusecase.RefreshToken(c, *data)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// LogoutUsers handles the delivery logic to logout the user
+func (uc *AuthControllerImpl) LogoutUsers(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.authusecase.LogoutUser(c, utils.GetPrincipal(ctx))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Logout succeed",
+	})
+}
@@ -44,6 +44,8 @@ func (uc *BookControllerImpl) GetAllBook(ctx *fiber.Ctx) error {
 		Title: filter.Title,
 		Limit: filter.Limit,
 		Page:  filter.Page,
+		Sort:  filter.Sort,
+		Order: filter.Order,
 	})
 
 	if customErr != nil {
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartController interface {
+	GetMyCart(ctx *fiber.Ctx) error
+	AddCartItem(ctx *fiber.Ctx) error
+	UpdateCartItem(ctx *fiber.Ctx) error
+	DeleteCartItem(ctx *fiber.Ctx) error
+	CheckoutCart(ctx *fiber.Ctx) error
+}
+
+type CartControllerImpl struct {
+	cartusecase usecase.CartUseCase
+}
+
+// NewCartController returns the controller for the cart group path
+func NewCartController(cartusecase usecase.CartUseCase) CartController {
+	return &CartControllerImpl{
+		cartusecase: cartusecase,
+	}
+}
+
+// GetMyCart handles the delivery logic to retrieve the cart of the current user
+func (uc *CartControllerImpl) GetMyCart(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.cartusecase.GetMyCart(c, utils.GetPrincipal(ctx))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// AddCartItem handles the delivery logic to put a produk into the cart of the current user
+func (uc *CartControllerImpl) AddCartItem(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.CartItemCreateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.cartusecase.AddCartItem(c, utils.GetPrincipal(ctx), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusCreated,
+		Data:       res,
+	})
+}
+
+// UpdateCartItem handles the delivery logic to change the kuantitas of the cartitem having the id
+func (uc *CartControllerImpl) UpdateCartItem(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.CartItemUpdateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.cartusecase.UpdateCartItem(c, utils.GetPrincipal(ctx), ctx.Params("id"), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Update cart item succeed",
+	})
+}
+
+// DeleteCartItem handles the delivery logic to remove the cartitem having the id from the cart of the current user
+func (uc *CartControllerImpl) DeleteCartItem(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.cartusecase.DeleteCartItem(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Delete cart item succeed",
+	})
+}
+
+// CheckoutCart handles the delivery logic to turn the cart of the current user into a trx
+func (uc *CartControllerImpl) CheckoutCart(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.CartCheckoutReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.cartusecase.CheckoutCart(c, utils.GetPrincipal(ctx), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusCreated,
+		Data:       res,
+	})
+}
@@ -32,7 +32,16 @@ func NewCategoryController(categoryusecase usecase.CategoryUseCase) CategoryCont
 func (uc *CategoryControllerImpl) GetAllCategories(ctx *fiber.Ctx) error {
 	c := ctx.Context()
 
-	res, customErr := uc.categoryusecase.GetAllCategories(c)
+	filter := new(dto.CategoryFilter)
+	if err := ctx.QueryParser(filter); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.categoryusecase.GetAllCategories(c, filter)
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OtpController interface {
+	RequestNotelpVerification(ctx *fiber.Ctx) error
+	VerifyNotelp(ctx *fiber.Ctx) error
+	RequestEmailVerification(ctx *fiber.Ctx) error
+	VerifyEmail(ctx *fiber.Ctx) error
+	ForgotPassword(ctx *fiber.Ctx) error
+	ResetPassword(ctx *fiber.Ctx) error
+}
+
+type OtpControllerImpl struct {
+	otpusecase usecase.OtpUseCase
+}
+
+// NewOtpController returns the controller for the verification and the password reset paths of the auth group path
+func NewOtpController(otpusecase usecase.OtpUseCase) OtpController {
+	return &OtpControllerImpl{
+		otpusecase: otpusecase,
+	}
+}
+
+// RequestNotelpVerification handles the delivery logic to send the otp verifying the notelp of the user
+func (uc *OtpControllerImpl) RequestNotelpVerification(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.otpusecase.RequestNotelpVerification(c, utils.GetPrincipal(ctx))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// VerifyNotelp handles the delivery logic to verify the notelp of the user
+func (uc *OtpControllerImpl) VerifyNotelp(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := new(dto.AuthReqVerifyOtp)
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.otpusecase.VerifyNotelp(c, utils.GetPrincipal(ctx), *data)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Verify notelp succeed",
+	})
+}
+
+// RequestEmailVerification handles the delivery logic to send the otp verifying the email of the user
+func (uc *OtpControllerImpl) RequestEmailVerification(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.otpusecase.RequestEmailVerification(c, utils.GetPrincipal(ctx))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// VerifyEmail handles the delivery logic to verify the email of the user
+func (uc *OtpControllerImpl) VerifyEmail(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := new(dto.AuthReqVerifyOtp)
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.otpusecase.VerifyEmail(c, utils.GetPrincipal(ctx), *data)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Verify email succeed",
+	})
+}
+
+// ForgotPassword handles the delivery logic to send the otp resetting the kata sandi
+func (uc *OtpControllerImpl) ForgotPassword(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := new(dto.AuthReqForgotPassword)
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.otpusecase.ForgotPassword(c, *data)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "OTP has been sent if the account exists",
+	})
+}
+
+// ResetPassword handles the delivery logic to reset the kata sandi of the user
+func (uc *OtpControllerImpl) ResetPassword(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := new(dto.AuthReqResetPassword)
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.otpusecase.ResetPassword(c, *data)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Reset password succeed",
+	})
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentController interface {
+	HandleWebhook(ctx *fiber.Ctx) error
+}
+
+type PaymentControllerImpl struct {
+	paymentusecase usecase.PaymentUseCase
+}
+
+// NewPaymentController returns the controller for the payment group path
+func NewPaymentController(paymentusecase usecase.PaymentUseCase) PaymentController {
+	return &PaymentControllerImpl{
+		paymentusecase: paymentusecase,
+	}
+}
+
+// HandleWebhook handles the delivery logic to settle a payment based on the webhook sent by the provider
+func (uc *PaymentControllerImpl) HandleWebhook(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.paymentusecase.HandleWebhook(c, ctx.Params("provider"), ctx.Get("X-Signature"), ctx.Body())
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
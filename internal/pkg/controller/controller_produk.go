@@ -11,10 +11,17 @@ import (
 
 type ProdukController interface {
 	GetAllProduks(ctx *fiber.Ctx) error
+	SearchProduks(ctx *fiber.Ctx) error
 	GetProdukById(ctx *fiber.Ctx) error
+	GetProdukBySlug(ctx *fiber.Ctx) error
 	CreateProduk(ctx *fiber.Ctx) error
 	UpdateProdukById(ctx *fiber.Ctx) error
 	DeleteProdukById(ctx *fiber.Ctx) error
+	GetProdukVariants(ctx *fiber.Ctx) error
+	GetProdukVariantById(ctx *fiber.Ctx) error
+	CreateProdukVariant(ctx *fiber.Ctx) error
+	UpdateProdukVariantById(ctx *fiber.Ctx) error
+	DeleteProdukVariantById(ctx *fiber.Ctx) error
 }
 
 type ProdukControllerImpl struct {
@@ -58,6 +65,35 @@ func (uc *ProdukControllerImpl) GetAllProduks(ctx *fiber.Ctx) error {
 	})
 }
 
+// SearchProduks handles the delivery logic to retrieve the produk data matching the keyword along with the facets of the matches
+func (uc *ProdukControllerImpl) SearchProduks(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	filter := new(dto.ProdukSearchFilter)
+	if err := ctx.QueryParser(filter); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.produkusecase.SearchProduks(c, filter)
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
 // GetProdukById handles the delivery logic to retrieve produk data having the id
 func (uc *ProdukControllerImpl) GetProdukById(ctx *fiber.Ctx) error {
 	c := ctx.Context()
@@ -78,6 +114,31 @@ func (uc *ProdukControllerImpl) GetProdukById(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetProdukBySlug handles the delivery logic to retrieve produk data having the slug, redirecting a previous slug to the current one
+func (uc *ProdukControllerImpl) GetProdukBySlug(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, redirectSlug, err := uc.produkusecase.GetProdukBySlug(c, ctx.Params("slug"))
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: err.Code,
+			Errors:     []string{err.Err.Error()},
+		})
+	}
+
+	if redirectSlug != "" {
+		// the location is relative to the requested path so only the last segment, the slug, is replaced
+		return ctx.Redirect(redirectSlug, fiber.StatusMovedPermanently)
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
 // CreateProduk handles the delivery logic to insert the produk data
 func (uc *ProdukControllerImpl) CreateProduk(ctx *fiber.Ctx) error {
 	c := ctx.Context()
@@ -100,7 +161,7 @@ func (uc *ProdukControllerImpl) CreateProduk(ctx *fiber.Ctx) error {
 		})
 	}
 
-	res, customErr := uc.produkusecase.CreateProduk(c, data, ctx.Get("token"), form.File["photos"])
+	res, customErr := uc.produkusecase.CreateProduk(c, data, utils.GetPrincipal(ctx), form.File["photos"])
 	if customErr != nil {
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
 			Ctx:        ctx,
@@ -173,3 +234,139 @@ func (uc *ProdukControllerImpl) DeleteProdukById(ctx *fiber.Ctx) error {
 		Data:       "Delete Succeed",
 	})
 }
+
+// GetProdukVariants handles the delivery logic to retrieve all variant data of the produk
+func (uc *ProdukControllerImpl) GetProdukVariants(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.produkusecase.GetProdukVariants(c, ctx.Params("id"))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetProdukVariantById handles the delivery logic to retrieve the variant data having the id of the produk
+func (uc *ProdukControllerImpl) GetProdukVariantById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.produkusecase.GetProdukVariantById(c, ctx.Params("id"), ctx.Params("id_variant"))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// CreateProdukVariant handles the delivery logic to insert the variant data of the produk
+func (uc *ProdukControllerImpl) CreateProdukVariant(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.ProdukVariantCreateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadGateway,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.produkusecase.CreateProdukVariant(c, ctx.Params("id"), data, form.File["photos"])
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusCreated,
+		Data:       res,
+	})
+}
+
+// UpdateProdukVariantById handles the delivery logic to update the variant data having the id of the produk
+func (uc *ProdukControllerImpl) UpdateProdukVariantById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.ProdukVariantUpdateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadGateway,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.produkusecase.UpdateProdukVariantById(c, ctx.Params("id"), ctx.Params("id_variant"), data, form.File["photos"])
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Update Succeed",
+	})
+}
+
+// DeleteProdukVariantById handles the delivery logic to delete the variant data having the id of the produk
+func (uc *ProdukControllerImpl) DeleteProdukVariantById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.produkusecase.DeleteProdukVariantById(c, ctx.Params("id"), ctx.Params("id_variant"))
+	if customErr != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Delete Succeed",
+	})
+}
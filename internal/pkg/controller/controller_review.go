@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewController interface {
+	GetProdukReviews(ctx *fiber.Ctx) error
+	GetReviewById(ctx *fiber.Ctx) error
+	CreateReview(ctx *fiber.Ctx) error
+	ReplyReview(ctx *fiber.Ctx) error
+}
+
+type ReviewControllerImpl struct {
+	reviewusecase usecase.ReviewUseCase
+}
+
+// NewReviewController returns the controller for the review group path
+func NewReviewController(reviewusecase usecase.ReviewUseCase) ReviewController {
+	return &ReviewControllerImpl{
+		reviewusecase: reviewusecase,
+	}
+}
+
+// GetProdukReviews handles the delivery logic to retrieve the review data of the produk having the id
+func (uc *ReviewControllerImpl) GetProdukReviews(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	filter := &dto.ReviewFilter{}
+	if err := ctx.QueryParser(filter); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.reviewusecase.GetProdukReviews(c, ctx.Params("id"), filter)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetReviewById handles the delivery logic to retrieve review data having the id
+func (uc *ReviewControllerImpl) GetReviewById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.reviewusecase.GetReviewById(c, ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// CreateReview handles the delivery logic to review the produk bought on a detailtrx of the current user
+func (uc *ReviewControllerImpl) CreateReview(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.ReviewCreateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.reviewusecase.CreateReview(c, utils.GetPrincipal(ctx), data, form.File["photos"])
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusCreated,
+		Data:       res,
+	})
+}
+
+// ReplyReview handles the delivery logic to reply to the review having the id on behalf of the toko of the current user
+func (uc *ReviewControllerImpl) ReplyReview(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.ReviewReplyReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.reviewusecase.ReplyReview(c, utils.GetPrincipal(ctx), ctx.Params("id"), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Update succeed",
+	})
+}
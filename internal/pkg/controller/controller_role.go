@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleController interface {
+	GetAllRoles(ctx *fiber.Ctx) error
+	GetUserRoles(ctx *fiber.Ctx) error
+	GrantUserRole(ctx *fiber.Ctx) error
+	RevokeUserRole(ctx *fiber.Ctx) error
+}
+
+type RoleControllerImpl struct {
+	roleusecase usecase.RoleUseCase
+}
+
+// NewRoleController returns the controller for the role group path
+func NewRoleController(roleusecase usecase.RoleUseCase) RoleController {
+	return &RoleControllerImpl{
+		roleusecase: roleusecase,
+	}
+}
+
+// GetAllRoles handles the delivery logic to retrieve the roles along with their permissions
+func (uc *RoleControllerImpl) GetAllRoles(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.roleusecase.GetAllRoles(c)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetUserRoles handles the delivery logic to retrieve the roles of the user having the id
+func (uc *RoleControllerImpl) GetUserRoles(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.roleusecase.GetUserRoles(c, ctx.Params("id_user"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GrantUserRole handles the delivery logic to grant the role to the user having the id
+func (uc *RoleControllerImpl) GrantUserRole(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.UserRoleReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.roleusecase.GrantUserRole(c, ctx.Params("id_user"), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// RevokeUserRole handles the delivery logic to revoke the role of the user having the id
+func (uc *RoleControllerImpl) RevokeUserRole(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.roleusecase.RevokeUserRole(c, ctx.Params("id_user"), ctx.Params("role"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
@@ -12,8 +12,12 @@ import (
 type TokoController interface {
 	GetAllToko(ctx *fiber.Ctx) error
 	GetTokoById(ctx *fiber.Ctx) error
+	GetTokoBySlug(ctx *fiber.Ctx) error
 	GetMyToko(ctx *fiber.Ctx) error
+	GetMyOrders(ctx *fiber.Ctx) error
 	UpdateTokoByID(ctx *fiber.Ctx) error
+	FollowToko(ctx *fiber.Ctx) error
+	UnfollowToko(ctx *fiber.Ctx) error
 }
 
 type TokoControllerImpl struct {
@@ -61,7 +65,7 @@ func (uc *TokoControllerImpl) GetAllToko(ctx *fiber.Ctx) error {
 func (uc *TokoControllerImpl) GetTokoById(ctx *fiber.Ctx) error {
 	c := ctx.Context()
 
-	toko, err := uc.tokousecase.GetTokoById(c, ctx.Params("id_toko"), ctx.Get("token"))
+	toko, err := uc.tokousecase.GetTokoById(c, ctx.Params("id_toko"))
 	if err != nil {
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
 			Ctx:        ctx,
@@ -77,11 +81,36 @@ func (uc *TokoControllerImpl) GetTokoById(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetTokoBySlug handles the delivery logic to retrieve toko data having the slug, redirecting a previous slug to the current one
+func (uc *TokoControllerImpl) GetTokoBySlug(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, redirectSlug, err := uc.tokousecase.GetTokoBySlug(c, ctx.Params("slug"))
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: err.Code,
+			Errors:     []string{err.Err.Error()},
+		})
+	}
+
+	if redirectSlug != "" {
+		// the location is relative to the requested path so only the last segment, the slug, is replaced
+		return ctx.Redirect(redirectSlug, fiber.StatusMovedPermanently)
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
 // GetMyToko handles the delivery logic to retrieve toko data of the current user
 func (uc *TokoControllerImpl) GetMyToko(ctx *fiber.Ctx) error {
 	c := ctx.Context()
 
-	toko, err := uc.tokousecase.GetMyToko(c, ctx.Get("token"))
+	toko, err := uc.tokousecase.GetMyToko(c, utils.GetPrincipal(ctx))
 	if err != nil {
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
 			Ctx:        ctx,
@@ -97,6 +126,35 @@ func (uc *TokoControllerImpl) GetMyToko(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetMyOrders handles the delivery logic to retrieve the orders received by the toko of the current user
+func (uc *TokoControllerImpl) GetMyOrders(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	filter := new(dto.TokoOrderFilter)
+	if err := ctx.QueryParser(filter); err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, err := uc.tokousecase.GetMyOrders(c, utils.GetPrincipal(ctx), filter)
+	if err != nil {
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: err.Code,
+			Errors:     []string{err.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
 // UpdateTokoByID handles the delivery logic to update toko data having the id
 func (uc *TokoControllerImpl) UpdateTokoByID(ctx *fiber.Ctx) error {
 	c := ctx.Context()
@@ -128,3 +186,45 @@ func (uc *TokoControllerImpl) UpdateTokoByID(ctx *fiber.Ctx) error {
 		Data:       "Update toko succeed",
 	})
 }
+
+// FollowToko handles the delivery logic to add the toko having the id to the favourite toko of the current user
+func (uc *TokoControllerImpl) FollowToko(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.tokousecase.FollowToko(c, utils.GetPrincipal(ctx), ctx.Params("id_toko"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// UnfollowToko handles the delivery logic to remove the toko having the id from the favourite toko of the current user
+func (uc *TokoControllerImpl) UnfollowToko(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.tokousecase.UnfollowToko(c, utils.GetPrincipal(ctx), ctx.Params("id_toko"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Delete succeed",
+	})
+}
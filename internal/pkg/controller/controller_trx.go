@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"fmt"
+	"strings"
+	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/pkg/dto"
 	"tugas_akhir_example/internal/pkg/usecase"
@@ -12,7 +15,15 @@ import (
 type TrxController interface {
 	GetAllTrxs(ctx *fiber.Ctx) error
 	GetTrxById(ctx *fiber.Ctx) error
+	GetTrxByKodeInvoice(ctx *fiber.Ctx) error
 	CreateTrx(ctx *fiber.Ctx) error
+	QuoteShipping(ctx *fiber.Ctx) error
+	GetTrxStatusHistories(ctx *fiber.Ctx) error
+	UpdateTrxStatusByBuyer(ctx *fiber.Ctx) error
+	UpdateTrxStatusBySeller(ctx *fiber.Ctx) error
+	UpdateTrxStatusByAdmin(ctx *fiber.Ctx) error
+	GetTrxInvoicePDF(ctx *fiber.Ctx) error
+	GetTrxPackingSlipPDF(ctx *fiber.Ctx) error
 }
 
 type TrxControllerImpl struct {
@@ -41,7 +52,7 @@ func (uc *TrxControllerImpl) GetAllTrxs(ctx *fiber.Ctx) error {
 		})
 	}
 
-	res, customErr := uc.trxusecase.GetAllTrxs(c, filter)
+	res, customErr := uc.trxusecase.GetAllTrxs(c, utils.GetPrincipal(ctx), filter)
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -62,7 +73,28 @@ func (uc *TrxControllerImpl) GetAllTrxs(ctx *fiber.Ctx) error {
 func (uc *TrxControllerImpl) GetTrxById(ctx *fiber.Ctx) error {
 	c := ctx.Context()
 
-	res, customErr := uc.trxusecase.GetTrxById(c, ctx.Params("id"))
+	res, customErr := uc.trxusecase.GetTrxById(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetTrxByKodeInvoice handles the delivery logic to retrieve trx data having the kodeinvoice
+func (uc *TrxControllerImpl) GetTrxByKodeInvoice(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.trxusecase.GetTrxByKodeInvoice(c, utils.GetPrincipal(ctx), ctx.Query("kode"))
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -94,7 +126,7 @@ func (uc *TrxControllerImpl) CreateTrx(ctx *fiber.Ctx) error {
 		})
 	}
 
-	res, customErr := uc.trxusecase.CreateTrx(c, ctx.Get("token"), data)
+	res, customErr := uc.trxusecase.CreateTrx(c, utils.GetPrincipal(ctx), data)
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -110,3 +142,143 @@ func (uc *TrxControllerImpl) CreateTrx(ctx *fiber.Ctx) error {
 		Data:       res,
 	})
 }
+
+// QuoteShipping handles the delivery logic to retrieve the shipping rates of the ordered produks
+func (uc *TrxControllerImpl) QuoteShipping(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.ShippingQuoteReq{}
+	err := ctx.BodyParser(data)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.trxusecase.QuoteShipping(c, utils.GetPrincipal(ctx), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetTrxStatusHistories handles the delivery logic to retrieve the status history of the trx having the id
+func (uc *TrxControllerImpl) GetTrxStatusHistories(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.trxusecase.GetTrxStatusHistories(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// UpdateTrxStatusByBuyer handles the delivery logic to update the status of the trx having the id as its buyer
+func (uc *TrxControllerImpl) UpdateTrxStatusByBuyer(ctx *fiber.Ctx) error {
+	return uc.updateTrxStatus(ctx, daos.TrxActorBuyer)
+}
+
+// UpdateTrxStatusBySeller handles the delivery logic to update the status of the trx having the id as its seller
+func (uc *TrxControllerImpl) UpdateTrxStatusBySeller(ctx *fiber.Ctx) error {
+	return uc.updateTrxStatus(ctx, daos.TrxActorSeller)
+}
+
+// UpdateTrxStatusByAdmin handles the delivery logic to update the status of the trx having the id as an admin
+func (uc *TrxControllerImpl) UpdateTrxStatusByAdmin(ctx *fiber.Ctx) error {
+	return uc.updateTrxStatus(ctx, daos.TrxActorAdmin)
+}
+
+// updateTrxStatus handles the delivery logic to update the status of the trx having the id on behalf of the peran
+func (uc *TrxControllerImpl) updateTrxStatus(ctx *fiber.Ctx, peran string) error {
+	c := ctx.Context()
+
+	data := &dto.TrxStatusUpdateReq{}
+	err := ctx.BodyParser(data)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.trxusecase.UpdateTrxStatus(c, utils.GetPrincipal(ctx), ctx.Params("id"), peran, data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Update trx status succeed",
+	})
+}
+
+// GetTrxInvoicePDF handles the delivery logic to download the invoice of the trx having the id
+func (uc *TrxControllerImpl) GetTrxInvoicePDF(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, kodeInvoice, customErr := uc.trxusecase.GetTrxInvoicePDF(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return sendPDF(ctx, fmt.Sprintf("%s.pdf", kodeInvoice), res)
+}
+
+// GetTrxPackingSlipPDF handles the delivery logic to download the packing slip of the trx having the id
+func (uc *TrxControllerImpl) GetTrxPackingSlipPDF(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, kodeInvoice, customErr := uc.trxusecase.GetTrxPackingSlipPDF(c, utils.GetPrincipal(ctx), ctx.Params("id"), ctx.Query("id_toko"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return sendPDF(ctx, fmt.Sprintf("%s-packing-slip.pdf", kodeInvoice), res)
+}
+
+// sendPDF writes the pdf document as a downloadable attachment named after the filename
+func sendPDF(ctx *fiber.Ctx, filename string, data []byte) error {
+	ctx.Attachment(strings.ReplaceAll(filename, "/", "-"))
+	return ctx.Status(fiber.StatusOK).Send(data)
+}
@@ -17,6 +17,9 @@ type UserController interface {
 	UpdateAlamatById(ctx *fiber.Ctx) error
 	UpdateProfile(ctx *fiber.Ctx) error
 	DeleteAlamatById(ctx *fiber.Ctx) error
+	GetMyWishlists(ctx *fiber.Ctx) error
+	AddWishlist(ctx *fiber.Ctx) error
+	RemoveWishlist(ctx *fiber.Ctx) error
 }
 
 type UserControllerImpl struct {
@@ -45,7 +48,7 @@ func (uc *UserControllerImpl) GetMyAlamats(ctx *fiber.Ctx) error {
 		})
 	}
 
-	res, customErr := uc.userusecase.GetMyAlamats(c, ctx.Get("token"), filter)
+	res, customErr := uc.userusecase.GetMyAlamats(c, utils.GetPrincipal(ctx), filter)
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -87,7 +90,7 @@ func (uc *UserControllerImpl) GetAlamatById(ctx *fiber.Ctx) error {
 func (uc *UserControllerImpl) GetMyProfile(ctx *fiber.Ctx) error {
 	c := ctx.Context()
 
-	res, customErr := uc.userusecase.GetMyProfile(c, ctx.Get("token"))
+	res, customErr := uc.userusecase.GetMyProfile(c, utils.GetPrincipal(ctx))
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -119,7 +122,7 @@ func (uc *UserControllerImpl) CreateAlamat(ctx *fiber.Ctx) error {
 		})
 	}
 
-	res, customErr := uc.userusecase.CreateAlamat(c, data, ctx.Get("token"))
+	res, customErr := uc.userusecase.CreateAlamat(c, data, utils.GetPrincipal(ctx))
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -183,7 +186,7 @@ func (uc *UserControllerImpl) UpdateProfile(ctx *fiber.Ctx) error {
 		})
 	}
 
-	customErr := uc.userusecase.UpdateProfile(c, ctx.Get("token"), data)
+	customErr := uc.userusecase.UpdateProfile(c, utils.GetPrincipal(ctx), data)
 	if customErr != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
 		return helper.ResponseWithJSON(&helper.JSONRespArgs{
@@ -220,3 +223,86 @@ func (uc *UserControllerImpl) DeleteAlamatById(ctx *fiber.Ctx) error {
 		Data:       "Delete succeed",
 	})
 }
+
+// GetMyWishlists handles the delivery logic to retrieve the wishlist data of the current user
+func (uc *UserControllerImpl) GetMyWishlists(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	filter := &dto.WishlistFilter{}
+	if err := ctx.QueryParser(filter); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.userusecase.GetMyWishlists(c, utils.GetPrincipal(ctx), filter)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// AddWishlist handles the delivery logic to add the produk to the wishlist of the current user
+func (uc *UserControllerImpl) AddWishlist(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.WishlistCreateReq{}
+	if err := ctx.BodyParser(data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.userusecase.AddWishlist(c, utils.GetPrincipal(ctx), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusCreated,
+		Data:       res,
+	})
+}
+
+// RemoveWishlist handles the delivery logic to remove the produk having the id from the wishlist of the current user
+func (uc *UserControllerImpl) RemoveWishlist(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.userusecase.RemoveWishlist(c, utils.GetPrincipal(ctx), ctx.Params("id_produk"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Delete succeed",
+	})
+}
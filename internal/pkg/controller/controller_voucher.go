@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/usecase"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type VoucherController interface {
+	GetAllVouchers(ctx *fiber.Ctx) error
+	GetVoucherById(ctx *fiber.Ctx) error
+	CreateVoucher(ctx *fiber.Ctx) error
+	UpdateVoucherById(ctx *fiber.Ctx) error
+	DeleteVoucherById(ctx *fiber.Ctx) error
+}
+
+type VoucherControllerImpl struct {
+	voucherusecase usecase.VoucherUseCase
+}
+
+// NewVoucherController returns the controller for the voucher group path
+func NewVoucherController(voucherusecase usecase.VoucherUseCase) VoucherController {
+	return &VoucherControllerImpl{
+		voucherusecase: voucherusecase,
+	}
+}
+
+// GetAllVouchers handles the delivery logic to retrieve all voucher data managed by the current user
+func (uc *VoucherControllerImpl) GetAllVouchers(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	filter := &dto.VoucherFilter{}
+	err := ctx.QueryParser(filter)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.voucherusecase.GetAllVouchers(c, utils.GetPrincipal(ctx), filter)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// GetVoucherById handles the delivery logic to retrieve voucher data having the id
+func (uc *VoucherControllerImpl) GetVoucherById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	res, customErr := uc.voucherusecase.GetVoucherById(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// CreateVoucher handles the delivery logic to insert the voucher data
+func (uc *VoucherControllerImpl) CreateVoucher(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.VoucherCreateReq{}
+	err := ctx.BodyParser(data)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	res, customErr := uc.voucherusecase.CreateVoucher(c, utils.GetPrincipal(ctx), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       res,
+	})
+}
+
+// UpdateVoucherById handles the delivery logic to update voucher data having the id
+func (uc *VoucherControllerImpl) UpdateVoucherById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	data := &dto.VoucherUpdateReq{}
+	err := ctx.BodyParser(data)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusBadRequest,
+			Errors:     []string{err.Error()},
+		})
+	}
+
+	customErr := uc.voucherusecase.UpdateVoucherById(c, utils.GetPrincipal(ctx), ctx.Params("id"), data)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Update succeed",
+	})
+}
+
+// DeleteVoucherById handles the delivery logic to delete voucher data having the id
+func (uc *VoucherControllerImpl) DeleteVoucherById(ctx *fiber.Ctx) error {
+	c := ctx.Context()
+
+	customErr := uc.voucherusecase.DeleteVoucherById(c, utils.GetPrincipal(ctx), ctx.Params("id"))
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, customErr.Err.Error())
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: customErr.Code,
+			Errors:     []string{customErr.Err.Error()},
+		})
+	}
+
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusOK,
+		Data:       "Delete succeed",
+	})
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type AuthReqRegister struct {
 	Nama         string `json:"nama" validate:"required"`
 	KataSandi    string `json:"kata_sandi" validate:"required"`
@@ -18,6 +20,26 @@ type AuthReqLogin struct {
 	KataSandi string `json:"kata_sandi" validate:"required"`
 }
 
+type AuthReqRefresh struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type AuthReqVerifyOtp struct {
+	Otp string `json:"otp" validate:"required"`
+}
+
+type AuthReqForgotPassword struct {
+	Notelp string `json:"no_telp" validate:"required_without=Email"`
+	Email  string `json:"email" validate:"required_without=Notelp"`
+}
+
+type AuthReqResetPassword struct {
+	Notelp    string `json:"no_telp" validate:"required_without=Email"`
+	Email     string `json:"email" validate:"required_without=Notelp"`
+	Otp       string `json:"otp" validate:"required"`
+	KataSandi string `json:"kata_sandi" validate:"required"`
+}
+
 type AuthReqUpdate struct {
 	Nama         string `json:"title,omitempty"`
 	KataSandi    string `json:"kata_sandi,omitempty"`
@@ -42,4 +64,17 @@ type LoginResp struct {
 	IdProvinsi   *ProvinceResp `json:"id_provinsi"`
 	IdKota       *CityResp     `json:"id_kota"`
 	Token        string        `json:"token"`
+	RefreshToken string        `json:"refresh_token"`
+}
+
+type RefreshResp struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type OtpResp struct {
+	Channel   string    `json:"channel"`
+	Target    string    `json:"target"`
+	ExpiredAt time.Time `json:"expired_at"`
+	ResendAt  time.Time `json:"resend_at"`
 }
@@ -1,9 +1,18 @@
 package dto
 
+import "tugas_akhir_example/internal/pkg/pagination"
+
+type AllBookResp struct {
+	pagination.Meta
+	Data []BookResp `json:"data"`
+}
+
 type BookFilter struct {
 	Title string `query:"title"`
 	Limit int    `query:"limit"`
 	Page  int    `query:"page"`
+	Sort  string `query:"sort"`
+	Order string `query:"order"`
 }
 
 type BookReqCreate struct {
@@ -0,0 +1,52 @@
+package dto
+
+import "tugas_akhir_example/internal/pkg/money"
+
+type CartResp struct {
+	Tokos          []*CartTokoResp `json:"toko"`
+	TotalKuantitas int             `json:"total_kuantitas"`
+	HargaTotal     int             `json:"harga_total"`
+	Checkoutable   bool            `json:"checkoutable"`
+}
+
+type CartTokoResp struct {
+	Toko       *TokoResp       `json:"toko"`
+	Items      []*CartItemResp `json:"items"`
+	HargaTotal int             `json:"harga_total"`
+}
+
+type CartItemResp struct {
+	Id                   uint              `json:"id"`
+	ProductId            uint              `json:"product_id"`
+	VariantId            *uint             `json:"variant_id,omitempty"`
+	Sku                  string            `json:"sku,omitempty"`
+	Opsi                 map[string]string `json:"opsi,omitempty"`
+	NamaProduk           string            `json:"nama_produk"`
+	Slug                 string            `json:"slug"`
+	HargaKonsumen        money.Rupiah      `json:"harga_konsumen"`
+	HargaSaatDitambahkan money.Rupiah      `json:"harga_saat_ditambahkan"`
+	HargaBerubah         bool              `json:"harga_berubah"`
+	Stok                 int               `json:"stok"`
+	Kuantitas            int               `json:"kuantitas"`
+	HargaTotal           int               `json:"harga_total"`
+	Tersedia             bool              `json:"tersedia"`
+	Pesan                string            `json:"pesan,omitempty"`
+	Photos               []*FotoProdukResp `json:"photos"`
+}
+
+type CartItemCreateReq struct {
+	ProductId uint `json:"product_id" validate:"required"`
+	VariantId uint `json:"variant_id"`
+	Kuantitas int  `json:"kuantitas" validate:"required,min=1"`
+}
+
+type CartItemUpdateReq struct {
+	Kuantitas int `json:"kuantitas" validate:"required,min=1"`
+}
+
+type CartCheckoutReq struct {
+	MethodBayar string `json:"method_bayar"`
+	AlamatKirim uint   `json:"alamat_kirim" validate:"required"`
+	Layanan     string `json:"layanan"`
+	KodeVoucher string `json:"kode_voucher"`
+}
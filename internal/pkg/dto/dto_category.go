@@ -1,5 +1,20 @@
 package dto
 
+import "tugas_akhir_example/internal/pkg/pagination"
+
+type AllCategoryResp struct {
+	pagination.Meta
+	Data []*CategoryResp `json:"data"`
+}
+
+type CategoryFilter struct {
+	NamaCategory string `query:"nama"`
+	Limit        int    `query:"limit"`
+	Page         int    `query:"page"`
+	Sort         string `query:"sort"`
+	Order        string `query:"order"`
+}
+
 type CategoryResp struct {
 	ID           uint   `json:"id"`
 	NamaCategory string `json:"nama_category"`
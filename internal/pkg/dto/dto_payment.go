@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type PaymentResp struct {
+	Provider  string     `json:"provider"`
+	Reference string     `json:"reference"`
+	Amount    int        `json:"amount"`
+	Status    string     `json:"status"`
+	Instruksi string     `json:"instruksi"`
+	ExpiredAt *time.Time `json:"expired_at"`
+	PaidAt    *time.Time `json:"paid_at"`
+}
+
+type PaymentWebhookResp struct {
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Duplicate bool   `json:"duplicate"`
+}
@@ -1,54 +1,144 @@
 package dto
 
+import (
+	"tugas_akhir_example/internal/pkg/money"
+	"tugas_akhir_example/internal/pkg/pagination"
+)
+
 type AllProdukResp struct {
-	Page  int           `json:"page"`
-	Limit int           `json:"limit"`
-	Data  []*ProdukResp `json:"data"`
+	pagination.Meta
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Data       []*ProdukResp `json:"data"`
 }
 
 type ProdukResp struct {
-	Id            uint             `json:"id"`
-	NamaProduk    string           `json:"nama_produk"`
-	Slug          string           `json:"slug"`
-	HargaReseller int              `json:"harga_reseler"`
-	HargaKonsumen int              `json:"harga_konsumen"`
-	Stok          int              `json:"stok"`
-	Deskripsi     string           `json:"deskripsi"`
-	Toko          TokoResp         `json:"toko"`
-	Category      CategoryResp     `json:"category"`
-	Photo         []FotoProdukResp `json:"photos"`
+	Id            uint                 `json:"id"`
+	NamaProduk    string               `json:"nama_produk"`
+	Slug          string               `json:"slug"`
+	HargaReseller money.Rupiah         `json:"harga_reseler"`
+	HargaKonsumen money.Rupiah         `json:"harga_konsumen"`
+	Stok          int                  `json:"stok"`
+	Berat         int                  `json:"berat"`
+	Deskripsi     string               `json:"deskripsi"`
+	Toko          TokoResp             `json:"toko"`
+	Category      CategoryResp         `json:"category"`
+	Photo         []FotoProdukResp     `json:"photos"`
+	Variants      []*ProdukVariantResp `json:"variants"`
+	Rating        *RatingResp          `json:"rating,omitempty"`
 }
 
 type ProdukFilter struct {
-	NamaProduk string `query:"nama_produk"`
-	Limit      int    `query:"limit"`
-	Page       int    `query:"page"`
-	CategoryId uint   `query:"category_id"`
-	TokoId     uint   `query:"toko_id"`
-	MaxHarga   int    `query:"max_harga"`
-	MinHarga   int    `query:"min_harga"`
+	NamaProduk string       `query:"nama_produk"`
+	Limit      int          `query:"limit"`
+	Page       int          `query:"page"`
+	CategoryId uint         `query:"category_id"`
+	TokoId     uint         `query:"toko_id"`
+	MaxHarga   money.Rupiah `query:"max_harga"`
+	MinHarga   money.Rupiah `query:"min_harga"`
+	Sort       string       `query:"sort"`
+	Order      string       `query:"order"`
+	Cursor     string       `query:"cursor"`
+}
+
+type ProdukSearchFilter struct {
+	Q          string       `query:"q"`
+	CategoryId uint         `query:"category_id"`
+	TokoId     uint         `query:"toko_id"`
+	MaxHarga   money.Rupiah `query:"max_harga"`
+	MinHarga   money.Rupiah `query:"min_harga"`
+	Limit      int          `query:"limit"`
+	Page       int          `query:"page"`
+}
+
+type ProdukSearchResp struct {
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	Total   int                 `json:"total"`
+	Keyword string              `json:"keyword"`
+	Data    []*ProdukResp       `json:"data"`
+	Facets  *ProdukSearchFacets `json:"facets"`
+}
+
+type ProdukSearchFacets struct {
+	Category []*SearchFacetResp      `json:"category"`
+	Toko     []*SearchFacetResp      `json:"toko"`
+	Harga    []*SearchHargaFacetResp `json:"harga"`
+}
+
+type SearchFacetResp struct {
+	Id    uint   `json:"id"`
+	Nama  string `json:"nama"`
+	Count int    `json:"count"`
+}
+
+type SearchHargaFacetResp struct {
+	Min   money.Rupiah  `json:"min"`
+	Max   *money.Rupiah `json:"max"`
+	Count int           `json:"count"`
 }
 
 type ProdukCreateReq struct {
-	NamaProduk    string `form:"nama_produk" validate:"required"`
-	CategoryId    string `form:"category_id" validate:"required"`
-	HargaReseller string `form:"harga_reseller" validate:"required"`
-	HargaKonsumen string `form:"harga_konsumen" validate:"required"`
-	Stok          string `form:"stok" validate:"required"`
-	Deskripsi     string `form:"deskripsi" validate:"required"`
+	NamaProduk    string       `form:"nama_produk" validate:"required"`
+	CategoryId    string       `form:"category_id" validate:"required"`
+	HargaReseller money.Rupiah `form:"harga_reseller" validate:"required,min=1"`
+	HargaKonsumen money.Rupiah `form:"harga_konsumen" validate:"required,min=1"`
+	Stok          string       `form:"stok" validate:"required"`
+	Berat         string       `form:"berat" validate:"omitempty,numeric"`
+	Deskripsi     string       `form:"deskripsi" validate:"required"`
 }
 
 type ProdukUpdateReq struct {
-	NamaProduk    string `form:"nama_produk,omitempty"`
-	CategoryId    string `form:"category_id,omitempty"`
-	HargaReseller string `form:"harga_reseller,omitempty"`
-	HargaKonsumen string `form:"harga_konsumen,omitempty"`
-	Stok          string `form:"stok,omitempty"`
-	Deskripsi     string `form:"deskripsi,omitempty"`
+	NamaProduk    string       `form:"nama_produk,omitempty"`
+	CategoryId    string       `form:"category_id,omitempty"`
+	HargaReseller money.Rupiah `form:"harga_reseller,omitempty" validate:"omitempty,min=1"`
+	HargaKonsumen money.Rupiah `form:"harga_konsumen,omitempty" validate:"omitempty,min=1"`
+	Stok          string       `form:"stok,omitempty"`
+	Berat         string       `form:"berat,omitempty" validate:"omitempty,numeric"`
+	Deskripsi     string       `form:"deskripsi,omitempty"`
 }
 
 type FotoProdukResp struct {
-	ID       uint   `json:"id"`
-	ProdukId uint   `json:"product_id"`
-	Url      string `json:"url"`
+	ID       uint                     `json:"id"`
+	ProdukId uint                     `json:"product_id"`
+	Url      string                   `json:"url"`
+	Sizes    map[string]*FotoSizeResp `json:"sizes,omitempty"`
+}
+
+type FotoSizeResp struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type ProdukVariantResp struct {
+	Id            uint                     `json:"id"`
+	ProdukId      uint                     `json:"product_id"`
+	Sku           string                   `json:"sku"`
+	Opsi          map[string]string        `json:"opsi"`
+	HargaReseller money.Rupiah             `json:"harga_reseler"`
+	HargaKonsumen money.Rupiah             `json:"harga_konsumen"`
+	Stok          int                      `json:"stok"`
+	Photos        []*FotoProdukVariantResp `json:"photos"`
+}
+
+type ProdukVariantCreateReq struct {
+	Sku           string       `form:"sku" validate:"required,max=64"`
+	Opsi          string       `form:"opsi" validate:"required,json"`
+	HargaReseller money.Rupiah `form:"harga_reseller" validate:"omitempty,min=1"`
+	HargaKonsumen money.Rupiah `form:"harga_konsumen" validate:"omitempty,min=1"`
+	Stok          string       `form:"stok" validate:"required,numeric"`
+}
+
+type ProdukVariantUpdateReq struct {
+	Sku           string       `form:"sku,omitempty" validate:"omitempty,max=64"`
+	Opsi          string       `form:"opsi,omitempty" validate:"omitempty,json"`
+	HargaReseller money.Rupiah `form:"harga_reseller,omitempty" validate:"omitempty,min=1"`
+	HargaKonsumen money.Rupiah `form:"harga_konsumen,omitempty" validate:"omitempty,min=1"`
+	Stok          string       `form:"stok,omitempty" validate:"omitempty,numeric"`
+}
+
+type FotoProdukVariantResp struct {
+	ID        uint   `json:"id"`
+	VariantId uint   `json:"variant_id"`
+	Url       string `json:"url"`
 }
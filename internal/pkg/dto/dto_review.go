@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+	"tugas_akhir_example/internal/pkg/pagination"
+)
+
+type AllReviewResp struct {
+	pagination.Meta
+	Rating *RatingResp   `json:"rating"`
+	Data   []*ReviewResp `json:"data"`
+}
+
+type ReviewResp struct {
+	Id          uint              `json:"id"`
+	DetailTrxId uint              `json:"detail_trx_id"`
+	ProdukId    uint              `json:"product_id"`
+	TokoId      uint              `json:"toko_id"`
+	UserId      uint              `json:"user_id"`
+	NamaUser    string            `json:"nama_user"`
+	Rating      int               `json:"rating"`
+	Ulasan      string            `json:"ulasan"`
+	Balasan     string            `json:"balasan"`
+	DibalasAt   *time.Time        `json:"dibalas_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Photos      []*FotoReviewResp `json:"photos"`
+}
+
+type FotoReviewResp struct {
+	ID       uint   `json:"id"`
+	ReviewId uint   `json:"review_id"`
+	Url      string `json:"url"`
+}
+
+type RatingResp struct {
+	RataRata   float64       `json:"rata_rata"`
+	Jumlah     int64         `json:"jumlah"`
+	PerBintang map[int]int64 `json:"per_bintang"`
+}
+
+type ReviewFilter struct {
+	Rating int    `query:"rating"`
+	Limit  int    `query:"limit"`
+	Page   int    `query:"page"`
+	Sort   string `query:"sort"`
+	Order  string `query:"order"`
+}
+
+type ReviewCreateReq struct {
+	DetailTrxId uint   `form:"detail_trx_id" validate:"required"`
+	Rating      int    `form:"rating" validate:"required,min=1,max=5"`
+	Ulasan      string `form:"ulasan" validate:"max=2000"`
+}
+
+type ReviewReplyReq struct {
+	Balasan string `json:"balasan" validate:"required,max=2000"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "tugas_akhir_example/internal/pkg/rbac"
+
+type RoleResp struct {
+	Role        string            `json:"role"`
+	Permissions []rbac.Permission `json:"permissions"`
+}
+
+type UserRoleReq struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type UserRolesResp struct {
+	IdUser uint     `json:"id_user"`
+	Roles  []string `json:"roles"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+type ShippingQuoteReq struct {
+	DetailTrxes []*DetailTrxCreateReq `json:"detail_trx" validate:"required,min=1,dive"`
+}
+
+type ShippingQuoteResp struct {
+	Tokos []*ShippingQuoteTokoResp `json:"toko"`
+}
+
+type ShippingQuoteTokoResp struct {
+	Toko   *TokoResp           `json:"toko"`
+	Asal   string              `json:"asal"`
+	Tujuan string              `json:"tujuan"`
+	Berat  int                 `json:"berat"`
+	Rates  []*ShippingRateResp `json:"rates"`
+}
+
+type ShippingRateResp struct {
+	Kurir     string `json:"kurir"`
+	Layanan   string `json:"layanan"`
+	Deskripsi string `json:"deskripsi"`
+	Ongkir    int    `json:"ongkir"`
+	Etd       string `json:"etd"`
+}
+
+type TrxPengirimanResp struct {
+	Toko    *TokoResp `json:"toko"`
+	Kurir   string    `json:"kurir"`
+	Layanan string    `json:"layanan"`
+	Berat   int       `json:"berat"`
+	Ongkir  int       `json:"ongkir"`
+	Etd     string    `json:"etd"`
+	Status  string    `json:"status"`
+}
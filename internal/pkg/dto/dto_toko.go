@@ -1,16 +1,23 @@
 package dto
 
+import (
+	"time"
+	"tugas_akhir_example/internal/pkg/pagination"
+)
+
 type AllTokoResp struct {
-	Page  int         `json:"page"`
-	Limit int         `json:"limit"`
-	Data  []*TokoResp `json:"data"`
+	pagination.Meta
+	Data []*TokoResp `json:"data"`
 }
 
 type TokoResp struct {
-	ID       uint   `json:"id"`
-	NamaToko string `json:"nama_toko"`
-	UrlFoto  string `json:"url_foto"`
-	UserId   uint   `json:"user_id"`
+	ID             uint        `json:"id"`
+	NamaToko       string      `json:"nama_toko"`
+	Slug           string      `json:"slug"`
+	UrlFoto        string      `json:"url_foto"`
+	UserId         uint        `json:"user_id"`
+	Rating         *RatingResp `json:"rating,omitempty"`
+	JumlahFollower *int64      `json:"jumlah_follower,omitempty"`
 }
 
 type TokoUpdateReq struct {
@@ -21,4 +28,35 @@ type TokoFilter struct {
 	NamaToko string `query:"nama"`
 	Limit    int    `query:"limit"`
 	Page     int    `query:"page"`
+	Sort     string `query:"sort"`
+	Order    string `query:"order"`
+}
+
+type TokoOrderFilter struct {
+	Status         string `query:"status"`
+	KodeInvoice    string `query:"kode_invoice"`
+	TanggalMulai   string `query:"tanggal_mulai"`
+	TanggalSelesai string `query:"tanggal_selesai"`
+	Limit          int    `query:"limit"`
+	Page           int    `query:"page"`
+}
+
+type AllTokoOrderResp struct {
+	Page  int              `json:"page"`
+	Limit int              `json:"limit"`
+	Data  []*TokoOrderResp `json:"data"`
+}
+
+type TokoOrderResp struct {
+	Id          uint           `json:"id"`
+	TrxId       uint           `json:"trx_id"`
+	KodeInvoice string         `json:"kode_invoice"`
+	Status      string         `json:"status"`
+	MethodBayar string         `json:"method_bayar"`
+	TanggalTrx  time.Time      `json:"tanggal_trx"`
+	AlamatKirim *AlamatResp    `json:"alamat_kirim"`
+	LogProduk   *LogProdukResp `json:"product"`
+	Kuantitas   int            `json:"kuantitas"`
+	HargaTotal  int            `json:"harga_total"`
+	Diskon      int            `json:"diskon"`
 }
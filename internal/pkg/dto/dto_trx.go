@@ -1,17 +1,29 @@
 package dto
 
+import (
+	"time"
+	"tugas_akhir_example/internal/pkg/money"
+	"tugas_akhir_example/internal/pkg/pagination"
+)
+
 type AllTrxResp struct {
-	Page  int        `json:"page"`
-	Limit int        `json:"limit"`
-	Data  []*TrxResp `json:"data"`
+	pagination.Meta
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Data       []*TrxResp `json:"data"`
 }
 type TrxResp struct {
-	Id          uint             `json:"id"`
-	HargaTotal  int              `json:"harga_total"`
-	KodeInvoice string           `json:"kode_invoice"`
-	MethodBayar string           `json:"method_bayar"`
-	AlamatKirim *AlamatResp      `json:"alamat_kirim"`
-	DetailTrxes []*DetailTrxResp `json:"detail_trx"`
+	Id          uint                 `json:"id"`
+	HargaTotal  int                  `json:"harga_total"`
+	OngkirTotal int                  `json:"ongkir_total"`
+	Diskon      int                  `json:"diskon"`
+	KodeVoucher string               `json:"kode_voucher"`
+	KodeInvoice string               `json:"kode_invoice"`
+	MethodBayar string               `json:"method_bayar"`
+	Status      string               `json:"status"`
+	AlamatKirim *AlamatResp          `json:"alamat_kirim"`
+	DetailTrxes []*DetailTrxResp     `json:"detail_trx"`
+	Payment     *PaymentResp         `json:"payment"`
+	Pengirimans []*TrxPengirimanResp `json:"pengiriman"`
 }
 
 type DetailTrxResp struct {
@@ -19,20 +31,28 @@ type DetailTrxResp struct {
 	Toko       *TokoResp      `json:"toko"`
 	Kuantitas  int            `json:"kuantitas"`
 	HargaTotal int            `json:"harga_total"`
+	Diskon     int            `json:"diskon"`
 }
 
 type TrxFilter struct {
 	Search string `query:"search"`
+	Status string `query:"status"`
 	Limit  int    `query:"limit"`
 	Page   int    `query:"page"`
+	Sort   string `query:"sort"`
+	Order  string `query:"order"`
+	Cursor string `query:"cursor"`
 }
 
 type LogProdukResp struct {
 	Id            uint              `json:"id"`
 	NamaProduk    string            `json:"nama_produk"`
 	Slug          string            `json:"slug"`
-	HargaReseller int               `json:"harga_reseler"`
-	HargaKonsumen int               `json:"harga_konsumen"`
+	VariantId     *uint             `json:"variant_id,omitempty"`
+	Sku           string            `json:"sku,omitempty"`
+	Opsi          map[string]string `json:"opsi,omitempty"`
+	HargaReseller money.Rupiah      `json:"harga_reseler"`
+	HargaKonsumen money.Rupiah      `json:"harga_konsumen"`
 	Deskripsi     string            `json:"deskripsi"`
 	Toko          *TokoResp         `json:"toko"`
 	Category      *CategoryResp     `json:"category"`
@@ -40,12 +60,31 @@ type LogProdukResp struct {
 }
 
 type TrxCreateReq struct {
-	MethodBayar string                `json:"method_bayar"`
+	MethodBayar string                `json:"method_bayar" validate:"required"`
 	AlamatKirim uint                  `json:"alamat_kirim"`
-	DetailTrxes []*DetailTrxCreateReq `json:"detail_trx"`
+	Layanan     string                `json:"layanan" validate:"omitempty,oneof=REG YES"`
+	KodeVoucher string                `json:"kode_voucher"`
+	DetailTrxes []*DetailTrxCreateReq `json:"detail_trx" validate:"required,min=1,dive"`
 }
 
 type DetailTrxCreateReq struct {
-	ProductId uint `json:"product_id"`
-	Kuantitas int  `json:"kuantitas"`
+	ProductId uint `json:"product_id" validate:"required"`
+	VariantId uint `json:"variant_id"`
+	Kuantitas int  `json:"kuantitas" validate:"required,min=1"`
+}
+
+type TrxStatusUpdateReq struct {
+	Status string `json:"status" validate:"required,oneof=pending paid packed shipped delivered cancelled refunded"`
+	Alasan string `json:"alasan"`
+}
+
+type TrxStatusHistoryResp struct {
+	Id         uint      `json:"id"`
+	UserId     uint      `json:"user_id"`
+	TokoId     *uint     `json:"toko_id,omitempty"`
+	Peran      string    `json:"peran"`
+	StatusLama string    `json:"status_lama"`
+	StatusBaru string    `json:"status_baru"`
+	Alasan     string    `json:"alasan"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+	"tugas_akhir_example/internal/pkg/money"
+	"tugas_akhir_example/internal/pkg/pagination"
+)
+
 type AlamatResp struct {
 	Id           uint   `json:"id"`
 	JudulAlamat  string `json:"judul_alamat"`
@@ -38,6 +44,9 @@ type UserResp struct {
 	IdProvinsi   *ProvinceResp `json:"id_provinsi"`
 	IdKota       *CityResp     `json:"id_kota"`
 	Alamats      []*AlamatResp `json:"alamat"`
+
+	NotelpVerified bool `json:"no_telp_verified"`
+	EmailVerified  bool `json:"email_verified"`
 }
 
 type UserUpdateReq struct {
@@ -52,3 +61,32 @@ type UserUpdateReq struct {
 	IdProvinsi   string `json:"id_provinse,omitempty"`
 	IdKota       string `json:"id_kota,omitempty"`
 }
+
+type AllWishlistResp struct {
+	pagination.Meta
+	Data []*WishlistResp `json:"data"`
+}
+
+type WishlistResp struct {
+	Id            uint             `json:"id"`
+	ProductId     uint             `json:"product_id"`
+	NamaProduk    string           `json:"nama_produk"`
+	Slug          string           `json:"slug"`
+	HargaKonsumen money.Rupiah     `json:"harga_konsumen"`
+	Stok          int              `json:"stok"`
+	Dihapus       bool             `json:"dihapus"`
+	Toko          *TokoResp        `json:"toko"`
+	Photos        []FotoProdukResp `json:"photos"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+type WishlistFilter struct {
+	Limit int    `query:"limit"`
+	Page  int    `query:"page"`
+	Sort  string `query:"sort"`
+	Order string `query:"order"`
+}
+
+type WishlistCreateReq struct {
+	ProductId uint `json:"product_id" validate:"required"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+type AllVoucherResp struct {
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Data  []*VoucherResp `json:"data"`
+}
+
+type VoucherResp struct {
+	Id            uint          `json:"id"`
+	Kode          string        `json:"kode"`
+	Deskripsi     string        `json:"deskripsi"`
+	Tipe          string        `json:"tipe"`
+	Nilai         int           `json:"nilai"`
+	MinBelanja    int           `json:"min_belanja"`
+	MaxDiskon     int           `json:"max_diskon"`
+	KuotaGlobal   int           `json:"kuota_global"`
+	KuotaPerUser  int           `json:"kuota_per_user"`
+	Terpakai      int           `json:"terpakai"`
+	BerlakuMulai  string        `json:"berlaku_mulai"`
+	BerlakuSampai string        `json:"berlaku_sampai"`
+	Aktif         bool          `json:"aktif"`
+	Toko          *TokoResp     `json:"toko"`
+	Category      *CategoryResp `json:"category"`
+}
+
+type VoucherFilter struct {
+	Kode  string `query:"kode"`
+	Limit int    `query:"limit"`
+	Page  int    `query:"page"`
+}
+
+type VoucherCreateReq struct {
+	Kode          string `json:"kode" validate:"required,alphanum,max=50"`
+	Deskripsi     string `json:"deskripsi"`
+	Tipe          string `json:"tipe" validate:"required,oneof=percentage fixed"`
+	Nilai         int    `json:"nilai" validate:"required,min=1"`
+	MinBelanja    int    `json:"min_belanja" validate:"min=0"`
+	MaxDiskon     int    `json:"max_diskon" validate:"min=0"`
+	KuotaGlobal   int    `json:"kuota_global" validate:"min=0"`
+	KuotaPerUser  int    `json:"kuota_per_user" validate:"min=0"`
+	BerlakuMulai  string `json:"berlaku_mulai" validate:"required"`
+	BerlakuSampai string `json:"berlaku_sampai" validate:"required"`
+	Aktif         *bool  `json:"aktif"`
+	IdToko        uint   `json:"id_toko"`
+	IdCategory    uint   `json:"id_category"`
+}
+
+type VoucherUpdateReq struct {
+	Kode          string `json:"kode,omitempty" validate:"omitempty,alphanum,max=50"`
+	Deskripsi     string `json:"deskripsi,omitempty"`
+	Tipe          string `json:"tipe,omitempty" validate:"omitempty,oneof=percentage fixed"`
+	Nilai         int    `json:"nilai,omitempty" validate:"min=0"`
+	MinBelanja    int    `json:"min_belanja,omitempty" validate:"min=0"`
+	MaxDiskon     int    `json:"max_diskon,omitempty" validate:"min=0"`
+	KuotaGlobal   int    `json:"kuota_global,omitempty" validate:"min=0"`
+	KuotaPerUser  int    `json:"kuota_per_user,omitempty" validate:"min=0"`
+	BerlakuMulai  string `json:"berlaku_mulai,omitempty"`
+	BerlakuSampai string `json:"berlaku_sampai,omitempty"`
+	Aktif         *bool  `json:"aktif,omitempty"`
+	IdCategory    uint   `json:"id_category,omitempty"`
+}
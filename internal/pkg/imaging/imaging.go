@@ -0,0 +1,178 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// MaxPixels is the maximum number of pixels of an uploaded image, refusing images decoding into huge bitmaps
+const MaxPixels = 40_000_000
+
+// JpegQuality is the quality the opaque images are re-encoded with
+const JpegQuality = 82
+
+var ErrTooManyPixels = errors.New("resolusi gambar terlalu besar")
+
+// Size is a standard size images are resized to, fitting in a box of MaxDimension by MaxDimension pixels
+type Size struct {
+	Name         string
+	MaxDimension int
+}
+
+const (
+	SizeThumb  = "thumb"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Sizes lists the standard sizes generated for each uploaded image, from the smallest
+var Sizes = []Size{
+	{Name: SizeThumb, MaxDimension: 200},
+	{Name: SizeMedium, MaxDimension: 640},
+	{Name: SizeLarge, MaxDimension: 1280},
+}
+
+// Variant is an image re-encoded in one of the standard sizes
+type Variant struct {
+	Size          string
+	Ext           string // jpg for opaque images, png otherwise
+	Width, Height int
+	Data          []byte
+}
+
+// Process decodes the jpeg or png image and re-encodes it in each of the standard sizes, never upscaling it.
+// The exif orientation of jpeg images is applied to the pixels, and no metadata of the upload, such as its gps location, is kept
+func Process(data []byte) (res []*Variant, err error) {
+	img, orientation, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	opaque := isOpaque(img)
+	for _, size := range Sizes {
+		resized := orient(resize(img, size.MaxDimension), orientation)
+
+		variant := &Variant{
+			Size:   size.Name,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		}
+
+		variant.Data, variant.Ext, err = encode(resized, opaque)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, variant)
+	}
+
+	return res, nil
+}
+
+// Sanitize decodes the jpeg or png image and re-encodes it in its own size, for the uploads kept in a single size.
+// Like Process, the exif orientation is applied to the pixels and no metadata of the upload is kept.
+// The ext is jpg for opaque images, png otherwise
+func Sanitize(data []byte) (res []byte, ext string, err error) {
+	img, orientation, err := decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := img.Bounds()
+	return encode(orient(resize(img, max(bounds.Dx(), bounds.Dy())), orientation), isOpaque(img))
+}
+
+// decode decodes the jpeg or png image along with its exif orientation, refusing the images of more than MaxPixels pixels
+// before decoding their pixels
+func decode(data []byte) (img image.Image, orientation int, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return nil, 0, ErrTooManyPixels
+	}
+
+	img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orientation = 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	return img, orientation, nil
+}
+
+// encode encodes the image as a jpeg when it is opaque, as a png otherwise, without any metadata
+func encode(img image.Image, opaque bool) (res []byte, ext string, err error) {
+	buff := &bytes.Buffer{}
+	if opaque {
+		ext = "jpg"
+		err = jpeg.Encode(buff, img, &jpeg.Options{Quality: JpegQuality})
+	} else {
+		ext = "png"
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(buff, img)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buff.Bytes(), ext, nil
+}
+
+// resize returns the image scaled down to fit in a box of maxDimension by maxDimension pixels, keeping its aspect ratio
+func resize(img image.Image, maxDimension int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxDimension || height > maxDimension {
+		if width >= height {
+			width, height = maxDimension, max(1, height*maxDimension/bounds.Dx())
+		} else {
+			width, height = max(1, width*maxDimension/bounds.Dy()), maxDimension
+		}
+	}
+
+	res := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(res, res.Bounds(), img, bounds.Min, draw.Src)
+		return res
+	}
+
+	xdraw.CatmullRom.Scale(res, res.Bounds(), img, bounds, xdraw.Src, nil)
+	return res
+}
+
+// isOpaque reports whether the image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,242 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage returns an image whose left half is red and right half is blue, with the given alpha
+func testImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: alpha})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{B: 0xff, A: alpha})
+			}
+		}
+	}
+
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	buff := &bytes.Buffer{}
+	if err := png.Encode(buff, img); err != nil {
+		t.Fatalf("png.Encode error = %v", err)
+	}
+
+	return buff.Bytes()
+}
+
+// encodeJPEG returns the image encoded as a jpeg holding an exif segment with the orientation
+func encodeJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	buff := &bytes.Buffer{}
+	if err := jpeg.Encode(buff, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode error = %v", err)
+	}
+
+	// a big endian tiff header with one ifd holding the orientation tag
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = append(tiff, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xff, 0xe1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buff.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	img := testImage(16, 8, 0xff)
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := jpegOrientation(encodeJPEG(t, img, orientation)); got != int(orientation) {
+			t.Errorf("jpegOrientation = %d, want %d", got, orientation)
+		}
+	}
+
+	if got := jpegOrientation(encodeJPEG(t, img, 9)); got != 1 {
+		t.Errorf("jpegOrientation of an invalid orientation = %d, want 1", got)
+	}
+	if got := jpegOrientation(encodePNG(t, img)); got != 1 {
+		t.Errorf("jpegOrientation of a png = %d, want 1", got)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a 3x2 image numbering its pixels in reading order
+	//   1 2 3
+	//   4 5 6
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetRGBA(i%3, i/3, color.RGBA{R: uint8(i + 1), A: 0xff})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+	}
+
+	for _, tt := range tests {
+		res := orient(src, tt.orientation)
+		if res.Bounds().Dx() != len(tt.want[0]) || res.Bounds().Dy() != len(tt.want) {
+			t.Errorf("orient(%d) size = %dx%d, want %dx%d", tt.orientation, res.Bounds().Dx(), res.Bounds().Dy(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+
+		for y, row := range tt.want {
+			for x, want := range row {
+				if got := res.RGBAAt(x, y).R; got != want {
+					t.Errorf("orient(%d) pixel (%d, %d) = %d, want %d", tt.orientation, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestProcessSizes(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		want          [][2]int
+	}{
+		{"landscape", 2000, 1000, [][2]int{{200, 100}, {640, 320}, {1280, 640}}},
+		{"portrait", 900, 1800, [][2]int{{100, 200}, {320, 640}, {640, 1280}}},
+		{"small images are not upscaled", 300, 150, [][2]int{{200, 100}, {300, 150}, {300, 150}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Process(encodePNG(t, testImage(tt.width, tt.height, 0xff)))
+			if err != nil {
+				t.Fatalf("Process error = %v", err)
+			}
+
+			if len(res) != len(Sizes) {
+				t.Fatalf("%d variants, want %d", len(res), len(Sizes))
+			}
+			for i, v := range res {
+				if v.Size != Sizes[i].Name || v.Width != tt.want[i][0] || v.Height != tt.want[i][1] {
+					t.Errorf("variant %d = %s %dx%d, want %s %dx%d", i, v.Size, v.Width, v.Height, Sizes[i].Name, tt.want[i][0], tt.want[i][1])
+				}
+
+				config, format, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				if err != nil || format != "jpeg" || v.Ext != "jpg" || config.Width != v.Width || config.Height != v.Height {
+					t.Errorf("variant %d encoded as %s %dx%d (%v), want jpeg %dx%d", i, format, config.Width, config.Height, err, v.Width, v.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessOrientation(t *testing.T) {
+	// rotated a quarter turn clockwise, the red left half ends up on top
+	res, err := Process(encodeJPEG(t, testImage(400, 200, 0xff), 6))
+	if err != nil {
+		t.Fatalf("Process error = %v", err)
+	}
+
+	thumb := res[0]
+	if thumb.Width != 100 || thumb.Height != 200 {
+		t.Fatalf("thumb = %dx%d, want 100x200", thumb.Width, thumb.Height)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(thumb.Data))
+	if err != nil {
+		t.Fatalf("jpeg.Decode error = %v", err)
+	}
+	if r, _, b, _ := img.At(50, 50).RGBA(); r < 0xc000 || b > 0x4000 {
+		t.Errorf("top of the thumb is not red")
+	}
+	if r, _, b, _ := img.At(50, 150).RGBA(); b < 0xc000 || r > 0x4000 {
+		t.Errorf("bottom of the thumb is not blue")
+	}
+}
+
+func TestProcessTransparency(t *testing.T) {
+	res, err := Process(encodePNG(t, testImage(300, 100, 0x80)))
+	if err != nil {
+		t.Fatalf("Process error = %v", err)
+	}
+
+	for _, v := range res {
+		if _, format, err := image.DecodeConfig(bytes.NewReader(v.Data)); err != nil || format != "png" || v.Ext != "png" {
+			t.Errorf("%s variant encoded as %s (%v), want png", v.Size, format, err)
+		}
+	}
+}
+
+func TestProcessTooManyPixels(t *testing.T) {
+	data := encodePNG(t, testImage(2, 2, 0xff))
+
+	// rewrite the ihdr chunk, after the 8 bytes png signature, to claim 10000x10000 pixels
+	binary.BigEndian.PutUint32(data[16:], 10000)
+	binary.BigEndian.PutUint32(data[20:], 10000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Process(data); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Process error = %v, want ErrTooManyPixels", err)
+	}
+	if _, _, err := Sanitize(data); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Sanitize error = %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	data := encodeJPEG(t, testImage(300, 100, 0xff), 8)
+	if !bytes.Contains(data, []byte("Exif")) {
+		t.Fatalf("test jpeg holds no exif segment")
+	}
+
+	res, ext, err := Sanitize(data)
+	if err != nil {
+		t.Fatalf("Sanitize error = %v", err)
+	}
+
+	if bytes.Contains(res, []byte("Exif")) {
+		t.Errorf("sanitized image still holds the exif segment")
+	}
+
+	// the orientation is applied in the original size
+	config, format, err := image.DecodeConfig(bytes.NewReader(res))
+	if err != nil || format != "jpeg" || ext != "jpg" || config.Width != 100 || config.Height != 300 {
+		t.Errorf("sanitized image = %s %dx%d (%v), want jpeg 100x300", format, config.Width, config.Height, err)
+	}
+
+	res, ext, err = Sanitize(encodePNG(t, testImage(30, 10, 0x80)))
+	if err != nil {
+		t.Fatalf("Sanitize error = %v", err)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(res)); err != nil || format != "png" || ext != "png" {
+		t.Errorf("sanitized transparent image = %s (%v), want png", format, err)
+	}
+
+	if _, _, err := Sanitize([]byte("not an image")); err == nil {
+		t.Errorf("Sanitize of garbage succeeded")
+	}
+}
@@ -0,0 +1,107 @@
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rupiah is an amount of money in whole rupiah
+type Rupiah int64
+
+var ErrInvalidRupiah = errors.New("nominal rupiah tidak valid")
+
+// Parse parses an amount written either as plain digits or in the rupiah notation, e.g. 75000, 75.000 or Rp 75.000.
+// The dots may only separate the thousands, so decimals such as 12.5 are rejected rather than read as 125
+func Parse(s string) (Rupiah, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "Rp"), "rp")
+	s = strings.TrimSpace(strings.TrimSuffix(s, ",00"))
+
+	groups := strings.Split(s, ".")
+	for i, group := range groups {
+		if group == "" || (i > 0 && len(group) != 3) || (len(groups) > 1 && i == 0 && len(group) > 3) {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidRupiah, s)
+		}
+
+		for _, c := range group {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("%w: %q", ErrInvalidRupiah, s)
+			}
+		}
+	}
+
+	amount, err := strconv.ParseInt(strings.Join(groups, ""), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRupiah, s)
+	}
+
+	return Rupiah(amount), nil
+}
+
+// Int returns the amount as an int
+func (r Rupiah) Int() int {
+	return int(r)
+}
+
+// Mul returns the amount multiplied by the kuantitas
+func (r Rupiah) Mul(kuantitas int) Rupiah {
+	return r * Rupiah(kuantitas)
+}
+
+// String formats the amount in the rupiah notation, e.g. Rp75.000
+func (r Rupiah) String() string {
+	sign := ""
+	amount := int64(r)
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+
+	return fmt.Sprintf("%sRp%s", sign, strings.Join(groups, "."))
+}
+
+// UnmarshalText parses the amount sent through form and query values
+func (r *Rupiah) UnmarshalText(text []byte) error {
+	amount, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = amount
+	return nil
+}
+
+// UnmarshalJSON parses the amount sent either as a json number or a json string
+func (r *Rupiah) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		text, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+
+		return r.UnmarshalText([]byte(text))
+	}
+
+	amount, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRupiah, data)
+	}
+
+	*r = Rupiah(amount)
+	return nil
+}
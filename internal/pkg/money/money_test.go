@@ -0,0 +1,99 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rupiah
+		wantErr bool
+	}{
+		{in: "75000", want: 75000},
+		{in: "75.000", want: 75000},
+		{in: "Rp 75.000", want: 75000},
+		{in: "Rp75.000,00", want: 75000},
+		{in: "rp 1.250.000", want: 1250000},
+		{in: " 0 ", want: 0},
+		{in: "999", want: 999},
+		{in: "12.5", wantErr: true},
+		{in: "1.5", wantErr: true},
+		{in: "12.50", wantErr: true},
+		{in: "1.2345", wantErr: true},
+		{in: "1234.567", wantErr: true},
+		{in: ".500", wantErr: true},
+		{in: "1.", wantErr: true},
+		{in: "1..000", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "-5000", wantErr: true},
+		{in: "Rp", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidRupiah) {
+				t.Errorf("Parse(%q) = %d, %v, want ErrInvalidRupiah", tt.in, got, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRupiahString(t *testing.T) {
+	tests := []struct {
+		in   Rupiah
+		want string
+	}{
+		{0, "Rp0"},
+		{999, "Rp999"},
+		{75000, "Rp75.000"},
+		{1250000, "Rp1.250.000"},
+		{-75000, "-Rp75.000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Rupiah(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+
+		// the notation parses back to the amount
+		if tt.in >= 0 {
+			if got, err := Parse(tt.want); err != nil || got != tt.in {
+				t.Errorf("Parse(%q) = %d, %v, want %d", tt.want, got, err, tt.in)
+			}
+		}
+	}
+}
+
+func TestRupiahUnmarshalJSON(t *testing.T) {
+	var data struct {
+		Harga Rupiah `json:"harga"`
+	}
+
+	for in, want := range map[string]Rupiah{
+		`{"harga":75000}`:       75000,
+		`{"harga":"Rp 75.000"}`: 75000,
+		`{"harga":null}`:        0,
+	} {
+		data.Harga = 0
+		if err := json.Unmarshal([]byte(in), &data); err != nil || data.Harga != want {
+			t.Errorf("json.Unmarshal(%s) = %d, %v, want %d", in, data.Harga, err, want)
+		}
+	}
+
+	for _, in := range []string{`{"harga":"12.5"}`, `{"harga":12.5}`} {
+		if err := json.Unmarshal([]byte(in), &data); err == nil {
+			t.Errorf("json.Unmarshal(%s) succeeded, want an error", in)
+		}
+	}
+}
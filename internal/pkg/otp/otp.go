@@ -0,0 +1,53 @@
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Digits is the length of the generated codes
+const Digits = 6
+
+// Generate returns a random numeric code of Digits digits
+func Generate() (string, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).Exp(big.NewInt(10), big.NewInt(Digits), nil))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", Digits, n.Int64()), nil
+}
+
+// Hash returns the hex encoded hmac-sha256 of the code bound to the user and the purpose. Keyed by the secret,
+// the few possible codes cannot be brute forced from a leaked table
+func Hash(secret string, idUser uint, purpose, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%s:%s", idUser, purpose, code)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Equal reports whether the code matches the hash, in constant time
+func Equal(hash, secret string, idUser uint, purpose, code string) bool {
+	return hmac.Equal([]byte(hash), []byte(Hash(secret, idUser, purpose, code)))
+}
+
+// Mask hides most of the notelp or email address the code is sent to, e.g. 0812******89 or ab***@mail.com
+func Mask(target string) string {
+	local, domain, isEmail := strings.Cut(target, "@")
+	if !isEmail {
+		if len(target) <= 6 {
+			return strings.Repeat("*", len(target))
+		}
+		return target[:4] + strings.Repeat("*", len(target)-6) + target[len(target)-2:]
+	}
+
+	if len(local) <= 2 {
+		return strings.Repeat("*", len(local)) + "@" + domain
+	}
+	return local[:2] + strings.Repeat("*", len(local)-2) + "@" + domain
+}
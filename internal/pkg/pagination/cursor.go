@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// KeysetClause is the order clause of the listings continued by cursor, the newest items first
+const KeysetClause = "created_at desc, id desc"
+
+var (
+	ErrInvalidCursor = errors.New("cursor tidak valid")
+	ErrCursorSort    = errors.New("cursor hanya dapat digunakan dengan sort created_at order desc")
+)
+
+// Cursor points at the last item of the previous page, the next page starts right after it
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        uint      `json:"i"`
+}
+
+// Encode returns the opaque string of the cursor handed to the client
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the cursor of the opaque string handed back by the client
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	res := &Cursor{}
+	if err := json.Unmarshal(raw, res); err != nil || res.CreatedAt.IsZero() || res.Id == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return res, nil
+}
+
+// CursorClause returns the cursor decoded from the encoded cursor along with the order clause of the page.
+// Without a cursor it falls back to Clause, while a cursor continues the listing sorted by KeysetClause, the only sort a cursor can follow
+func (f SortFields) CursorClause(encoded, sortField, order, defaultClause string) (*Cursor, string, error) {
+	clause, err := f.Clause(sortField, order, defaultClause)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if encoded == "" {
+		return nil, clause, nil
+	}
+
+	if sortField != "" && clause != KeysetClause {
+		return nil, "", ErrCursorSort
+	}
+
+	cursor, err := DecodeCursor(encoded)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cursor, KeysetClause, nil
+}
+
+// Window returns the offset and limit to query the page by. A page continued by the cursor starts right after the cursor instead of at an offset,
+// and a page sorted by KeysetClause asks for one more item telling whether there is a next page to point the next cursor at
+func (p *Pagination) Window(cursor *Cursor, clause string) (offset, limit int) {
+	offset, limit = p.Offset(), p.Limit
+	if cursor != nil {
+		p.Page, offset = 0, 0
+	}
+
+	if clause == KeysetClause {
+		limit++
+	}
+
+	return offset, limit
+}
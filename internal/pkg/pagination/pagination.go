@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Pagination is the page of items requested by the client
+type Pagination struct {
+	Page  int
+	Limit int
+}
+
+// Meta describes the returned page along with how many items and pages there are in total, the page is left out of the pages continued by cursor
+type Meta struct {
+	Page       int   `json:"page,omitempty"`
+	Limit      int   `json:"limit"`
+	TotalItems int64 `json:"total_items"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// SortFields maps the sort fields accepted from the client to the columns they sort by
+type SortFields map[string]string
+
+// New returns the pagination of the page and limit, falling back to the first page of DefaultLimit items and capping the limit at MaxLimit
+func New(page, limit int) Pagination {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return Pagination{
+		Page:  page,
+		Limit: limit,
+	}
+}
+
+// Offset returns how many items come before the page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Meta returns the meta of the page out of the total items
+func (p Pagination) Meta(totalItems int64) Meta {
+	return Meta{
+		Page:       p.Page,
+		Limit:      p.Limit,
+		TotalItems: totalItems,
+		TotalPages: int((totalItems + int64(p.Limit) - 1) / int64(p.Limit)),
+	}
+}
+
+// Clause returns the order clause of the sort field and order requested by the client, falling back to the default clause when no sort field is given.
+// The id column of the sorted field's table breaks the ties so the items keep a stable position across pages
+func (f SortFields) Clause(sortField, order, defaultClause string) (string, error) {
+	sortField, order = strings.ToLower(strings.TrimSpace(sortField)), strings.ToLower(strings.TrimSpace(order))
+	if sortField == "" {
+		return defaultClause, nil
+	}
+
+	column, ok := f[sortField]
+	if !ok {
+		return "", fmt.Errorf("sort %s tidak didukung, gunakan salah satu dari %s", sortField, strings.Join(f.names(), ", "))
+	}
+
+	switch order {
+	case "":
+		order = OrderAsc
+	case OrderAsc, OrderDesc:
+	default:
+		return "", fmt.Errorf("order %s tidak didukung, gunakan %s atau %s", order, OrderAsc, OrderDesc)
+	}
+
+	idColumn := "id"
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		idColumn = column[:i+1] + "id"
+	}
+
+	return fmt.Sprintf("%s %s, %s %s", column, order, idColumn, order), nil
+}
+
+// names returns the sorted sort fields accepted from the client
+func (f SortFields) names() (res []string) {
+	for k := range f {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
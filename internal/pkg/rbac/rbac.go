@@ -0,0 +1,140 @@
+package rbac
+
+import "sort"
+
+// Permission is a named action a role is allowed to take
+type Permission string
+
+const (
+	PermissionProfileManage  Permission = "profile:manage"
+	PermissionAlamatManage   Permission = "alamat:manage"
+	PermissionWishlistManage Permission = "wishlist:manage"
+	PermissionCartManage     Permission = "cart:manage"
+	PermissionTokoFollow     Permission = "toko:follow"
+	PermissionReviewCreate   Permission = "review:create"
+	PermissionTrxRead        Permission = "trx:read"
+	PermissionTrxBuy         Permission = "trx:buy"
+
+	PermissionTokoManage    Permission = "toko:manage"
+	PermissionProdukManage  Permission = "produk:manage"
+	PermissionVoucherManage Permission = "voucher:manage"
+	PermissionReviewReply   Permission = "review:reply"
+	PermissionTrxSell       Permission = "trx:sell"
+
+	// PermissionTrxModerate allows acting as the admin on any trx
+	PermissionTrxModerate Permission = "trx:moderate"
+	// PermissionVoucherManageAll allows managing the vouchers of every toko along with the global ones
+	PermissionVoucherManageAll Permission = "voucher:manage_all"
+	PermissionCategoryManage   Permission = "category:manage"
+	PermissionBookManage       Permission = "book:manage"
+	PermissionRoleManage       Permission = "role:manage"
+)
+
+const (
+	RoleBuyer    = "buyer"
+	RoleSeller   = "seller"
+	RoleReseller = "reseller"
+	RoleAdmin    = "admin"
+	RoleSupport  = "support"
+)
+
+// DefaultRoles lists the roles given to the registered users, which all get a toko along with their account
+var DefaultRoles = []string{RoleBuyer, RoleSeller}
+
+var (
+	buyerPermissions = []Permission{
+		PermissionProfileManage,
+		PermissionAlamatManage,
+		PermissionWishlistManage,
+		PermissionCartManage,
+		PermissionTokoFollow,
+		PermissionReviewCreate,
+		PermissionTrxRead,
+		PermissionTrxBuy,
+	}
+
+	sellerPermissions = []Permission{
+		PermissionProfileManage,
+		PermissionTokoManage,
+		PermissionProdukManage,
+		PermissionVoucherManage,
+		PermissionReviewReply,
+		PermissionTrxRead,
+		PermissionTrxSell,
+	}
+)
+
+// rolePermissions lists the permissions granted by each role
+var rolePermissions = map[string][]Permission{
+	RoleBuyer:    buyerPermissions,
+	RoleSeller:   sellerPermissions,
+	RoleReseller: concat(buyerPermissions, sellerPermissions),
+	RoleSupport: {
+		PermissionProfileManage,
+		PermissionTrxRead,
+		PermissionTrxModerate,
+	},
+	RoleAdmin: concat(buyerPermissions, sellerPermissions, []Permission{
+		PermissionTrxModerate,
+		PermissionVoucherManageAll,
+		PermissionCategoryManage,
+		PermissionBookManage,
+		PermissionRoleManage,
+	}),
+}
+
+// concat returns the permissions of the lists joined in a new list
+func concat(lists ...[]Permission) (res []Permission) {
+	for _, v := range lists {
+		res = append(res, v...)
+	}
+
+	return res
+}
+
+// Roles returns the names of the roles, sorted
+func Roles() []string {
+	res := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		res = append(res, role)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// ValidRole reports whether the role exists
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Permissions returns the permissions granted by the role, without duplicates and sorted
+func Permissions(role string) []Permission {
+	seen := map[Permission]struct{}{}
+	res := []Permission{}
+	for _, v := range rolePermissions[role] {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+
+		seen[v] = struct{}{}
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+
+	return res
+}
+
+// HasPermission reports whether one of the roles grants the permission
+func HasPermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		for _, v := range rolePermissions[role] {
+			if v == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -14,6 +14,8 @@ import (
 
 type AuthRepository interface {
 	GetUserByNotelp(ctx context.Context, nama string) (res *daos.User, err error)
+	GetUserByEmail(ctx context.Context, email string) (res *daos.User, err error)
+	GetUserById(ctx context.Context, id uint) (res *daos.User, err error)
 	GetProvinceById(ctx context.Context, provId string) (res *dto.ProvinceResp, err error)
 	GetCityById(ctx context.Context, cityId string) (res *dto.CityResp, err error)
 	CreateUser(ctx context.Context, data *daos.User) (res uint, err error)
@@ -39,6 +41,26 @@ func (alr *AuthRepositoryImpl) GetUserByNotelp(ctx context.Context, notelp strin
 	return res, nil
 }
 
+// GetUserByEmail returns user data having the email from the user table
+func (alr *AuthRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (res *daos.User, err error) {
+	res = &daos.User{}
+	if err := alr.db.WithContext(ctx).Where("email = ?", email).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetUserById returns user data having the id from the user table
+func (alr *AuthRepositoryImpl) GetUserById(ctx context.Context, id uint) (res *daos.User, err error) {
+	res = &daos.User{}
+	if err := alr.db.WithContext(ctx).First(res, id).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // GetProvinceById returns province data having the id from the specified external API
 func (alr *AuthRepositoryImpl) GetProvinceById(ctx context.Context, provId string) (res *dto.ProvinceResp, err error) {
 	resp, err := http.Get(fmt.Sprintf(provinceCityDetailProvinceAPI, provId))
@@ -89,11 +111,19 @@ func (alr *AuthRepositoryImpl) CreateUser(ctx context.Context, data *daos.User)
 	return data.ID, nil
 }
 
-// CreateToko inserts the toko data to the toko table
+// CreateToko inserts the toko data to the toko table, deriving its unique slug from the nama
 func (alr *AuthRepositoryImpl) CreateToko(ctx context.Context, data *daos.Toko) (res uint, err error) {
-	result := alr.db.Create(data).WithContext(ctx)
-	if result.Error != nil {
-		return res, result.Error
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, &daos.Toko{}, daos.SlugTipeToko, data.NamaToko, 0)
+		if err != nil {
+			return err
+		}
+
+		data.Slug = slug
+		return tx.Create(data).Error
+	})
+	if err != nil {
+		return res, err
 	}
 
 	return res, nil
@@ -9,7 +9,7 @@ import (
 )
 
 type BookRepository interface {
-	GetAllBooks(ctx context.Context, params daos.FilterBook) (res []daos.Book, err error)
+	GetAllBooks(ctx context.Context, params daos.FilterBook) (res []daos.Book, total int64, err error)
 	GetBookByID(ctx context.Context, bookid string) (res daos.Book, err error)
 	CreateBook(ctx context.Context, data daos.Book) (res uint, err error)
 	UpdateBookByID(ctx context.Context, bookid string, data daos.Book) (res string, err error)
@@ -27,22 +27,28 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 	}
 }
 
-// GetAllBooks returns all book data from the book table
-func (alr *BookRepositoryImpl) GetAllBooks(ctx context.Context, params daos.FilterBook) (res []daos.Book, err error) {
-	db := alr.db
+// GetAllBooks returns the page of book data from the book table along with the total book data matching the filter
+func (alr *BookRepositoryImpl) GetAllBooks(ctx context.Context, params daos.FilterBook) (res []daos.Book, total int64, err error) {
+	db := alr.db.WithContext(ctx).Model(&daos.Book{})
 
+	title := fmt.Sprintf("%%%s%%", params.Title)
 	filter := map[string][]any{
-		"title like ? or description like ? or author like ?": {fmt.Sprint("%" + params.Title), "%ab ", "%ab"},
+		"title like ? or description like ? or author like ?": {title, title, title},
 	}
 
 	for key, val := range filter {
 		db = db.Where(key, val...)
 	}
+	db = db.Session(&gorm.Session{})
 
-	if err := db.Debug().WithContext(ctx).Limit(params.Limit).Offset(params.Offset).Find(&res).Error; err != nil {
-		return res, err
+	if err := db.Count(&total).Error; err != nil {
+		return res, 0, err
 	}
-	return res, nil
+
+	if err := db.Limit(params.Limit).Offset(params.Offset).Order(params.Order).Find(&res).Error; err != nil {
+		return res, 0, err
+	}
+	return res, total, nil
 }
 
 // GetBookByID returns book data having the id from the book table
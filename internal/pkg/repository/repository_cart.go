@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type CartRepository interface {
+	GetCartByUserId(ctx context.Context, userId uint) (res *daos.Cart, err error)
+	GetCartItemById(ctx context.Context, id string) (res *daos.CartItem, err error)
+	GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error)
+	CreateCartItem(ctx context.Context, data *daos.CartItem) (res uint, err error)
+	UpdateCartItem(ctx context.Context, prevData *daos.CartItem, data *daos.CartItem) (err error)
+	DeleteCartItem(ctx context.Context, data *daos.CartItem) (err error)
+}
+
+type CartRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewCartRepository returns the repository for the cart group path
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &CartRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetCartByUserId returns cart data having the userid from the cart table, creating an empty one if the user has none
+func (alr *CartRepositoryImpl) GetCartByUserId(ctx context.Context, userId uint) (res *daos.Cart, err error) {
+	res = &daos.Cart{}
+	if err := alr.db.WithContext(ctx).Where(daos.Cart{IdUser: userId}).FirstOrCreate(res).Error; err != nil {
+		return nil, err
+	}
+
+	tx := alr.db.WithContext(ctx).Model(res)
+	tx = tx.Preload("CartItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc, id asc")
+	})
+	tx = tx.Preload("CartItems.Produk").Preload("CartItems.Produk.Toko").Preload("CartItems.Produk.FotoProduks")
+	tx = tx.Preload("CartItems.Variant")
+	if err := tx.Where("id = ?", res.ID).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetCartItemById returns cartitem data having the id from the cartitem table
+func (alr *CartRepositoryImpl) GetCartItemById(ctx context.Context, id string) (res *daos.CartItem, err error) {
+	res = &daos.CartItem{}
+	if err := alr.db.WithContext(ctx).Preload("Produk").Preload("Variant").Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetProdukById returns produk data having the id from the produk table
+func (alr *CartRepositoryImpl) GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error) {
+	res = &daos.Produk{}
+	if err := alr.db.WithContext(ctx).Preload("Variants").Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateCartItem inserts the cartitem data to the cartitem table
+func (alr *CartRepositoryImpl) CreateCartItem(ctx context.Context, data *daos.CartItem) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// UpdateCartItem updates cartitem data on the cartitem table
+func (alr *CartRepositoryImpl) UpdateCartItem(ctx context.Context, prevData *daos.CartItem, data *daos.CartItem) (err error) {
+	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCartItem deletes cartitem data on the cartitem table
+func (alr *CartRepositoryImpl) DeleteCartItem(ctx context.Context, data *daos.CartItem) (err error) {
+	if err := alr.db.WithContext(ctx).Unscoped().Delete(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// clearCart deletes all cartitem data of the cart having the id on the cartitem table inside the given transaction
+func clearCart(tx *gorm.DB, idCart uint) (err error) {
+	return tx.Unscoped().Where("id_cart = ?", idCart).Delete(&daos.CartItem{}).Error
+}
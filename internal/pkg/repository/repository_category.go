@@ -9,7 +9,7 @@ import (
 )
 
 type CategoryRepository interface {
-	GetAllCategory(ctx context.Context) (res []*daos.Category, err error)
+	GetAllCategory(ctx context.Context, filter daos.FilterCategory) (res []*daos.Category, total int64, err error)
 	GetCategoryById(ctx context.Context, id string) (res *daos.Category, err error)
 	GetUserById(ctx context.Context, id string) (res *daos.User, err error)
 	CreateCategory(ctx context.Context, data *daos.Category) (res uint, err error)
@@ -28,12 +28,17 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	}
 }
 
-// GetAllCategory returns all cateory data from the category table
-func (alr *CategoryRepositoryImpl) GetAllCategory(ctx context.Context) (res []*daos.Category, err error) {
-	if err := alr.db.WithContext(ctx).Find(&res).Error; err != nil {
-		return nil, err
+// GetAllCategory returns the page of cateory data from the category table along with the total category data matching the filter
+func (alr *CategoryRepositoryImpl) GetAllCategory(ctx context.Context, filter daos.FilterCategory) (res []*daos.Category, total int64, err error) {
+	tx := alr.db.WithContext(ctx).Model(&daos.Category{}).Where("nama_category like ?", fmt.Sprintf("%%%s%%", filter.NamaCategory)).Session(&gorm.Session{})
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	return res, nil
+
+	if err := tx.Limit(filter.Limit).Offset(filter.Offset).Order(filter.Order).Find(&res).Error; err != nil {
+		return nil, 0, err
+	}
+	return res, total, nil
 }
 
 // GetCategoryById returns cateory data having the id from the category table
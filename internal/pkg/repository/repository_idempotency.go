@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository interface {
+	GetIdempotencyKeyByScope(ctx context.Context, scope string) (res *daos.IdempotencyKey, err error)
+	CreateIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (res uint, err error)
+	UpdateIdempotencyKey(ctx context.Context, prevData *daos.IdempotencyKey, data *daos.IdempotencyKey) (err error)
+	DeleteIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (err error)
+}
+
+type IdempotencyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository returns the repository for the idempotency middleware
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetIdempotencyKeyByScope returns idempotencykey data having the scope from the idempotencykey table
+func (alr *IdempotencyRepositoryImpl) GetIdempotencyKeyByScope(ctx context.Context, scope string) (res *daos.IdempotencyKey, err error) {
+	res = &daos.IdempotencyKey{}
+	if err := alr.db.WithContext(ctx).Where("scope = ?", scope).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateIdempotencyKey inserts the idempotencykey data to the idempotencykey table, failing when the scope is already taken
+func (alr *IdempotencyRepositoryImpl) CreateIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// UpdateIdempotencyKey updates idempotencykey data on the idempotencykey table
+func (alr *IdempotencyRepositoryImpl) UpdateIdempotencyKey(ctx context.Context, prevData *daos.IdempotencyKey, data *daos.IdempotencyKey) (err error) {
+	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes idempotencykey data on the idempotencykey table so the scope can be used again
+func (alr *IdempotencyRepositoryImpl) DeleteIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (err error) {
+	if err := alr.db.WithContext(ctx).Unscoped().Delete(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlErrDuplicateEntry is the mysql error number of ER_DUP_ENTRY
+const mysqlErrDuplicateEntry = 1062
+
+type PaymentRepository interface {
+	GetPaymentByReference(ctx context.Context, reference string) (res *daos.Payment, err error)
+	ApplyPaymentEvent(ctx context.Context, data *daos.Payment, status string, event *daos.PaymentEvent, history *daos.TrxStatusHistory, release bool) (err error)
+}
+
+var (
+	ErrPaymentEventProcessed = errors.New("payment event telah diproses")
+	ErrPaymentStatusChanged  = errors.New("status payment telah berubah")
+)
+
+type PaymentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository returns the repository for the payment group path
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &PaymentRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetPaymentByReference returns payment data having the reference from the payment table
+func (alr *PaymentRepositoryImpl) GetPaymentByReference(ctx context.Context, reference string) (res *daos.Payment, err error) {
+	res = &daos.Payment{}
+	tx := alr.db.WithContext(ctx).Preload("Trx").Preload("Trx.DetailTrxs").Preload("Trx.DetailTrxs.LogProduk")
+	if err := tx.Where("reference = ?", reference).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ApplyPaymentEvent records the webhook event, settles the payment to the status, and optionally moves its trx using the history data in one transaction.
+// An event recorded before fails with ErrPaymentEventProcessed. The release flag gives back the stok and voucher usage reserved by the trx like UpdateTrxStatus does
+func (alr *PaymentRepositoryImpl) ApplyPaymentEvent(ctx context.Context, data *daos.Payment, status string, event *daos.PaymentEvent, history *daos.TrxStatusHistory, release bool) (err error) {
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the unique index on the provider and event id settles concurrent deliveries of the same event,
+		// the later ones waiting on the first insertion and failing once it commits
+		if err := tx.Create(event).Error; err != nil {
+			if isDuplicateKeyError(err) {
+				return ErrPaymentEventProcessed
+			}
+			return err
+		}
+
+		if status != data.Status {
+			updates := map[string]interface{}{"status": status}
+			if status == daos.PaymentStatusPaid {
+				updates["paid_at"] = time.Now()
+			}
+
+			result := tx.Model(&daos.Payment{}).Where("id = ? AND status = ?", data.ID, data.Status).Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return ErrPaymentStatusChanged
+			}
+		}
+
+		if history != nil {
+			return updateTrxStatus(tx, data.Trx, history, release)
+		}
+
+		return nil
+	})
+}
+
+// isDuplicateKeyError checks whether the error is the mysql error of an insertion violating a unique index
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/testfixture"
+)
+
+const testDeliveries = 5
+
+func TestApplyPaymentEventDuplicateDeliveries(t *testing.T) {
+	db := testfixture.OpenDB(t, &daos.Payment{}, &daos.PaymentEvent{})
+	repo := NewPaymentRepository(db)
+
+	payment := &daos.Payment{
+		Provider:  "mock",
+		Reference: testfixture.Name("mock"),
+		Amount:    10000,
+		Status:    daos.PaymentStatusPending,
+	}
+	if err := db.Create(payment).Error; err != nil {
+		t.Fatalf("create payment: %s", err)
+	}
+
+	eventId := testfixture.Name("evt")
+
+	var wg sync.WaitGroup
+	errs := make([]error, testDeliveries)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			data := *payment
+			errs[i] = repo.ApplyPaymentEvent(context.Background(), &data, daos.PaymentStatusPaid, &daos.PaymentEvent{
+				Provider:  payment.Provider,
+				EventId:   eventId,
+				Reference: payment.Reference,
+				Status:    daos.PaymentStatusPaid,
+			}, nil, false)
+		}(i)
+	}
+	wg.Wait()
+
+	applied := 0
+	for _, err := range errs {
+		if err == nil {
+			applied++
+			continue
+		}
+
+		if !errors.Is(err, ErrPaymentEventProcessed) {
+			t.Errorf("ApplyPaymentEvent error = %v, want ErrPaymentEventProcessed", err)
+		}
+	}
+
+	if applied != 1 {
+		t.Errorf("%d deliveries applied, want 1", applied)
+	}
+
+	res := &daos.Payment{}
+	if err := db.First(res, payment.ID).Error; err != nil {
+		t.Fatalf("read payment: %s", err)
+	}
+	if res.Status != daos.PaymentStatusPaid || res.PaidAt == nil {
+		t.Errorf("payment status = %s, paid at = %v, want paid", res.Status, res.PaidAt)
+	}
+
+	var events int64
+	if err := db.Model(&daos.PaymentEvent{}).Where("provider = ? AND event_id = ?", payment.Provider, eventId).Count(&events).Error; err != nil {
+		t.Fatalf("count payment event: %s", err)
+	}
+	if events != 1 {
+		t.Errorf("%d payment event recorded, want 1", events)
+	}
+}
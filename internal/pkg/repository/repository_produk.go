@@ -9,14 +9,24 @@ import (
 )
 
 type ProdukRepository interface {
-	GetAllProduks(ctx context.Context, filter *daos.FilterProduk) (res []*daos.Produk, err error)
+	GetAllProduks(ctx context.Context, filter *daos.FilterProduk) (res []*daos.Produk, total int64, err error)
 	GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error)
+	GetProdukBySlug(ctx context.Context, slug string) (res *daos.Produk, err error)
+	GetProdukSlugHistory(ctx context.Context, slug string) (res *daos.SlugHistory, err error)
+	GetProduksByIds(ctx context.Context, ids []uint) (res []*daos.Produk, err error)
 	GetUserById(ctx context.Context, id string) (res *daos.User, err error)
 	CreateProduk(ctx context.Context, data *daos.Produk) (res uint, err error)
 	CreateFotoProduk(ctx context.Context, data *daos.FotoProduk) (res uint, err error)
 	UpdateProduk(ctx context.Context, prevData *daos.Produk, data *daos.Produk) (err error)
 	DeleteProduk(ctx context.Context, data *daos.Produk) (err error)
 	DeleteFotoProduk(ctx context.Context, data *daos.FotoProduk) (err error)
+	GetProdukVariantById(ctx context.Context, produkId, id string) (res *daos.ProdukVariant, err error)
+	GetProdukVariantBySku(ctx context.Context, sku string) (res *daos.ProdukVariant, err error)
+	CreateProdukVariant(ctx context.Context, data *daos.ProdukVariant) (res uint, err error)
+	CreateFotoProdukVariant(ctx context.Context, data *daos.FotoProdukVariant) (res uint, err error)
+	UpdateProdukVariant(ctx context.Context, prevData *daos.ProdukVariant, data *daos.ProdukVariant, columns []string) (err error)
+	DeleteProdukVariant(ctx context.Context, data *daos.ProdukVariant) (err error)
+	DeleteFotoProdukVariant(ctx context.Context, data *daos.FotoProdukVariant) (err error)
 }
 
 type ProdukRepositoryImpl struct {
@@ -30,38 +40,100 @@ func NewProdukRepository(db *gorm.DB) ProdukRepository {
 	}
 }
 
-// GetAllProduks returns all produk data from the produk table
-func (alr *ProdukRepositoryImpl) GetAllProduks(ctx context.Context, filter *daos.FilterProduk) (res []*daos.Produk, err error) {
-	tx := alr.db.Where("nama_produk like ?", fmt.Sprintf("%%%s%%", filter.NamaProduk))
-	if filter.MaxHarga != 0 && filter.MaxHarga >= filter.MinHarga {
-		tx = tx.Where("harga_konsumen BETWEEN ? AND ?", filter.MinHarga, filter.MaxHarga)
-		tx = tx.Where("harga_reseller BETWEEN ? AND ?", filter.MinHarga, filter.MaxHarga)
+// GetAllProduks returns the page of produk data from the produk table along with the total produk data matching the filter,
+// the page continued by the cursor starts right after the produk the cursor points at
+func (alr *ProdukRepositoryImpl) GetAllProduks(ctx context.Context, filter *daos.FilterProduk) (res []*daos.Produk, total int64, err error) {
+	scope := func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("nama_produk like ?", fmt.Sprintf("%%%s%%", filter.NamaProduk))
+		if filter.MinHarga > 0 {
+			tx = tx.Where("harga_konsumen >= ?", filter.MinHarga)
+		}
+
+		if filter.MaxHarga > 0 {
+			tx = tx.Where("harga_konsumen <= ?", filter.MaxHarga)
+		}
+
+		if filter.CategoryId > 0 {
+			tx = tx.Where("id_category = ?", filter.CategoryId)
+		}
+
+		if filter.TokoId > 0 {
+			tx = tx.Where("id_Toko = ?", filter.TokoId)
+		}
+		return tx
 	}
 
-	if filter.CategoryId > 0 {
-		tx = tx.Where("id_category = ?", filter.CategoryId)
+	if err := alr.db.WithContext(ctx).Model(&daos.Produk{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	if filter.TokoId > 0 {
-		tx = tx.Where("id_Toko = ?", filter.TokoId)
+	tx := alr.db.WithContext(ctx).Scopes(scope).Limit(filter.Limit).Offset(filter.Offset).Order(filter.Order)
+	if filter.CursorCreatedAt != nil {
+		tx = tx.Where("(created_at, id) < (?, ?)", filter.CursorCreatedAt, filter.CursorId)
 	}
-	tx = tx.WithContext(ctx).Limit(filter.Limit).Offset(filter.Offset)
-	tx = tx.Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category")
+	tx = tx.Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category").Preload("RatingCounts", ratingCountsBy("id_produk"))
+	tx = tx.Preload("Variants").Preload("Variants.FotoProdukVariants")
 	if err := tx.Find(&res).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return res, nil
+	return res, total, nil
 }
 
 // GetProdukById returns produk data having the id from the produk table
 func (alr *ProdukRepositoryImpl) GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error) {
 	res = &daos.Produk{}
-	if err := alr.db.WithContext(ctx).Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category").Where("id = ? ", id).First(res).Error; err != nil {
+	tx := alr.db.WithContext(ctx).Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category").Preload("RatingCounts", ratingCountsBy("id_produk"))
+	tx = tx.Preload("Variants").Preload("Variants.FotoProdukVariants")
+	if err := tx.Where("id = ? ", id).First(res).Error; err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
+// GetProdukBySlug returns produk data having the slug from the produk table
+func (alr *ProdukRepositoryImpl) GetProdukBySlug(ctx context.Context, slug string) (res *daos.Produk, err error) {
+	res = &daos.Produk{}
+	tx := alr.db.WithContext(ctx).Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category").Preload("RatingCounts", ratingCountsBy("id_produk"))
+	tx = tx.Preload("Variants").Preload("Variants.FotoProdukVariants")
+	if err := tx.Where("slug = ? ", slug).First(res).Error; err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetProdukSlugHistory returns the slughistory data of the previous slug of a produk from the slughistory table
+func (alr *ProdukRepositoryImpl) GetProdukSlugHistory(ctx context.Context, slug string) (res *daos.SlugHistory, err error) {
+	return getSlugHistory(alr.db.WithContext(ctx), daos.SlugTipeProduk, slug)
+}
+
+// GetProduksByIds returns produk data having the ids from the produk table, keeping the order of the ids
+func (alr *ProdukRepositoryImpl) GetProduksByIds(ctx context.Context, ids []uint) (res []*daos.Produk, err error) {
+	if len(ids) == 0 {
+		return []*daos.Produk{}, nil
+	}
+
+	produks := []*daos.Produk{}
+	tx := alr.db.WithContext(ctx).Model(daos.Produk{}).Preload("FotoProduks").Preload("Toko").Preload("Category").Preload("RatingCounts", ratingCountsBy("id_produk"))
+	tx = tx.Preload("Variants").Preload("Variants.FotoProdukVariants")
+	if err := tx.Where("id IN ?", ids).Find(&produks).Error; err != nil {
+		return nil, err
+	}
+
+	byId := map[uint]*daos.Produk{}
+	for _, v := range produks {
+		byId[v.ID] = v
+	}
+
+	res = []*daos.Produk{}
+	for _, id := range ids {
+		if v, ok := byId[id]; ok {
+			res = append(res, v)
+		}
+	}
+
+	return res, nil
+}
+
 // GetUserById returns user data having the id from the user table
 func (alr *ProdukRepositoryImpl) GetUserById(ctx context.Context, id string) (res *daos.User, err error) {
 	res = &daos.User{}
@@ -71,11 +143,19 @@ func (alr *ProdukRepositoryImpl) GetUserById(ctx context.Context, id string) (re
 	return res, nil
 }
 
-// CreateProduk inserts the produk data to the produk table
+// CreateProduk inserts the produk data to the produk table, deriving its unique slug from the nama
 func (alr *ProdukRepositoryImpl) CreateProduk(ctx context.Context, data *daos.Produk) (res uint, err error) {
-	result := alr.db.Create(data).WithContext(ctx)
-	if result.Error != nil {
-		return res, result.Error
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, &daos.Produk{}, daos.SlugTipeProduk, data.NamaProduk, 0)
+		if err != nil {
+			return err
+		}
+
+		data.Slug = slug
+		return tx.Create(data).Error
+	})
+	if err != nil {
+		return res, err
 	}
 
 	return data.ID, nil
@@ -91,13 +171,19 @@ func (alr *ProdukRepositoryImpl) CreateFotoProduk(ctx context.Context, data *dao
 	return data.ID, nil
 }
 
-// UpdateProduk updates produk data on the produk table
+// UpdateProduk updates produk data on the produk table, renaming the slug when the nama changes
 func (alr *ProdukRepositoryImpl) UpdateProduk(ctx context.Context, prevData *daos.Produk, data *daos.Produk) (err error) {
-	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
-		return err
-	}
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if data.NamaProduk != "" {
+			slug, err := renameSlug(tx, &daos.Produk{}, daos.SlugTipeProduk, prevData.Slug, data.NamaProduk, prevData.ID)
+			if err != nil {
+				return err
+			}
+			data.Slug = slug
+		}
 
-	return nil
+		return tx.Where("id = ?", prevData.ID).Updates(data).Error
+	})
 }
 
 // DeleteProduk deletes produk data having the id on the produk table
@@ -117,3 +203,73 @@ func (alr *ProdukRepositoryImpl) DeleteFotoProduk(ctx context.Context, data *dao
 
 	return nil
 }
+
+// GetProdukVariantById returns produkvariant data having the id and the produkid from the produkvariant table
+func (alr *ProdukRepositoryImpl) GetProdukVariantById(ctx context.Context, produkId, id string) (res *daos.ProdukVariant, err error) {
+	res = &daos.ProdukVariant{}
+	if err := alr.db.WithContext(ctx).Preload("FotoProdukVariants").Where("id = ? AND id_produk = ?", id, produkId).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetProdukVariantBySku returns produkvariant data having the sku from the produkvariant table, including the deleted ones
+func (alr *ProdukRepositoryImpl) GetProdukVariantBySku(ctx context.Context, sku string) (res *daos.ProdukVariant, err error) {
+	res = &daos.ProdukVariant{}
+	if err := alr.db.WithContext(ctx).Unscoped().Where("sku = ?", sku).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateProdukVariant inserts the produkvariant data to the produkvariant table
+func (alr *ProdukRepositoryImpl) CreateProdukVariant(ctx context.Context, data *daos.ProdukVariant) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// CreateFotoProdukVariant inserts the fotoprodukvariant data to the fotoprodukvariant table
+func (alr *ProdukRepositoryImpl) CreateFotoProdukVariant(ctx context.Context, data *daos.FotoProdukVariant) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// UpdateProdukVariant updates the columns of produkvariant data on the produkvariant table.
+// Only the listed columns are written, zero values included, so a stok can be set to 0
+func (alr *ProdukRepositoryImpl) UpdateProdukVariant(ctx context.Context, prevData *daos.ProdukVariant, data *daos.ProdukVariant, columns []string) (err error) {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	if err := alr.db.WithContext(ctx).Model(&daos.ProdukVariant{}).Where("id = ?", prevData.ID).Select(columns).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteProdukVariant deletes produkvariant data having the id on the produkvariant table
+func (alr *ProdukRepositoryImpl) DeleteProdukVariant(ctx context.Context, data *daos.ProdukVariant) (err error) {
+	if err := alr.db.WithContext(ctx).Delete(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteFotoProdukVariant deletes fotoprodukvariant data having the id on the fotoprodukvariant table
+func (alr *ProdukRepositoryImpl) DeleteFotoProdukVariant(ctx context.Context, data *daos.FotoProdukVariant) (err error) {
+	if err := alr.db.WithContext(ctx).Delete(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
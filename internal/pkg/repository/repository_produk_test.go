@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/testfixture"
+)
+
+func TestUpdateProdukVariantWritesTheColumnsOnly(t *testing.T) {
+	db := testfixture.OpenDB(t, &daos.Produk{}, &daos.ProdukVariant{})
+	repo := NewProdukRepository(db)
+
+	_, variant := testfixture.CreateProduk(t, db, 0, 5)
+
+	// a sold out variant gets its stok set to 0
+	if err := repo.UpdateProdukVariant(context.Background(), variant, &daos.ProdukVariant{Stok: 0}, []string{"stok"}); err != nil {
+		t.Fatalf("UpdateProdukVariant error = %v", err)
+	}
+
+	res := &daos.ProdukVariant{}
+	if err := db.First(res, variant.ID).Error; err != nil {
+		t.Fatalf("read variant: %s", err)
+	}
+	if res.Stok != 0 || res.Sku != variant.Sku {
+		t.Errorf("variant stok = %d, sku = %s, want 0 and %s", res.Stok, res.Sku, variant.Sku)
+	}
+
+	// the columns left out keep their values
+	sku := testfixture.Name("sku")
+	if err := repo.UpdateProdukVariant(context.Background(), variant, &daos.ProdukVariant{Sku: sku, Stok: 7}, []string{"sku"}); err != nil {
+		t.Fatalf("UpdateProdukVariant error = %v", err)
+	}
+
+	res = &daos.ProdukVariant{}
+	if err := db.First(res, variant.ID).Error; err != nil {
+		t.Fatalf("read variant: %s", err)
+	}
+	if res.Stok != 0 || res.Sku != sku {
+		t.Errorf("variant stok = %d, sku = %s, want 0 and %s", res.Stok, res.Sku, sku)
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository interface {
+	GetReviewsByProdukId(ctx context.Context, filter *daos.FilterReview) (res []*daos.Review, total int64, err error)
+	GetRatingCountsByProdukId(ctx context.Context, produkId uint) (res []*daos.RatingCount, err error)
+	GetReviewById(ctx context.Context, id string) (res *daos.Review, err error)
+	GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error)
+	GetDetailTrxById(ctx context.Context, id uint) (res *daos.DetailTrx, err error)
+	GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error)
+	CreateReview(ctx context.Context, data *daos.Review) (res uint, err error)
+	UpdateReview(ctx context.Context, prevData *daos.Review, data *daos.Review) (err error)
+}
+
+var ErrReviewExists = errors.New("barang pada detail trx ini sudah diulas")
+
+type ReviewRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository returns the repository for the review group path
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &ReviewRepositoryImpl{
+		db: db,
+	}
+}
+
+// ratingCountsBy returns the preload conditions counting the reviews of each rating grouped by the column, either id_produk or id_toko
+func ratingCountsBy(column string) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(column + ", rating, count(*) AS jumlah").Where("deleted_at IS NULL").Group(column + ", rating")
+	}
+}
+
+// GetReviewsByProdukId returns the page of review data of the produk from the review table along with the total review data matching the filter
+func (alr *ReviewRepositoryImpl) GetReviewsByProdukId(ctx context.Context, filter *daos.FilterReview) (res []*daos.Review, total int64, err error) {
+	tx := alr.db.WithContext(ctx).Model(&daos.Review{}).Where("id_produk = ?", filter.IdProduk)
+	if filter.Rating > 0 {
+		tx = tx.Where("rating = ?", filter.Rating)
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	tx = tx.Preload("FotoReviews").Preload("User").Limit(filter.Limit).Offset(filter.Offset).Order(filter.Order)
+	if err := tx.Find(&res).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
+// GetRatingCountsByProdukId returns the number of reviews of each rating given to the produk from the review table
+func (alr *ReviewRepositoryImpl) GetRatingCountsByProdukId(ctx context.Context, produkId uint) (res []*daos.RatingCount, err error) {
+	tx := alr.db.WithContext(ctx).Scopes(ratingCountsBy("id_produk")).Where("id_produk = ?", produkId)
+	if err := tx.Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetReviewById returns review data having the id from the review table
+func (alr *ReviewRepositoryImpl) GetReviewById(ctx context.Context, id string) (res *daos.Review, err error) {
+	res = &daos.Review{}
+	if err := alr.db.WithContext(ctx).Preload("FotoReviews").Preload("User").Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetProdukById returns produk data having the id from the produk table
+func (alr *ReviewRepositoryImpl) GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error) {
+	res = &daos.Produk{}
+	if err := alr.db.WithContext(ctx).Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetDetailTrxById returns detailtrx data having the id along with its trx and logproduk from the detailtrx table
+func (alr *ReviewRepositoryImpl) GetDetailTrxById(ctx context.Context, id uint) (res *daos.DetailTrx, err error) {
+	res = &daos.DetailTrx{}
+	if err := alr.db.WithContext(ctx).Preload("Trx").Preload("LogProduk").Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetTokoByUserId returns toko data having the userid from the toko table
+func (alr *ReviewRepositoryImpl) GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error) {
+	res = &daos.Toko{}
+	if err := alr.db.WithContext(ctx).Where("id_user = ?", userId).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateReview inserts the review data along with its fotoreview data to the review and fotoreview tables in one transaction,
+// a detailtrx can only be reviewed once
+func (alr *ReviewRepositoryImpl) CreateReview(ctx context.Context, data *daos.Review) (res uint, err error) {
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&daos.Review{}).Unscoped().Where("id_detail_trx = ?", data.IdDetailTrx).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrReviewExists
+		}
+
+		// a concurrent review of the same detailtrx is refused by the unique index
+		if err := tx.Create(data).Error; err != nil {
+			if isDuplicateKeyError(err) {
+				return ErrReviewExists
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// UpdateReview updates the review data on the review table
+func (alr *ReviewRepositoryImpl) UpdateReview(ctx context.Context, prevData *daos.Review, data *daos.Review) (err error) {
+	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
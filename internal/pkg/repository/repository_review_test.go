@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/testfixture"
+)
+
+func TestCreateReviewWithFotoReviews(t *testing.T) {
+	db := testfixture.OpenDB(t, &daos.Review{}, &daos.FotoReview{})
+	repo := NewReviewRepository(db)
+
+	idDetailTrx := testfixture.Id()
+	newReview := func(urls ...string) *daos.Review {
+		review := &daos.Review{
+			IdDetailTrx: idDetailTrx,
+			IdUser:      1,
+			IdProduk:    1,
+			IdToko:      1,
+			Rating:      5,
+			Ulasan:      "mantap",
+		}
+		for _, v := range urls {
+			review.FotoReviews = append(review.FotoReviews, &daos.FotoReview{Url: v})
+		}
+
+		return review
+	}
+
+	fotoReviews := func() (urls []string) {
+		if err := db.Model(&daos.FotoReview{}).
+			Joins("JOIN reviews ON reviews.id = foto_reviews.id_review").
+			Where("reviews.id_detail_trx = ?", idDetailTrx).
+			Order("foto_reviews.id").
+			Pluck("foto_reviews.url", &urls).Error; err != nil {
+			t.Fatalf("read fotoreview: %s", err)
+		}
+
+		return urls
+	}
+
+	res, err := repo.CreateReview(context.Background(), newReview("/static/a.jpg", "/static/b.jpg"))
+	if err != nil || res == 0 {
+		t.Fatalf("CreateReview = %d, %v", res, err)
+	}
+	if urls := fotoReviews(); len(urls) != 2 || urls[0] != "/static/a.jpg" || urls[1] != "/static/b.jpg" {
+		t.Errorf("fotoreview urls = %v, want [/static/a.jpg /static/b.jpg]", urls)
+	}
+
+	// a second review of the detailtrx is refused and inserts none of its photos
+	_, err = repo.CreateReview(context.Background(), newReview("/static/c.jpg"))
+	if !errors.Is(err, ErrReviewExists) {
+		t.Errorf("second CreateReview error = %v, want ErrReviewExists", err)
+	}
+
+	var count int64
+	if err := db.Model(&daos.FotoReview{}).Where("url = ?", "/static/c.jpg").Count(&count).Error; err != nil {
+		t.Fatalf("count fotoreview: %s", err)
+	}
+	if count != 0 {
+		t.Errorf("%d fotoreview of the refused review inserted, want 0", count)
+	}
+}
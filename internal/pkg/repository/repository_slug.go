@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/slug"
+
+	"gorm.io/gorm"
+)
+
+// baseSlug returns the slug of the nama, falling back to the tipe when the nama has no letters nor digits
+func baseSlug(tipe, nama string) string {
+	if res := slug.Make(nama); res != "" {
+		return res
+	}
+
+	return tipe
+}
+
+// uniqueSlug returns the slug of the nama suffixed so it is taken neither by the other data of the model, including the deleted ones,
+// nor by their previous slugs on the slughistory table
+func uniqueSlug(tx *gorm.DB, model interface{}, tipe, nama string, id uint) (string, error) {
+	base := baseSlug(tipe, nama)
+	pattern := base + "-%"
+
+	slugs := []string{}
+	if err := tx.Model(model).Unscoped().Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, pattern, id).Pluck("slug", &slugs).Error; err != nil {
+		return "", err
+	}
+
+	prevSlugs := []string{}
+	if err := tx.Model(&daos.SlugHistory{}).Where("tipe = ? AND (slug = ? OR slug LIKE ?) AND id_ref <> ?", tipe, base, pattern, id).Pluck("slug", &prevSlugs).Error; err != nil {
+		return "", err
+	}
+
+	taken := map[string]struct{}{}
+	for _, v := range append(slugs, prevSlugs...) {
+		taken[v] = struct{}{}
+	}
+
+	return slug.Unique(base, taken), nil
+}
+
+// renameSlug returns the slug of the new nama of the data having the id, keeping the previous slug on the slughistory table
+// so it keeps resolving. The previous slug is kept as is when the new nama still yields it
+func renameSlug(tx *gorm.DB, model interface{}, tipe, prevSlug, nama string, id uint) (string, error) {
+	if prevSlug != "" && slug.Matches(prevSlug, baseSlug(tipe, nama)) {
+		return prevSlug, nil
+	}
+
+	res, err := uniqueSlug(tx, model, tipe, nama, id)
+	if err != nil {
+		return "", err
+	}
+
+	// the data taking back one of its previous slugs no longer needs it on the slughistory table
+	if err := tx.Where("tipe = ? AND slug = ? AND id_ref = ?", tipe, res, id).Delete(&daos.SlugHistory{}).Error; err != nil {
+		return "", err
+	}
+
+	if prevSlug != "" {
+		if err := tx.Create(&daos.SlugHistory{Tipe: tipe, Slug: prevSlug, IdRef: id}).Error; err != nil {
+			return "", err
+		}
+	}
+
+	return res, nil
+}
+
+// getSlugHistory returns the slughistory data of the previous slug of the tipe from the slughistory table
+func getSlugHistory(tx *gorm.DB, tipe, prevSlug string) (res *daos.SlugHistory, err error) {
+	res = &daos.SlugHistory{}
+	if err := tx.Where("tipe = ? AND slug = ?", tipe, prevSlug).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
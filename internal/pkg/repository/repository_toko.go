@@ -9,10 +9,15 @@ import (
 )
 
 type TokoRepository interface {
-	GetAllTokos(ctx context.Context, queries daos.FilterToko) (res []*daos.Toko, err error)
+	GetAllTokos(ctx context.Context, queries daos.FilterToko) (res []*daos.Toko, total int64, err error)
 	GetTokoById(ctx context.Context, id string) (res *daos.Toko, err error)
+	GetTokoBySlug(ctx context.Context, slug string) (res *daos.Toko, err error)
+	GetTokoSlugHistory(ctx context.Context, slug string) (res *daos.SlugHistory, err error)
 	GetTokoByUserID(ctx context.Context, userId string) (res *daos.Toko, err error)
+	GetDetailTrxsByTokoId(ctx context.Context, filter *daos.FilterDetailTrx) (res []*daos.DetailTrx, err error)
 	UpdateToko(ctx context.Context, prevData *daos.Toko, data *daos.Toko) (err error)
+	CreateTokoFollower(ctx context.Context, data *daos.TokoFollower) (res uint, err error)
+	DeleteTokoFollower(ctx context.Context, userId, tokoId uint) (err error)
 }
 
 type TokoRepositoryImpl struct {
@@ -26,34 +31,121 @@ func NewTokoRepository(db *gorm.DB) TokoRepository {
 	}
 }
 
-// GetAllTokos returns all toko data from the toko table
-func (alr *TokoRepositoryImpl) GetAllTokos(ctx context.Context, queries daos.FilterToko) (res []*daos.Toko, err error) {
-	if err := alr.db.Where("nama_toko like ?", fmt.Sprintf("%%%s%%", queries.NamaToko)).WithContext(ctx).Limit(queries.Limit).Offset(queries.Offset).Find(&res).Error; err != nil {
-		return res, err
+// followerCounts is the preload condition counting the users following each toko
+func followerCounts(tx *gorm.DB) *gorm.DB {
+	return tx.Select("id_toko, count(*) AS jumlah").Group("id_toko")
+}
+
+// GetAllTokos returns the page of toko data from the toko table along with the total toko data matching the filter
+func (alr *TokoRepositoryImpl) GetAllTokos(ctx context.Context, queries daos.FilterToko) (res []*daos.Toko, total int64, err error) {
+	tx := alr.db.WithContext(ctx).Model(&daos.Toko{}).Where("nama_toko like ?", fmt.Sprintf("%%%s%%", queries.NamaToko)).Session(&gorm.Session{})
+	if err := tx.Count(&total).Error; err != nil {
+		return res, 0, err
 	}
-	return res, nil
+
+	if err := tx.Limit(queries.Limit).Offset(queries.Offset).Order(queries.Order).Preload("RatingCounts", ratingCountsBy("id_toko")).Preload("FollowerCounts", followerCounts).Find(&res).Error; err != nil {
+		return res, 0, err
+	}
+	return res, total, nil
 }
 
 // GetTokoById returns toko data having the id from the toko table
 func (alr *TokoRepositoryImpl) GetTokoById(ctx context.Context, id string) (res *daos.Toko, err error) {
-	if err := alr.db.Where("id = ? ", id).First(&res).WithContext(ctx).Error; err != nil {
+	if err := alr.db.Where("id = ? ", id).Preload("RatingCounts", ratingCountsBy("id_toko")).Preload("FollowerCounts", followerCounts).First(&res).WithContext(ctx).Error; err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetTokoBySlug returns toko data having the slug from the toko table
+func (alr *TokoRepositoryImpl) GetTokoBySlug(ctx context.Context, slug string) (res *daos.Toko, err error) {
+	if err := alr.db.WithContext(ctx).Where("slug = ? ", slug).Preload("RatingCounts", ratingCountsBy("id_toko")).Preload("FollowerCounts", followerCounts).First(&res).Error; err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
+// GetTokoSlugHistory returns the slughistory data of the previous slug of a toko from the slughistory table
+func (alr *TokoRepositoryImpl) GetTokoSlugHistory(ctx context.Context, slug string) (res *daos.SlugHistory, err error) {
+	return getSlugHistory(alr.db.WithContext(ctx), daos.SlugTipeToko, slug)
+}
+
 // GetTokoByUserID returns toko data having the userid from the toko table
 func (alr *TokoRepositoryImpl) GetTokoByUserID(ctx context.Context, userId string) (res *daos.Toko, err error) {
-	if err := alr.db.Where("id_user = ? ", userId).First(&res).WithContext(ctx).Error; err != nil {
+	if err := alr.db.Where("id_user = ? ", userId).Preload("RatingCounts", ratingCountsBy("id_toko")).Preload("FollowerCounts", followerCounts).First(&res).WithContext(ctx).Error; err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
-// UpdateToko updates toko data on the toko table
+// GetDetailTrxsByTokoId returns the detailtrx data sold by the toko from the detailtrx table
+func (alr *TokoRepositoryImpl) GetDetailTrxsByTokoId(ctx context.Context, filter *daos.FilterDetailTrx) (res []*daos.DetailTrx, err error) {
+	tx := alr.db.WithContext(ctx).Model(&daos.DetailTrx{}).Joins("Trx")
+	tx = tx.Where("detail_trxes.id_toko = ?", filter.IdToko)
+	if filter.Status != "" {
+		// the status of the pengiriman of the toko, which is packed and shipped apart from the other tokos of the trx
+		tx = tx.Where("COALESCE((?), `Trx`.`status`) = ?", alr.db.Model(&daos.TrxPengiriman{}).Select("status").
+			Where("trx_pengiriman.id_trx = detail_trxes.id_trx AND trx_pengiriman.id_toko = detail_trxes.id_toko").Limit(1), filter.Status)
+	}
+
+	if filter.KodeInvoice != "" {
+		tx = tx.Where("`Trx`.`kode_invoice` like ?", fmt.Sprintf("%%%s%%", filter.KodeInvoice))
+	}
+
+	if !filter.TanggalMulai.IsZero() {
+		tx = tx.Where("`Trx`.`created_at` >= ?", filter.TanggalMulai)
+	}
+
+	if !filter.TanggalSelesai.IsZero() {
+		tx = tx.Where("`Trx`.`created_at` < ?", filter.TanggalSelesai)
+	}
+
+	tx = tx.Preload("Trx.Alamat").Preload("Trx.Pengirimans", "id_toko = ?", filter.IdToko).Preload("LogProduk")
+	tx = tx.Preload("LogProduk.Produk", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+	tx = tx.Preload("LogProduk.Produk.FotoProduks").Preload("LogProduk.Toko").Preload("LogProduk.Category")
+	tx = tx.Order("detail_trxes.created_at desc, detail_trxes.id desc").Limit(filter.Limit).Offset(filter.Offset)
+	if err := tx.Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateToko updates toko data on the toko table, renaming the slug when the nama changes
 func (alr *TokoRepositoryImpl) UpdateToko(ctx context.Context, prevData *daos.Toko, data *daos.Toko) (err error) {
-	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
-		return err
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if data.NamaToko != "" {
+			slug, err := renameSlug(tx, &daos.Toko{}, daos.SlugTipeToko, prevData.Slug, data.NamaToko, prevData.ID)
+			if err != nil {
+				return err
+			}
+			data.Slug = slug
+		}
+
+		return tx.Where("id = ?", prevData.ID).Updates(data).Error
+	})
+}
+
+// CreateTokoFollower inserts the tokofollower data to the tokofollower table, following a toko already followed keeps the existing data
+func (alr *TokoRepositoryImpl) CreateTokoFollower(ctx context.Context, data *daos.TokoFollower) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Where("id_user = ? AND id_toko = ?", data.IdUser, data.IdToko).FirstOrCreate(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// DeleteTokoFollower deletes the tokofollower data of the user following the toko on the tokofollower table
+func (alr *TokoRepositoryImpl) DeleteTokoFollower(ctx context.Context, userId, tokoId uint) (err error) {
+	result := alr.db.WithContext(ctx).Where("id_user = ? AND id_toko = ?", userId, tokoId).Delete(&daos.TokoFollower{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 
 	return nil
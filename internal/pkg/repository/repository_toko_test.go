@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/testfixture"
+)
+
+func TestGetDetailTrxsByTokoIdOfTheTokoOnly(t *testing.T) {
+	db := testfixture.OpenDB(t,
+		&daos.Produk{},
+		&daos.ProdukVariant{},
+		&daos.FotoProduk{},
+		&daos.LogProduk{},
+		&daos.Toko{},
+		&daos.Category{},
+		&daos.Alamat{},
+		&daos.Trx{},
+		&daos.DetailTrx{},
+		&daos.InvoiceSequence{},
+		&daos.TrxPengiriman{},
+	)
+	trxRepo := NewTrxRepository(db)
+	repo := NewTokoRepository(db)
+
+	idToko, idTokoLain := testfixture.Id(), testfixture.Id()
+	produk, _ := testfixture.CreateProduk(t, db, idToko, 5)
+	produkLain, _ := testfixture.CreateProduk(t, db, idTokoLain, 5)
+
+	// a trx holding the lines of both tokos, and a trx of the other toko only
+	trx := testfixture.NewTrx(1, testfixture.Name("INV"), testfixture.NewDetailTrx(produk, nil), testfixture.NewDetailTrx(produkLain, nil))
+	if _, err := trxRepo.CreateTrx(context.Background(), trx); err != nil {
+		t.Fatalf("CreateTrx error = %v", err)
+	}
+	if _, err := trxRepo.CreateTrx(context.Background(), testfixture.NewTrx(2, testfixture.Name("INV"), testfixture.NewDetailTrx(produkLain, nil))); err != nil {
+		t.Fatalf("CreateTrx error = %v", err)
+	}
+
+	res, err := repo.GetDetailTrxsByTokoId(context.Background(), &daos.FilterDetailTrx{Limit: 10, IdToko: idToko})
+	if err != nil {
+		t.Fatalf("GetDetailTrxsByTokoId error = %v", err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("GetDetailTrxsByTokoId returned %d detailtrxs, want 1", len(res))
+	}
+	if res[0].IdToko != idToko || res[0].IdTrx != trx.ID || res[0].Trx == nil {
+		t.Errorf("GetDetailTrxsByTokoId = detailtrx of toko %d and trx %d, want toko %d and trx %d", res[0].IdToko, res[0].IdTrx, idToko, trx.ID)
+	}
+}
@@ -2,18 +2,45 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"tugas_akhir_example/internal/daos"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TrxRepository interface {
-	GetAllTrxs(ctx context.Context, filter *daos.FilterTrx) (res []*daos.Trx, err error)
+	GetAllTrxs(ctx context.Context, filter *daos.FilterTrx) (res []*daos.Trx, total int64, err error)
 	GetTrxById(ctx context.Context, id string) (res *daos.Trx, err error)
+	GetTrxByKodeInvoice(ctx context.Context, kodeInvoice string) (res *daos.Trx, err error)
 	GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error)
 	GetAlamatById(ctx context.Context, id string) (res *daos.Alamat, err error)
+	GetUserById(ctx context.Context, id string) (res *daos.User, err error)
+	GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error)
+	GetVoucherByKode(ctx context.Context, kode string) (res *daos.Voucher, err error)
+	GetTrxStatusHistories(ctx context.Context, trxId string) (res []*daos.TrxStatusHistory, err error)
 	CreateTrx(ctx context.Context, data *daos.Trx) (res uint, err error)
+	CreateTrxFromCart(ctx context.Context, data *daos.Trx, idCart uint) (res uint, err error)
+	CreateTrxPayment(ctx context.Context, data *daos.Payment) (res uint, err error)
+	UpdateTrxStatus(ctx context.Context, data *daos.Trx, history *daos.TrxStatusHistory, release bool) (err error)
+	UpdateTrxPengirimanStatus(ctx context.Context, data *daos.Trx, pengiriman *daos.TrxPengiriman, history *daos.TrxStatusHistory) (err error)
+}
+
+var (
+	ErrTrxStatusChanged     = errors.New("status trx telah berubah, silakan muat ulang data")
+	ErrVoucherQuotaExceeded = errors.New("kuota voucher telah habis")
+)
+
+// InsufficientStokError is returned when the stok of some produks can no longer cover the ordered kuantitas
+type InsufficientStokError struct {
+	NamaProduks []string
+}
+
+// Error returns the message listing the produks running out of stok
+func (e *InsufficientStokError) Error() string {
+	return fmt.Sprintf("stok produk tidak mencukupi: %s", strings.Join(e.NamaProduks, ", "))
 }
 
 type TrxRepositoryImpl struct {
@@ -27,28 +54,49 @@ func NewTrxRepository(db *gorm.DB) TrxRepository {
 	}
 }
 
-// GetAllTrxs returns all trx data from the trx table
-func (alr *TrxRepositoryImpl) GetAllTrxs(ctx context.Context, filter *daos.FilterTrx) (res []*daos.Trx, err error) {
-	tx := alr.db.WithContext(ctx).Model(&res).Limit(filter.Limit).Offset(filter.Offset)
-	tx = tx.Preload("DetailTrxs").Preload("Alamat")
+// GetAllTrxs returns the page of trx data of the user from the trx table along with the total trx data matching the filter,
+// the page continued by the cursor starts right after the trx the cursor points at
+func (alr *TrxRepositoryImpl) GetAllTrxs(ctx context.Context, filter *daos.FilterTrx) (res []*daos.Trx, total int64, err error) {
+	scope := func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("id_user = ?", filter.IdUser)
+		tx = tx.Where("kode_invoice like ?", fmt.Sprintf("%%%s%%", filter.KodeInvoice))
+		if filter.Status != "" {
+			tx = tx.Where("status = ?", filter.Status)
+		}
+		return tx
+	}
+
+	if err := alr.db.WithContext(ctx).Model(&daos.Trx{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	tx := alr.db.WithContext(ctx).Model(&res).Scopes(scope).Limit(filter.Limit).Offset(filter.Offset)
+	tx = tx.Preload("DetailTrxs").Preload("Alamat").Preload("Payment")
 	tx = tx.Preload("DetailTrxs.LogProduk")
 	tx = tx.Preload("DetailTrxs.LogProduk.Produk").Preload("DetailTrxs.LogProduk.Toko").Preload("DetailTrxs.LogProduk.Category")
 	tx = tx.Preload("DetailTrxs.LogProduk.Produk.FotoProduks")
-	tx = tx.Where("kode_invoice like ?", fmt.Sprintf("%%%s%%", filter.KodeInvoice))
+	tx = tx.Preload("Pengirimans").Preload("Pengirimans.Toko").Preload("Voucher")
+
+	if filter.CursorCreatedAt != nil {
+		tx = tx.Where("(created_at, id) < (?, ?)", filter.CursorCreatedAt, filter.CursorId)
+	}
+
+	tx = tx.Order(filter.Order)
 	if err := tx.Find(&res).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return res, nil
+	return res, total, nil
 }
 
 // GetTrxById returns trx data having the id from the trx table
 func (alr *TrxRepositoryImpl) GetTrxById(ctx context.Context, id string) (res *daos.Trx, err error) {
 	tx := alr.db.WithContext(ctx).Model(&res)
-	tx = tx.Preload("DetailTrxs").Preload("Alamat")
+	tx = tx.Preload("DetailTrxs").Preload("Alamat").Preload("Payment").Preload("User")
 	tx = tx.Preload("DetailTrxs.LogProduk")
 	tx = tx.Preload("DetailTrxs.LogProduk.Produk").Preload("DetailTrxs.LogProduk.Toko").Preload("DetailTrxs.LogProduk.Category")
 	tx = tx.Preload("DetailTrxs.LogProduk.Produk.FotoProduks")
+	tx = tx.Preload("Pengirimans").Preload("Pengirimans.Toko").Preload("Voucher")
 	if err := tx.Where("id = ?", id).First(&res).Error; err != nil {
 		return nil, err
 	}
@@ -56,9 +104,24 @@ func (alr *TrxRepositoryImpl) GetTrxById(ctx context.Context, id string) (res *d
 	return res, nil
 }
 
+// GetTrxByKodeInvoice returns trx data having the kodeinvoice from the trx table
+func (alr *TrxRepositoryImpl) GetTrxByKodeInvoice(ctx context.Context, kodeInvoice string) (res *daos.Trx, err error) {
+	tx := alr.db.WithContext(ctx).Model(&res)
+	tx = tx.Preload("DetailTrxs").Preload("Alamat").Preload("Payment")
+	tx = tx.Preload("DetailTrxs.LogProduk")
+	tx = tx.Preload("DetailTrxs.LogProduk.Produk").Preload("DetailTrxs.LogProduk.Toko").Preload("DetailTrxs.LogProduk.Category")
+	tx = tx.Preload("DetailTrxs.LogProduk.Produk.FotoProduks")
+	tx = tx.Preload("Pengirimans").Preload("Pengirimans.Toko").Preload("Voucher")
+	if err := tx.Where("kode_invoice = ?", kodeInvoice).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // GetProdukById returns produk data having the id from the produk table
 func (alr *TrxRepositoryImpl) GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error) {
-	if err := alr.db.WithContext(ctx).Model(&res).Preload("FotoProduks").Where("id = ?", id).First(&res).Error; err != nil {
+	if err := alr.db.WithContext(ctx).Model(&res).Preload("FotoProduks").Preload("Toko").Preload("Variants").Where("id = ?", id).First(&res).Error; err != nil {
 		return nil, err
 	}
 
@@ -74,12 +137,286 @@ func (alr *TrxRepositoryImpl) GetAlamatById(ctx context.Context, id string) (res
 	return res, nil
 }
 
-// CreateTrx inserts the trx data to the trx table
+// CreateTrx decrements the stok of the ordered produks and inserts the trx data to the trx table in one transaction.
+// The kodeinvoice of the data is used as the invoice prefix and gets the next number of its sequence appended
 func (alr *TrxRepositoryImpl) CreateTrx(ctx context.Context, data *daos.Trx) (res uint, err error) {
-	result := alr.db.WithContext(ctx).Create(data)
-	if result.Error != nil {
-		return res, result.Error
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createTrx(tx, data)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	return data.ID, nil
+}
+
+// CreateTrxFromCart inserts the trx data like CreateTrx and empties the cart having the idcart in the same transaction,
+// so the cart keeps its items whenever the trx is not created
+func (alr *TrxRepositoryImpl) CreateTrxFromCart(ctx context.Context, data *daos.Trx, idCart uint) (res uint, err error) {
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createTrx(tx, data); err != nil {
+			return err
+		}
+
+		return clearCart(tx, idCart)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	return data.ID, nil
+}
+
+// CreateTrxPayment inserts the payment data of a trx to the payment table
+func (alr *TrxRepositoryImpl) CreateTrxPayment(ctx context.Context, data *daos.Payment) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
 	}
 
 	return data.ID, nil
 }
+
+// createTrx runs the trx creation of CreateTrx inside the given transaction
+func createTrx(tx *gorm.DB, data *daos.Trx) (err error) {
+	stokErr := &InsufficientStokError{}
+	for _, v := range data.DetailTrxs {
+		result := stokModel(tx, v.LogProduk).
+			Where("stok >= ?", v.Kuantitas).
+			Update("stok", gorm.Expr("stok - ?", v.Kuantitas))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			stokErr.NamaProduks = append(stokErr.NamaProduks, v.LogProduk.NamaProduk)
+		}
+	}
+
+	if len(stokErr.NamaProduks) > 0 {
+		return stokErr
+	}
+
+	if data.IdVoucher != nil {
+		if err := claimVoucher(tx, *data.IdVoucher, data.IdUser); err != nil {
+			return err
+		}
+	}
+
+	invoiceNumber, err := nextInvoiceNumber(tx, data.KodeInvoice)
+	if err != nil {
+		return err
+	}
+
+	data.KodeInvoice = fmt.Sprintf("%s/%06d", data.KodeInvoice, invoiceNumber)
+	return tx.Create(data).Error
+}
+
+// GetUserById returns user data having the id from the user table
+func (alr *TrxRepositoryImpl) GetUserById(ctx context.Context, id string) (res *daos.User, err error) {
+	res = &daos.User{}
+	if err := alr.db.WithContext(ctx).Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetTokoByUserId returns toko data having the userid from the toko table
+func (alr *TrxRepositoryImpl) GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error) {
+	res = &daos.Toko{}
+	if err := alr.db.WithContext(ctx).Where("id_user = ?", userId).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetVoucherByKode returns voucher data having the kode from the voucher table
+func (alr *TrxRepositoryImpl) GetVoucherByKode(ctx context.Context, kode string) (res *daos.Voucher, err error) {
+	res = &daos.Voucher{}
+	if err := alr.db.WithContext(ctx).Where("kode = ?", kode).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetTrxStatusHistories returns the status history data of the trx from the trx status history table
+func (alr *TrxRepositoryImpl) GetTrxStatusHistories(ctx context.Context, trxId string) (res []*daos.TrxStatusHistory, err error) {
+	if err := alr.db.WithContext(ctx).Where("id_trx = ?", trxId).Order("created_at asc, id asc").Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateTrxStatus moves the trx to the status of the history data, records the history, and optionally releases what the trx reserved
+// by giving the ordered kuantitas back to the produk stok and the voucher usage back to the voucher kuota in one transaction
+func (alr *TrxRepositoryImpl) UpdateTrxStatus(ctx context.Context, data *daos.Trx, history *daos.TrxStatusHistory, release bool) (err error) {
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateTrxStatus(tx, data, history, release)
+	})
+}
+
+// updateTrxStatus runs the trx status update of UpdateTrxStatus inside the given transaction
+func updateTrxStatus(tx *gorm.DB, data *daos.Trx, history *daos.TrxStatusHistory, release bool) (err error) {
+	result := tx.Model(&daos.Trx{}).Where("id = ? AND status = ?", data.ID, history.StatusLama).Update("status", history.StatusBaru)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTrxStatusChanged
+	}
+
+	history.IdTrx = data.ID
+	if err := tx.Create(history).Error; err != nil {
+		return err
+	}
+
+	// the pengirimans left at the former status follow the trx, all of them when the trx is called off
+	pengirimans := tx.Model(&daos.TrxPengiriman{}).Where("id_trx = ?", data.ID)
+	if history.StatusBaru != daos.TrxStatusCancelled && history.StatusBaru != daos.TrxStatusRefunded {
+		pengirimans = pengirimans.Where("status = ?", history.StatusLama)
+	}
+	if err := pengirimans.Update("status", history.StatusBaru).Error; err != nil {
+		return err
+	}
+
+	if release {
+		for _, v := range data.DetailTrxs {
+			err := stokModel(tx.Unscoped(), v.LogProduk).
+				Update("stok", gorm.Expr("stok + ?", v.Kuantitas)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if data.IdVoucher != nil {
+			result := tx.Unscoped().Where("id_trx = ?", data.ID).Delete(&daos.VoucherUsage{})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected > 0 {
+				err := tx.Unscoped().Model(&daos.Voucher{}).
+					Where("id = ? AND terpakai > 0", *data.IdVoucher).
+					Update("terpakai", gorm.Expr("terpakai - 1")).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, v := range data.Pengirimans {
+		if v.Status == history.StatusLama || history.StatusBaru == daos.TrxStatusCancelled || history.StatusBaru == daos.TrxStatusRefunded {
+			v.Status = history.StatusBaru
+		}
+	}
+
+	data.Status = history.StatusBaru
+	return nil
+}
+
+// UpdateTrxPengirimanStatus moves the pengiriman of the trx to the status of the history data and records the history in one transaction.
+// Once none of the pengirimans is left at the status of the trx, the trx moves along to the same status as a move of the system
+func (alr *TrxRepositoryImpl) UpdateTrxPengirimanStatus(ctx context.Context, data *daos.Trx, pengiriman *daos.TrxPengiriman, history *daos.TrxStatusHistory) (err error) {
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the trx row is locked first, so the last two pengirimans moved at once cannot both miss that none is left behind
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status = ?", data.ID, data.Status).First(&daos.Trx{}).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTrxStatusChanged
+			}
+			return err
+		}
+
+		result := tx.Model(&daos.TrxPengiriman{}).Where("id = ? AND status = ?", pengiriman.ID, history.StatusLama).Update("status", history.StatusBaru)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrTrxStatusChanged
+		}
+
+		history.IdTrx = data.ID
+		history.IdToko = &pengiriman.IdToko
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		pengiriman.Status = history.StatusBaru
+
+		if history.StatusLama != data.Status {
+			return nil
+		}
+
+		var behind int64
+		if err := tx.Model(&daos.TrxPengiriman{}).Where("id_trx = ? AND status = ?", data.ID, data.Status).Count(&behind).Error; err != nil {
+			return err
+		}
+
+		if behind > 0 {
+			return nil
+		}
+
+		return updateTrxStatus(tx, data, &daos.TrxStatusHistory{
+			Peran:      daos.TrxActorSystem,
+			StatusLama: data.Status,
+			StatusBaru: history.StatusBaru,
+		}, false)
+	})
+}
+
+// stokModel scopes the given transaction to the row holding the stok of the logproduk, which is the variant when one was ordered
+func stokModel(tx *gorm.DB, data *daos.LogProduk) *gorm.DB {
+	if data.IdVariant != nil {
+		return tx.Model(&daos.ProdukVariant{}).Where("id = ?", *data.IdVariant)
+	}
+
+	return tx.Model(&daos.Produk{}).Where("id = ?", data.IdProduk)
+}
+
+// nextInvoiceNumber increments the invoice sequence of the scope inside the given transaction and returns its new value.
+// The sequence row stays locked until the transaction ends so concurrent checkouts never get the same number
+func nextInvoiceNumber(tx *gorm.DB, scope string) (res int, err error) {
+	err = tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("last_number + 1")}),
+	}).Create(&daos.InvoiceSequence{Scope: scope, LastNumber: 1}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	sequence := &daos.InvoiceSequence{}
+	if err := tx.Where("scope = ?", scope).First(sequence).Error; err != nil {
+		return 0, err
+	}
+
+	return sequence.LastNumber, nil
+}
+
+// claimVoucher locks the voucher row inside the given transaction and counts one more usage of it by the user,
+// failing when either the global or the per user kuota has been used up
+func claimVoucher(tx *gorm.DB, voucherId, userId uint) (err error) {
+	voucher := &daos.Voucher{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", voucherId).First(voucher).Error; err != nil {
+		return err
+	}
+
+	if voucher.KuotaGlobal > 0 && voucher.Terpakai >= voucher.KuotaGlobal {
+		return ErrVoucherQuotaExceeded
+	}
+
+	if voucher.KuotaPerUser > 0 {
+		var used int64
+		if err := tx.Model(&daos.VoucherUsage{}).Where("id_voucher = ? AND id_user = ?", voucherId, userId).Count(&used).Error; err != nil {
+			return err
+		}
+
+		if used >= int64(voucher.KuotaPerUser) {
+			return ErrVoucherQuotaExceeded
+		}
+	}
+
+	return tx.Model(voucher).Update("terpakai", gorm.Expr("terpakai + 1")).Error
+}
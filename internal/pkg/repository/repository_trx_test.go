@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/testfixture"
+
+	"gorm.io/gorm"
+)
+
+const (
+	testCheckouts = 10
+	testInvoices  = 20
+)
+
+// openTrxTestDB returns the database holding the tables touched by CreateTrx and UpdateTrxStatus
+func openTrxTestDB(t *testing.T) *gorm.DB {
+	return testfixture.OpenDB(t,
+		&daos.Produk{},
+		&daos.ProdukVariant{},
+		&daos.LogProduk{},
+		&daos.Trx{},
+		&daos.DetailTrx{},
+		&daos.InvoiceSequence{},
+		&daos.TrxStatusHistory{},
+		&daos.TrxPengiriman{},
+		&daos.Cart{},
+		&daos.CartItem{},
+	)
+}
+
+func TestCreateTrxParallelCheckoutsOfLastStok(t *testing.T) {
+	db := openTrxTestDB(t)
+	repo := NewTrxRepository(db)
+
+	for _, withVariant := range []bool{false, true} {
+		name := "produk"
+		if withVariant {
+			name = "variant"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			produk, variant := testfixture.CreateProduk(t, db, 0, 1)
+			if !withVariant {
+				variant = nil
+			}
+			kodeInvoice := testfixture.Name("INV")
+
+			var wg sync.WaitGroup
+			errs := make([]error, testCheckouts)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = repo.CreateTrx(context.Background(), testfixture.NewTrx(1, kodeInvoice, testfixture.NewDetailTrx(produk, variant)))
+				}(i)
+			}
+			wg.Wait()
+
+			succeeded := 0
+			for _, err := range errs {
+				if err == nil {
+					succeeded++
+					continue
+				}
+
+				var stokErr *InsufficientStokError
+				if !errors.As(err, &stokErr) {
+					t.Errorf("CreateTrx error = %v, want an InsufficientStokError", err)
+					continue
+				}
+				if len(stokErr.NamaProduks) != 1 || stokErr.NamaProduks[0] != produk.NamaProduk {
+					t.Errorf("InsufficientStokError.NamaProduks = %v, want [%s]", stokErr.NamaProduks, produk.NamaProduk)
+				}
+			}
+
+			if succeeded != 1 {
+				t.Errorf("%d checkouts succeeded, want 1", succeeded)
+			}
+			if stok := testfixture.StokOf(t, db, produk, variant); stok != 0 {
+				t.Errorf("stok = %d, want 0", stok)
+			}
+
+			var trxs int64
+			if err := db.Model(&daos.Trx{}).Where("kode_invoice LIKE ?", kodeInvoice+"/%").Count(&trxs).Error; err != nil {
+				t.Fatalf("count trx: %s", err)
+			}
+			if trxs != 1 {
+				t.Errorf("%d trx inserted, want 1", trxs)
+			}
+		})
+	}
+}
+
+func TestUpdateTrxStatusCancelRestoresStok(t *testing.T) {
+	db := openTrxTestDB(t)
+	repo := NewTrxRepository(db)
+
+	for _, withVariant := range []bool{false, true} {
+		name := "produk"
+		if withVariant {
+			name = "variant"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			produk, variant := testfixture.CreateProduk(t, db, 0, 1)
+			if !withVariant {
+				variant = nil
+			}
+
+			trx := testfixture.NewTrx(1, testfixture.Name("INV"), testfixture.NewDetailTrx(produk, variant))
+			if _, err := repo.CreateTrx(context.Background(), trx); err != nil {
+				t.Fatalf("CreateTrx error = %v", err)
+			}
+			if stok := testfixture.StokOf(t, db, produk, variant); stok != 0 {
+				t.Fatalf("stok after checkout = %d, want 0", stok)
+			}
+
+			err := repo.UpdateTrxStatus(context.Background(), trx, &daos.TrxStatusHistory{
+				IdUser:     1,
+				Peran:      daos.TrxActorBuyer,
+				StatusLama: daos.TrxStatusPending,
+				StatusBaru: daos.TrxStatusCancelled,
+			}, true)
+			if err != nil {
+				t.Fatalf("UpdateTrxStatus error = %v", err)
+			}
+			if stok := testfixture.StokOf(t, db, produk, variant); stok != 1 {
+				t.Errorf("stok after cancel = %d, want 1", stok)
+			}
+
+			// the stok is released once, a second cancel finds the status already moved
+			err = repo.UpdateTrxStatus(context.Background(), trx, &daos.TrxStatusHistory{
+				IdUser:     1,
+				Peran:      daos.TrxActorBuyer,
+				StatusLama: daos.TrxStatusPending,
+				StatusBaru: daos.TrxStatusCancelled,
+			}, true)
+			if !errors.Is(err, ErrTrxStatusChanged) {
+				t.Errorf("second UpdateTrxStatus error = %v, want ErrTrxStatusChanged", err)
+			}
+			if stok := testfixture.StokOf(t, db, produk, variant); stok != 1 {
+				t.Errorf("stok after second cancel = %d, want 1", stok)
+			}
+		})
+	}
+}
+
+func TestCreateTrxFromCartEmptiesTheCartWithTheTrx(t *testing.T) {
+	db := openTrxTestDB(t)
+	repo := NewTrxRepository(db)
+
+	produk, _ := testfixture.CreateProduk(t, db, 0, 1)
+	cart := &daos.Cart{
+		IdUser: testfixture.Id(),
+		CartItems: []*daos.CartItem{
+			{IdProduk: produk.ID, Kuantitas: 1},
+		},
+	}
+	if err := db.Create(cart).Error; err != nil {
+		t.Fatalf("create cart: %s", err)
+	}
+
+	cartItems := func() int64 {
+		var count int64
+		if err := db.Model(&daos.CartItem{}).Where("id_cart = ?", cart.ID).Count(&count).Error; err != nil {
+			t.Fatalf("count cartitem: %s", err)
+		}
+		return count
+	}
+
+	if _, err := repo.CreateTrxFromCart(context.Background(), testfixture.NewTrx(1, testfixture.Name("INV"), testfixture.NewDetailTrx(produk, nil)), cart.ID); err != nil {
+		t.Fatalf("CreateTrxFromCart error = %v", err)
+	}
+	if count := cartItems(); count != 0 {
+		t.Errorf("%d cartitem left after the checkout, want 0", count)
+	}
+
+	// a failing checkout keeps the items in the cart
+	if err := db.Create(&daos.CartItem{IdCart: cart.ID, IdProduk: produk.ID, Kuantitas: 1}).Error; err != nil {
+		t.Fatalf("create cartitem: %s", err)
+	}
+
+	_, err := repo.CreateTrxFromCart(context.Background(), testfixture.NewTrx(1, testfixture.Name("INV"), testfixture.NewDetailTrx(produk, nil)), cart.ID)
+	var stokErr *InsufficientStokError
+	if !errors.As(err, &stokErr) {
+		t.Fatalf("CreateTrxFromCart error = %v, want an InsufficientStokError", err)
+	}
+	if count := cartItems(); count != 1 {
+		t.Errorf("%d cartitem left after the failed checkout, want 1", count)
+	}
+}
+
+func TestUpdateTrxPengirimanStatusMovesTheTrxAlongTheLastOne(t *testing.T) {
+	db := openTrxTestDB(t)
+	repo := NewTrxRepository(db)
+
+	idTokos := []uint{testfixture.Id(), testfixture.Id()}
+	trx := testfixture.NewTrx(1, testfixture.Name("INV"))
+	for _, idToko := range idTokos {
+		produk, _ := testfixture.CreateProduk(t, db, idToko, 1)
+		trx.DetailTrxs = append(trx.DetailTrxs, testfixture.NewDetailTrx(produk, nil))
+		trx.Pengirimans = append(trx.Pengirimans, &daos.TrxPengiriman{IdToko: idToko})
+	}
+	if _, err := repo.CreateTrx(context.Background(), trx); err != nil {
+		t.Fatalf("CreateTrx error = %v", err)
+	}
+
+	statuses := func() (trxStatus string, pengirimanStatuses []string) {
+		if err := db.Model(&daos.Trx{}).Where("id = ?", trx.ID).Pluck("status", &trxStatus).Error; err != nil {
+			t.Fatalf("read trx status: %s", err)
+		}
+		if err := db.Model(&daos.TrxPengiriman{}).Where("id_trx = ?", trx.ID).Order("id").Pluck("status", &pengirimanStatuses).Error; err != nil {
+			t.Fatalf("read pengiriman status: %s", err)
+		}
+		return trxStatus, pengirimanStatuses
+	}
+	check := func(step, wantTrx string, wantPengirimans ...string) {
+		t.Helper()
+		trxStatus, pengirimanStatuses := statuses()
+		if trxStatus != wantTrx || fmt.Sprint(pengirimanStatuses) != fmt.Sprint(wantPengirimans) {
+			t.Errorf("%s: trx %s, pengirimans %v, want %s, %v", step, trxStatus, pengirimanStatuses, wantTrx, wantPengirimans)
+		}
+	}
+
+	// the pengirimans follow the trx as a whole
+	err := repo.UpdateTrxStatus(context.Background(), trx, &daos.TrxStatusHistory{
+		Peran:      daos.TrxActorSystem,
+		StatusLama: daos.TrxStatusPending,
+		StatusBaru: daos.TrxStatusPaid,
+	}, false)
+	if err != nil {
+		t.Fatalf("UpdateTrxStatus error = %v", err)
+	}
+	check("paid", daos.TrxStatusPaid, daos.TrxStatusPaid, daos.TrxStatusPaid)
+
+	// the trx waits for the last pengiriman to be packed
+	packed := func(i int) error {
+		return repo.UpdateTrxPengirimanStatus(context.Background(), trx, trx.Pengirimans[i], &daos.TrxStatusHistory{
+			IdUser:     1,
+			Peran:      daos.TrxActorSeller,
+			StatusLama: daos.TrxStatusPaid,
+			StatusBaru: daos.TrxStatusPacked,
+		})
+	}
+	if err := packed(0); err != nil {
+		t.Fatalf("UpdateTrxPengirimanStatus error = %v", err)
+	}
+	check("first packed", daos.TrxStatusPaid, daos.TrxStatusPacked, daos.TrxStatusPaid)
+
+	if err := packed(0); !errors.Is(err, ErrTrxStatusChanged) {
+		t.Errorf("second pack of the first pengiriman error = %v, want ErrTrxStatusChanged", err)
+	}
+
+	if err := packed(1); err != nil {
+		t.Fatalf("UpdateTrxPengirimanStatus error = %v", err)
+	}
+	check("both packed", daos.TrxStatusPacked, daos.TrxStatusPacked, daos.TrxStatusPacked)
+
+	var histories []*daos.TrxStatusHistory
+	if err := db.Where("id_trx = ?", trx.ID).Order("id").Find(&histories).Error; err != nil {
+		t.Fatalf("read history: %s", err)
+	}
+	if len(histories) != 4 || histories[1].IdToko == nil || *histories[1].IdToko != idTokos[0] || *histories[2].IdToko != idTokos[1] ||
+		histories[3].Peran != daos.TrxActorSystem || histories[3].StatusBaru != daos.TrxStatusPacked {
+		t.Errorf("histories = %d, want paid, the pengiriman of each toko packed, then the trx packed by the system", len(histories))
+	}
+
+	// calling the trx off takes every pengiriman along
+	err = repo.UpdateTrxStatus(context.Background(), trx, &daos.TrxStatusHistory{
+		Peran:      daos.TrxActorAdmin,
+		StatusLama: daos.TrxStatusPacked,
+		StatusBaru: daos.TrxStatusCancelled,
+	}, true)
+	if err != nil {
+		t.Fatalf("UpdateTrxStatus error = %v", err)
+	}
+	check("cancelled", daos.TrxStatusCancelled, daos.TrxStatusCancelled, daos.TrxStatusCancelled)
+}
+
+func TestCreateTrxParallelInvoiceNumbers(t *testing.T) {
+	db := openTrxTestDB(t)
+	repo := NewTrxRepository(db)
+
+	produk, _ := testfixture.CreateProduk(t, db, 0, testInvoices)
+	prefixes := []string{testfixture.Name("INV"), testfixture.Name("INV")}
+
+	var wg sync.WaitGroup
+	kodeInvoices := make([]string, testInvoices)
+	errs := make([]error, testInvoices)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			trx := testfixture.NewTrx(1, prefixes[i%len(prefixes)], testfixture.NewDetailTrx(produk, nil))
+			_, errs[i] = repo.CreateTrx(context.Background(), trx)
+			kodeInvoices[i] = trx.KodeInvoice
+		}(i)
+	}
+	wg.Wait()
+
+	// every checkout of a prefix gets the next number of its own sequence, none twice
+	taken := map[string]bool{}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("CreateTrx error = %v", err)
+		}
+		if taken[kodeInvoices[i]] {
+			t.Errorf("kodeinvoice %s given twice", kodeInvoices[i])
+		}
+		taken[kodeInvoices[i]] = true
+	}
+
+	for _, prefix := range prefixes {
+		for n := 1; n <= testInvoices/len(prefixes); n++ {
+			if kodeInvoice := fmt.Sprintf("%s/%06d", prefix, n); !taken[kodeInvoice] {
+				t.Errorf("kodeinvoice %s missing", kodeInvoice)
+			}
+		}
+	}
+}
@@ -22,6 +22,10 @@ type UserRepository interface {
 	UpdateAlamatByID(ctx context.Context, id string, data *daos.Alamat) (err error)
 	UpdateUserById(ctx context.Context, id string, data *daos.User) (err error)
 	DeleteAlamatById(ctx context.Context, id string) (err error)
+	GetWishlistsByUserId(ctx context.Context, filter *daos.FilterWishlist) (res []*daos.Wishlist, total int64, err error)
+	GetProdukById(ctx context.Context, id uint) (res *daos.Produk, err error)
+	CreateWishlist(ctx context.Context, data *daos.Wishlist) (res uint, err error)
+	DeleteWishlist(ctx context.Context, userId uint, produkId string) (err error)
 }
 
 type UserRepositoryImpl struct {
@@ -123,15 +127,30 @@ func (alr *UserRepositoryImpl) UpdateAlamatByID(ctx context.Context, id string,
 
 // UpdateUserById updates user data having the id on the user table
 func (alr *UserRepositoryImpl) UpdateUserById(ctx context.Context, id string, data *daos.User) (err error) {
-	if err = alr.db.WithContext(ctx).Where("id = ? ", id).First(&daos.User{}).Error; err != nil {
+	current := &daos.User{}
+	if err = alr.db.WithContext(ctx).Where("id = ? ", id).First(current).Error; err != nil {
 		return gorm.ErrRecordNotFound
 	}
 
-	if err := alr.db.WithContext(ctx).Where("id = ?", id).Updates(data).Error; err != nil {
-		return err
-	}
-
-	return nil
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).Updates(data).Error; err != nil {
+			return err
+		}
+
+		// a changed notelp or email has to be verified again
+		if data.Notelp != "" && data.Notelp != current.Notelp {
+			if err := tx.Model(&daos.User{}).Where("id = ?", id).Update("notelp_verified_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		if data.Email != "" && data.Email != current.Email {
+			if err := tx.Model(&daos.User{}).Where("id = ?", id).Update("email_verified_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 // DeleteAlamatById deletes alamat data having the id on the alamat table
@@ -146,3 +165,55 @@ func (alr *UserRepositoryImpl) DeleteAlamatById(ctx context.Context, id string)
 
 	return nil
 }
+
+// GetWishlistsByUserId returns the page of wishlist data of the user from the wishlist table along with the total wishlist data of the user,
+// the produk data are loaded even when they have been deleted so the wishlist can flag them
+func (alr *UserRepositoryImpl) GetWishlistsByUserId(ctx context.Context, filter *daos.FilterWishlist) (res []*daos.Wishlist, total int64, err error) {
+	tx := alr.db.WithContext(ctx).Model(&daos.Wishlist{}).Where("id_user = ?", filter.IdUser).Session(&gorm.Session{})
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	tx = tx.Preload("Produk", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+	tx = tx.Preload("Produk.FotoProduks").Preload("Produk.Toko")
+	if err := tx.Limit(filter.Limit).Offset(filter.Offset).Order(filter.Order).Find(&res).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return res, total, nil
+}
+
+// GetProdukById returns produk data having the id from the produk table
+func (alr *UserRepositoryImpl) GetProdukById(ctx context.Context, id uint) (res *daos.Produk, err error) {
+	res = &daos.Produk{}
+	if err := alr.db.WithContext(ctx).Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateWishlist inserts the wishlist data to the wishlist table, adding a produk already on the wishlist keeps the existing data
+func (alr *UserRepositoryImpl) CreateWishlist(ctx context.Context, data *daos.Wishlist) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Where("id_user = ? AND id_produk = ?", data.IdUser, data.IdProduk).FirstOrCreate(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// DeleteWishlist deletes the wishlist data of the user having the produkid on the wishlist table
+func (alr *UserRepositoryImpl) DeleteWishlist(ctx context.Context, userId uint, produkId string) (err error) {
+	result := alr.db.WithContext(ctx).Where("id_user = ? AND id_produk = ?", userId, produkId).Delete(&daos.Wishlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
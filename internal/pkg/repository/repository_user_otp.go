@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type UserOtpRepository interface {
+	GetLatestUserOtp(ctx context.Context, idUser uint, purpose string) (res *daos.UserOtp, err error)
+	CountUserOtpsSince(ctx context.Context, idUser uint, purpose string, since time.Time) (res int64, err error)
+	CreateUserOtp(ctx context.Context, data *daos.UserOtp) (res uint, err error)
+	AttemptUserOtp(ctx context.Context, id uint, maxAttempts int) (err error)
+	ConsumeUserOtp(ctx context.Context, data *daos.UserOtp, user *daos.User) (err error)
+}
+
+var (
+	ErrUserOtpExhausted = errors.New("terlalu banyak percobaan, silakan minta otp baru")
+	ErrUserOtpConsumed  = errors.New("otp telah digunakan")
+)
+
+type UserOtpRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserOtpRepository returns the repository for the user otps
+func NewUserOtpRepository(db *gorm.DB) UserOtpRepository {
+	return &UserOtpRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetLatestUserOtp returns the latest userotp data of the user and the purpose from the userotp table
+func (alr *UserOtpRepositoryImpl) GetLatestUserOtp(ctx context.Context, idUser uint, purpose string) (res *daos.UserOtp, err error) {
+	res = &daos.UserOtp{}
+	if err := alr.db.WithContext(ctx).Where("id_user = ? AND purpose = ?", idUser, purpose).Order("id desc").First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CountUserOtpsSince returns the number of userotp data of the user and the purpose issued since the time
+func (alr *UserOtpRepositoryImpl) CountUserOtpsSince(ctx context.Context, idUser uint, purpose string, since time.Time) (res int64, err error) {
+	if err := alr.db.WithContext(ctx).Model(&daos.UserOtp{}).
+		Where("id_user = ? AND purpose = ? AND created_at >= ?", idUser, purpose, since).
+		Count(&res).Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// CreateUserOtp inserts the userotp data to the userotp table, consuming the previous userotp data of the user and the purpose
+func (alr *UserOtpRepositoryImpl) CreateUserOtp(ctx context.Context, data *daos.UserOtp) (res uint, err error) {
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&daos.UserOtp{}).
+			Where("id_user = ? AND purpose = ? AND consumed_at IS NULL", data.IdUser, data.Purpose).
+			Update("consumed_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(data).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// AttemptUserOtp counts an attempt to use the userotp data having the id, failing with ErrUserOtpExhausted once the attempts reach the maximum
+func (alr *UserOtpRepositoryImpl) AttemptUserOtp(ctx context.Context, id uint, maxAttempts int) (err error) {
+	result := alr.db.WithContext(ctx).Model(&daos.UserOtp{}).
+		Where("id = ? AND attempts < ? AND consumed_at IS NULL", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserOtpExhausted
+	}
+
+	return nil
+}
+
+// ConsumeUserOtp marks the userotp data as consumed and applies the non zero fields of the user to the user of the otp
+// in the same transaction, failing with ErrUserOtpConsumed when a concurrent request consumed it first
+func (alr *UserOtpRepositoryImpl) ConsumeUserOtp(ctx context.Context, data *daos.UserOtp, user *daos.User) (err error) {
+	return alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&daos.UserOtp{}).
+			Where("id = ? AND consumed_at IS NULL", data.ID).
+			Update("consumed_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrUserOtpConsumed
+		}
+
+		return tx.Model(&daos.User{}).Where("id = ?", data.IdUser).Updates(user).Error
+	})
+}
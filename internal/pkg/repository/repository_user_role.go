@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type UserRoleRepository interface {
+	GetRolesByUserId(ctx context.Context, idUser uint) (res []string, err error)
+	GetUserById(ctx context.Context, idUser uint) (res *daos.User, err error)
+	CreateUserRole(ctx context.Context, data *daos.UserRole) (res uint, err error)
+	DeleteUserRole(ctx context.Context, idUser uint, role string) (err error)
+}
+
+type UserRoleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserRoleRepository returns the repository for the role group path
+func NewUserRoleRepository(db *gorm.DB) UserRoleRepository {
+	return &UserRoleRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetRolesByUserId returns the roles of the user from the userrole table
+func (alr *UserRoleRepositoryImpl) GetRolesByUserId(ctx context.Context, idUser uint) (res []string, err error) {
+	res = []string{}
+	if err := alr.db.WithContext(ctx).Model(&daos.UserRole{}).Where("id_user = ?", idUser).Order("role").Pluck("role", &res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetUserById returns user data having the id along with its roles from the user table
+func (alr *UserRoleRepositoryImpl) GetUserById(ctx context.Context, idUser uint) (res *daos.User, err error) {
+	res = &daos.User{}
+	if err := alr.db.WithContext(ctx).Preload("Roles", func(db *gorm.DB) *gorm.DB {
+		return db.Order("role")
+	}).First(res, idUser).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateUserRole inserts the userrole data to the userrole table, granting a role the user already has keeps the existing data
+func (alr *UserRoleRepositoryImpl) CreateUserRole(ctx context.Context, data *daos.UserRole) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Where("id_user = ? AND role = ?", data.IdUser, data.Role).FirstOrCreate(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// DeleteUserRole deletes the role of the user on the userrole table
+func (alr *UserRoleRepositoryImpl) DeleteUserRole(ctx context.Context, idUser uint, role string) (err error) {
+	result := alr.db.WithContext(ctx).Where("id_user = ? AND role = ?", idUser, role).Delete(&daos.UserRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type UserSessionRepository interface {
+	GetUserSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (res *daos.UserSession, err error)
+	CreateUserSession(ctx context.Context, data *daos.UserSession) (res uint, err error)
+	RotateUserSession(ctx context.Context, prevData *daos.UserSession, data *daos.UserSession) (res uint, err error)
+	RevokeUserSessionFamily(ctx context.Context, family string) (err error)
+	RevokeUserSessionsByUserId(ctx context.Context, idUser uint) (err error)
+	IsUserSessionFamilyActive(ctx context.Context, family string) (res bool, err error)
+}
+
+var ErrUserSessionRotated = errors.New("refresh token telah digunakan")
+
+type UserSessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository returns the repository for the user sessions
+func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
+	return &UserSessionRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetUserSessionByRefreshTokenHash returns usersession data having the refresh token hash from the usersession table
+func (alr *UserSessionRepositoryImpl) GetUserSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (res *daos.UserSession, err error) {
+	res = &daos.UserSession{}
+	if err := alr.db.WithContext(ctx).Where("refresh_token_hash = ?", refreshTokenHash).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateUserSession inserts the usersession data to the usersession table
+func (alr *UserSessionRepositoryImpl) CreateUserSession(ctx context.Context, data *daos.UserSession) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// RotateUserSession marks the previous usersession data as rotated and inserts its successor to the usersession table,
+// failing with ErrUserSessionRotated when the previous one has already been rotated or revoked meanwhile
+func (alr *UserSessionRepositoryImpl) RotateUserSession(ctx context.Context, prevData *daos.UserSession, data *daos.UserSession) (res uint, err error) {
+	err = alr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&daos.UserSession{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", prevData.ID).
+			Update("rotated_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrUserSessionRotated
+		}
+
+		return tx.Create(data).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// RevokeUserSessionFamily revokes every usersession data of the family on the usersession table
+func (alr *UserSessionRepositoryImpl) RevokeUserSessionFamily(ctx context.Context, family string) (err error) {
+	if err := alr.db.WithContext(ctx).Model(&daos.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserSessionsByUserId revokes every usersession data of the user on the usersession table
+func (alr *UserSessionRepositoryImpl) RevokeUserSessionsByUserId(ctx context.Context, idUser uint) (err error) {
+	if err := alr.db.WithContext(ctx).Model(&daos.UserSession{}).
+		Where("id_user = ? AND revoked_at IS NULL", idUser).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsUserSessionFamilyActive reports whether the family has a usersession data neither revoked nor expired on the usersession table
+func (alr *UserSessionRepositoryImpl) IsUserSessionFamilyActive(ctx context.Context, family string) (res bool, err error) {
+	var count int64
+	if err := alr.db.WithContext(ctx).Model(&daos.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL AND expired_at > ?", family, time.Now()).
+		Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
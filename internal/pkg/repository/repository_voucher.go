@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+type VoucherRepository interface {
+	GetAllVouchers(ctx context.Context, filter *daos.FilterVoucher) (res []*daos.Voucher, err error)
+	GetVoucherById(ctx context.Context, id string) (res *daos.Voucher, err error)
+	GetUserById(ctx context.Context, id string) (res *daos.User, err error)
+	GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error)
+	CreateVoucher(ctx context.Context, data *daos.Voucher) (res uint, err error)
+	UpdateVoucher(ctx context.Context, prevData *daos.Voucher, data *daos.Voucher) (err error)
+	DeleteVoucher(ctx context.Context, data *daos.Voucher) (err error)
+}
+
+type VoucherRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewVoucherRepository returns the repository for the voucher group path
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &VoucherRepositoryImpl{
+		db: db,
+	}
+}
+
+// GetAllVouchers returns all voucher data matching the filter from the voucher table
+func (alr *VoucherRepositoryImpl) GetAllVouchers(ctx context.Context, filter *daos.FilterVoucher) (res []*daos.Voucher, err error) {
+	tx := alr.db.WithContext(ctx).Model(&res).Limit(filter.Limit).Offset(filter.Offset)
+	tx = tx.Preload("Toko").Preload("Category")
+	if filter.IdToko != nil {
+		tx = tx.Where("id_toko = ?", *filter.IdToko)
+	}
+
+	if filter.Kode != "" {
+		tx = tx.Where("kode like ?", fmt.Sprintf("%%%s%%", filter.Kode))
+	}
+
+	if err := tx.Order("created_at desc, id desc").Find(&res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetVoucherById returns voucher data having the id from the voucher table
+func (alr *VoucherRepositoryImpl) GetVoucherById(ctx context.Context, id string) (res *daos.Voucher, err error) {
+	res = &daos.Voucher{}
+	if err := alr.db.WithContext(ctx).Preload("Toko").Preload("Category").Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetUserById returns user data having the id from the user table
+func (alr *VoucherRepositoryImpl) GetUserById(ctx context.Context, id string) (res *daos.User, err error) {
+	res = &daos.User{}
+	if err := alr.db.WithContext(ctx).Where("id = ?", id).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetTokoByUserId returns toko data having the userid from the toko table
+func (alr *VoucherRepositoryImpl) GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error) {
+	res = &daos.Toko{}
+	if err := alr.db.WithContext(ctx).Where("id_user = ?", userId).First(res).Error; err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateVoucher inserts the voucher data to the voucher table
+func (alr *VoucherRepositoryImpl) CreateVoucher(ctx context.Context, data *daos.Voucher) (res uint, err error) {
+	if err := alr.db.WithContext(ctx).Create(data).Error; err != nil {
+		return 0, err
+	}
+
+	return data.ID, nil
+}
+
+// UpdateVoucher updates voucher data on the voucher table
+func (alr *VoucherRepositoryImpl) UpdateVoucher(ctx context.Context, prevData *daos.Voucher, data *daos.Voucher) (err error) {
+	if err := alr.db.WithContext(ctx).Where("id = ?", prevData.ID).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteVoucher deletes voucher data on the voucher table
+func (alr *VoucherRepositoryImpl) DeleteVoucher(ctx context.Context, data *daos.Voucher) (err error) {
+	if err := alr.db.WithContext(ctx).Delete(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the maximum length of a slug, leaving room for the uniqueness suffix
+const MaxLength = 180
+
+// transliterations lists the letters not decomposed into a latin base letter and a diacritic
+var transliterations = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ł': "l",
+	'þ': "th",
+	'ı': "i",
+	'&': " dan ",
+	'@': " at ",
+}
+
+// Make returns the slug of the text, e.g. "Kaos Polos Café & Co." becomes kaos-polos-cafe-dan-co
+func Make(s string) string {
+	s = strings.ToLower(s)
+
+	mapped := strings.Builder{}
+	for _, c := range s {
+		if v, ok := transliterations[c]; ok {
+			mapped.WriteString(v)
+			continue
+		}
+		mapped.WriteRune(c)
+	}
+
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, err := transform.String(t, mapped.String())
+	if err != nil {
+		s = mapped.String()
+	}
+
+	res := strings.Builder{}
+	dash := false
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			if dash && res.Len() > 0 {
+				res.WriteByte('-')
+			}
+			res.WriteRune(c)
+			dash = false
+		case c == '\'' || c == '’':
+			// apostrophes are dropped so "Toko Ani's" becomes toko-anis
+		default:
+			dash = true
+		}
+	}
+
+	slug := res.String()
+	if len(slug) > MaxLength {
+		slug = strings.TrimRight(slug[:MaxLength], "-")
+	}
+
+	return slug
+}
+
+// Unique returns the slug suffixed with the lowest number making it absent from the taken slugs, e.g. kaos-polos-2
+func Unique(slug string, taken map[string]struct{}) string {
+	if _, ok := taken[slug]; !ok {
+		return slug
+	}
+
+	for i := 2; ; i++ {
+		res := slug + "-" + strconv.Itoa(i)
+		if _, ok := taken[res]; !ok {
+			return res
+		}
+	}
+}
+
+// Matches reports whether the slug is the base slug or the base slug with a uniqueness suffix
+func Matches(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+
+	suffix := strings.TrimPrefix(slug, base+"-")
+	if suffix == slug || suffix == "" {
+		return false
+	}
+
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n >= 2 && strconv.Itoa(n) == suffix
+}
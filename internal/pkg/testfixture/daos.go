@@ -0,0 +1,91 @@
+package testfixture
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+var idSeq = uint64(time.Now().UnixNano() % 1000000000)
+
+// Id returns an id unique to this run of the test, for the rows referring to a user, a cart or a detailtrx not inserted by the test
+func Id() uint {
+	return uint(atomic.AddUint64(&idSeq, 1))
+}
+
+// CreateProduk inserts a produk of the toko with a single variant, both having the stok
+func CreateProduk(t *testing.T, db *gorm.DB, idToko uint, stok int) (*daos.Produk, *daos.ProdukVariant) {
+	t.Helper()
+
+	produk := &daos.Produk{
+		NamaProduk: Name("produk"),
+		Slug:       Name("produk"),
+		IdToko:     idToko,
+		Stok:       stok,
+	}
+	if err := db.Create(produk).Error; err != nil {
+		t.Fatalf("create produk: %s", err)
+	}
+
+	variant := &daos.ProdukVariant{
+		IdProduk: produk.ID,
+		Sku:      Name("sku"),
+		Stok:     stok,
+	}
+	if err := db.Create(variant).Error; err != nil {
+		t.Fatalf("create variant: %s", err)
+	}
+
+	return produk, variant
+}
+
+// NewDetailTrx returns a detailtrx of one piece of the produk, or of its variant when given
+func NewDetailTrx(produk *daos.Produk, variant *daos.ProdukVariant) *daos.DetailTrx {
+	logProduk := &daos.LogProduk{
+		IdProduk:   produk.ID,
+		NamaProduk: produk.NamaProduk,
+		Slug:       produk.Slug,
+		IdToko:     produk.IdToko,
+	}
+	if variant != nil {
+		logProduk.IdVariant = &variant.ID
+		logProduk.Sku = variant.Sku
+	}
+
+	return &daos.DetailTrx{
+		LogProduk: logProduk,
+		IdToko:    produk.IdToko,
+		Kuantitas: 1,
+	}
+}
+
+// NewTrx returns a pending trx of the user ordering the detailtrxs
+func NewTrx(idUser uint, kodeInvoice string, detailTrxs ...*daos.DetailTrx) *daos.Trx {
+	return &daos.Trx{
+		IdUser:      idUser,
+		KodeInvoice: kodeInvoice,
+		Status:      daos.TrxStatusPending,
+		DetailTrxs:  detailTrxs,
+	}
+}
+
+// StokOf reads the current stok of the produk, or of its variant when given
+func StokOf(t *testing.T, db *gorm.DB, produk *daos.Produk, variant *daos.ProdukVariant) int {
+	t.Helper()
+
+	var stok int
+	var err error
+	if variant != nil {
+		err = db.Model(&daos.ProdukVariant{}).Where("id = ?", variant.ID).Pluck("stok", &stok).Error
+	} else {
+		err = db.Model(&daos.Produk{}).Where("id = ?", produk.ID).Pluck("stok", &stok).Error
+	}
+	if err != nil {
+		t.Fatalf("read stok: %s", err)
+	}
+
+	return stok
+}
@@ -0,0 +1,111 @@
+package testfixture
+
+import (
+	"context"
+	"sync"
+	"time"
+	"tugas_akhir_example/internal/daos"
+
+	"gorm.io/gorm"
+)
+
+// UserSessions is an in-memory user session repository, failing every call with Err when set.
+// It records the users whose sessions are revoked and the ctx of the last call
+type UserSessions struct {
+	mu       sync.Mutex
+	Sessions []*daos.UserSession
+	Revoked  []uint
+	Err      error
+	Ctx      context.Context
+}
+
+// ActiveSession returns the user sessions holding an active session of the family
+func ActiveSession(idUser uint, family string) *UserSessions {
+	return &UserSessions{Sessions: []*daos.UserSession{
+		{IdUser: idUser, Family: family, ExpiredAt: time.Now().Add(time.Hour)},
+	}}
+}
+
+func (f *UserSessions) call(ctx context.Context) error {
+	f.Ctx = ctx
+	return f.Err
+}
+
+func (f *UserSessions) GetUserSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (res *daos.UserSession, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	for _, v := range f.Sessions {
+		if v.RefreshTokenHash == refreshTokenHash {
+			return v, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *UserSessions) CreateUserSession(ctx context.Context, data *daos.UserSession) (res uint, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call(ctx); err != nil {
+		return 0, err
+	}
+	data.ID = uint(len(f.Sessions) + 1)
+	f.Sessions = append(f.Sessions, data)
+	return data.ID, nil
+}
+
+func (f *UserSessions) RotateUserSession(ctx context.Context, prevData *daos.UserSession, data *daos.UserSession) (res uint, err error) {
+	now := time.Now()
+	prevData.RotatedAt = &now
+	return f.CreateUserSession(ctx, data)
+}
+
+func (f *UserSessions) RevokeUserSessionFamily(ctx context.Context, family string) (err error) {
+	return f.revoke(ctx, func(v *daos.UserSession) bool { return v.Family == family })
+}
+
+func (f *UserSessions) RevokeUserSessionsByUserId(ctx context.Context, idUser uint) (err error) {
+	if err := f.revoke(ctx, func(v *daos.UserSession) bool { return v.IdUser == idUser }); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Revoked = append(f.Revoked, idUser)
+	return nil
+}
+
+func (f *UserSessions) revoke(ctx context.Context, match func(v *daos.UserSession) bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call(ctx); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, v := range f.Sessions {
+		if match(v) && v.RevokedAt == nil {
+			v.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *UserSessions) IsUserSessionFamilyActive(ctx context.Context, family string) (res bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call(ctx); err != nil {
+		return false, err
+	}
+	for _, v := range f.Sessions {
+		if v.Family == family && v.RevokedAt == nil && v.ExpiredAt.After(time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,57 @@
+// Package testfixture holds the fixtures shared by the tests of the other packages: the throwaway mysql database,
+// the rows inserted into it and the in-memory repositories
+package testfixture
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MysqlDsnEnv names the environment variable holding the dsn of the throwaway mysql database the database tests run against,
+// e.g. root:secret@tcp(localhost:3306)/tugas_akhir_test?charset=utf8mb4&parseTime=True&loc=Local, see make test-mysql
+const MysqlDsnEnv = "TEST_MYSQL_DSN"
+
+var seq uint64
+
+// OpenDB connects to the database of TEST_MYSQL_DSN and migrates the models, skipping the test when it is not set.
+// The tests leave their rows behind, so the database should not hold anything else
+func OpenDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(MysqlDsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", MysqlDsnEnv)
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %s", err)
+	}
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate database: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
+
+// Name returns a name unique to this run of the test, for the columns having a unique index
+func Name(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), atomic.AddUint64(&seq, 1))
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/ratelimit"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
@@ -21,18 +24,43 @@ import (
 type AuthUseCase interface {
 	LoginUser(ctx context.Context, data dto.AuthReqLogin) (res *dto.LoginResp, err *helper.ErrorStruct)
 	RegisterUser(ctx context.Context, data dto.AuthReqRegister) (err *helper.ErrorStruct)
+	RefreshToken(ctx context.Context, data dto.AuthReqRefresh) (res *dto.RefreshResp, err *helper.ErrorStruct)
+	LogoutUser(ctx context.Context, principal *utils.Principal) (err *helper.ErrorStruct)
 }
 
+const (
+	accessTokenDefaultTtl  = 10 * time.Minute
+	refreshTokenDefaultTtl = 30 * 24 * time.Hour
+)
+
+var errLoginInvalid = errors.New("no telp atau kata sandi salah")
+
 type AuthUseCaseImpl struct {
-	authRepository repository.AuthRepository
-	jwtSecret      string
+	authRepository        repository.AuthRepository
+	userSessionRepository repository.UserSessionRepository
+	jwtSecret             string
+	accessTokenTtl        time.Duration
+	refreshTokenTtl       time.Duration
+	limiter               *ratelimit.Limiter
 }
 
-// NewAuthUseCase returns the usecase for the auth group path
-func NewAuthUseCase(authRepository repository.AuthRepository, jwtSecret string) AuthUseCase {
+// NewAuthUseCase returns the usecase for the auth group path, limiting the logins of each notelp with the limiter
+func NewAuthUseCase(authRepository repository.AuthRepository, userSessionRepository repository.UserSessionRepository, jwtSecret string, accessTokenTtl, refreshTokenTtl time.Duration,
+	limiter *ratelimit.Limiter) AuthUseCase {
+	if accessTokenTtl <= 0 {
+		accessTokenTtl = accessTokenDefaultTtl
+	}
+	if refreshTokenTtl <= 0 {
+		refreshTokenTtl = refreshTokenDefaultTtl
+	}
+
 	return &AuthUseCaseImpl{
-		authRepository: authRepository,
-		jwtSecret:      jwtSecret,
+		authRepository:        authRepository,
+		userSessionRepository: userSessionRepository,
+		jwtSecret:             jwtSecret,
+		accessTokenTtl:        accessTokenTtl,
+		refreshTokenTtl:       refreshTokenTtl,
+		limiter:               limiter,
 	}
 }
 
@@ -46,10 +74,17 @@ func (alc *AuthUseCaseImpl) LoginUser(ctx context.Context, data dto.AuthReqLogin
 		}
 	}
 
+	limiterKey := "login:notelp:" + data.Notelp
+	if customErr := alc.checkLoginLimit(ctx, limiterKey); customErr != nil {
+		return res, customErr
+	}
+
 	resRepo, err := alc.authRepository.GetUserByNotelp(ctx, data.Notelp)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = errors.New("no data user")
+			// an unknown notelp is refused like a wrong kata sandi, so the response does not reveal which notelps are registered
+			utils.ValidateDummyPassword(data.KataSandi)
+			return res, alc.failLogin(ctx, limiterKey, err)
 		}
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return res, &helper.ErrorStruct{
@@ -61,7 +96,7 @@ func (alc *AuthUseCaseImpl) LoginUser(ctx context.Context, data dto.AuthReqLogin
 	err = utils.ValidatePassword(resRepo.KataSandi, data.KataSandi)
 	if err != nil {
 		if err == bcrypt.ErrMismatchedHashAndPassword {
-			err = errors.New("no telp atau kata sandi salah")
+			return res, alc.failLogin(ctx, limiterKey, err)
 		}
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return res, &helper.ErrorStruct{
@@ -70,12 +105,20 @@ func (alc *AuthUseCaseImpl) LoginUser(ctx context.Context, data dto.AuthReqLogin
 		}
 	}
 
-	token, err := utils.GenerateNewJWT(&utils.Claims{
-		UserId: strconv.Itoa(int(resRepo.ID)),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * time.Minute)),
-		},
-	})
+	if err := alc.limiter.Succeed(ctx, limiterKey); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	}
+
+	family, err := utils.GenerateSessionId()
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	tokens, err := alc.createSession(ctx, resRepo.ID, family, nil)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return res, &helper.ErrorStruct{
@@ -105,7 +148,8 @@ func (alc *AuthUseCaseImpl) LoginUser(ctx context.Context, data dto.AuthReqLogin
 	res = utils.UserToLoginResp(resRepo)
 	res.IdProvinsi = provinceData
 	res.IdKota = cityData
-	res.Token = token
+	res.Token = tokens.Token
+	res.RefreshToken = tokens.RefreshToken
 
 	return res, nil
 }
@@ -149,7 +193,7 @@ func (alc *AuthUseCaseImpl) RegisterUser(ctx context.Context, data dto.AuthReqRe
 		Email:        data.Email,
 		IdProvinsi:   data.IdProvinsi,
 		IdKota:       data.IdKota,
-		IsAdmin:      false,
+		Roles:        defaultUserRoles(),
 	})
 
 	if errRepo != nil {
@@ -175,3 +219,194 @@ func (alc *AuthUseCaseImpl) RegisterUser(ctx context.Context, data dto.AuthReqRe
 
 	return nil
 }
+
+// RefreshToken handles the business logic to exchange the refresh token for a new access token and a new refresh token.
+// Using a refresh token which has already been exchanged revokes its whole session, as it has likely been stolen
+func (alc *AuthUseCaseImpl) RefreshToken(ctx context.Context, data dto.AuthReqRefresh) (res *dto.RefreshResp, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return res, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepo, err := alc.userSessionRepository.GetUserSessionByRefreshTokenHash(ctx, utils.HashRefreshToken(data.RefreshToken))
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusUnauthorized
+			err = errors.New("refresh token tidak valid")
+		}
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	if resRepo.RevokedAt != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", utils.ErrSessionRevoked.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusUnauthorized,
+			Err:  utils.ErrSessionRevoked,
+		}
+	}
+
+	if resRepo.RotatedAt != nil {
+		return res, alc.revokeReusedSession(ctx, resRepo)
+	}
+
+	if time.Now().After(resRepo.ExpiredAt) {
+		err = errors.New("refresh token telah kedaluwarsa, silakan login kembali")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusUnauthorized,
+			Err:  err,
+		}
+	}
+
+	res, err = alc.createSession(ctx, resRepo.IdUser, resRepo.Family, resRepo)
+	if err != nil {
+		// a concurrent request exchanged the same refresh token first
+		if errors.Is(err, repository.ErrUserSessionRotated) {
+			return nil, alc.revokeReusedSession(ctx, resRepo)
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// LogoutUser handles the business logic to log out the user, revoking the session of the principal
+func (alc *AuthUseCaseImpl) LogoutUser(ctx context.Context, principal *utils.Principal) (customErr *helper.ErrorStruct) {
+	if err := alc.userSessionRepository.RevokeUserSessionFamily(ctx, principal.SessionId); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// checkLoginLimit refuses the login of the notelp while it is locked out after failed logins or beyond its rate limit.
+// A failing limiter lets the login through, so an outage of its store does not lock everybody out
+func (alc *AuthUseCaseImpl) checkLoginLimit(ctx context.Context, limiterKey string) (customErr *helper.ErrorStruct) {
+	lockedFor, err := alc.limiter.LockedFor(ctx, limiterKey)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil
+	}
+
+	if lockedFor > 0 {
+		return &helper.ErrorStruct{
+			Code: fiber.StatusTooManyRequests,
+			Err:  fmt.Errorf("terlalu banyak percobaan login gagal, silakan coba lagi dalam %d detik", int(math.Ceil(lockedFor.Seconds()))),
+		}
+	}
+
+	res, err := alc.limiter.Allow(ctx, limiterKey, alc.limiter.Rules.LoginNotelp)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil
+	}
+
+	if !res.Allowed {
+		return &helper.ErrorStruct{
+			Code: fiber.StatusTooManyRequests,
+			Err:  fmt.Errorf("terlalu banyak percobaan login, silakan coba lagi dalam %d detik", int(math.Ceil(res.RetryAfter.Seconds()))),
+		}
+	}
+
+	return nil
+}
+
+// failLogin records the failed login of the notelp towards its lockout, refusing it with the same error whatever its cause
+func (alc *AuthUseCaseImpl) failLogin(ctx context.Context, limiterKey string, cause error) (customErr *helper.ErrorStruct) {
+	helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s : %s", limiterKey, cause.Error()))
+
+	lockedFor, err := alc.limiter.Fail(ctx, limiterKey)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	} else if lockedFor > 0 {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelWarn, fmt.Sprintf("%s locked out for %s", limiterKey, lockedFor))
+	}
+
+	return &helper.ErrorStruct{
+		Code: fiber.StatusBadRequest,
+		Err:  errLoginInvalid,
+	}
+}
+
+// defaultUserRoles returns the roles granted to the registered users
+func defaultUserRoles() (res []*daos.UserRole) {
+	for _, v := range rbac.DefaultRoles {
+		res = append(res, &daos.UserRole{Role: v})
+	}
+
+	return res
+}
+
+// createSession issues an access token and a refresh token of the session family to the user,
+// rotating the previous refresh token of the family when given
+func (alc *AuthUseCaseImpl) createSession(ctx context.Context, idUser uint, family string, prevSession *daos.UserSession) (res *dto.RefreshResp, err error) {
+	refreshToken, refreshTokenHash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &daos.UserSession{
+		IdUser:           idUser,
+		Family:           family,
+		RefreshTokenHash: refreshTokenHash,
+		ExpiredAt:        time.Now().Add(alc.refreshTokenTtl),
+	}
+	if prevSession != nil {
+		_, err = alc.userSessionRepository.RotateUserSession(ctx, prevSession, session)
+	} else {
+		_, err = alc.userSessionRepository.CreateUserSession(ctx, session)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateNewJWT(&utils.Claims{
+		UserId:    strconv.Itoa(int(idUser)),
+		SessionId: family,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(alc.accessTokenTtl)),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RefreshResp{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeReusedSession revokes the session family of the refresh token used again after its rotation
+func (alc *AuthUseCaseImpl) revokeReusedSession(ctx context.Context, session *daos.UserSession) (customErr *helper.ErrorStruct) {
+	helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : refresh token of user %d reused, revoking session %s", session.IdUser, session.Family))
+	if err := alc.userSessionRepository.RevokeUserSessionFamily(ctx, session.Family); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return &helper.ErrorStruct{
+		Code: fiber.StatusUnauthorized,
+		Err:  repository.ErrUserSessionRotated,
+	}
+}
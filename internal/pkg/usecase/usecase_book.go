@@ -8,6 +8,7 @@ import (
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
 	bookdto "tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
 	bookrepository "tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
@@ -16,13 +17,19 @@ import (
 )
 
 type BookUseCase interface {
-	GetAllBooks(ctx context.Context, params bookdto.BookFilter) (res []bookdto.BookResp, err *helper.ErrorStruct)
+	GetAllBooks(ctx context.Context, params bookdto.BookFilter) (res *bookdto.AllBookResp, err *helper.ErrorStruct)
 	GetBookByID(ctx context.Context, bookid string) (res bookdto.BookResp, err *helper.ErrorStruct)
 	CreateBook(ctx context.Context, data bookdto.BookReqCreate) (res uint, err *helper.ErrorStruct)
 	UpdateBookByID(ctx context.Context, bookid string, data bookdto.BookReqUpdate) (res string, err *helper.ErrorStruct)
 	DeleteBookByID(ctx context.Context, bookid string) (res string, err *helper.ErrorStruct)
 }
 
+// bookSortFields lists the columns the book data can be sorted by
+var bookSortFields = pagination.SortFields{
+	"nama":       "title",
+	"created_at": "created_at",
+}
+
 type BookUseCaseImpl struct {
 	bookrepository bookrepository.BookRepository
 }
@@ -36,21 +43,22 @@ func NewBookUseCase(bookrepository bookrepository.BookRepository) BookUseCase {
 }
 
 // GetAllBooks handles the business logic to retrieve all stored book data
-func (alc *BookUseCaseImpl) GetAllBooks(ctx context.Context, params bookdto.BookFilter) (res []bookdto.BookResp, err *helper.ErrorStruct) {
-	if params.Limit < 1 {
-		params.Limit = 10
-	}
-
-	if params.Page < 1 {
-		params.Page = 0
-	} else {
-		params.Page = (params.Page - 1) * params.Limit
+func (alc *BookUseCaseImpl) GetAllBooks(ctx context.Context, params bookdto.BookFilter) (res *bookdto.AllBookResp, err *helper.ErrorStruct) {
+	page := pagination.New(params.Page, params.Limit)
+	order, errSort := bookSortFields.Clause(params.Sort, params.Order, "id asc")
+	if errSort != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errSort.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errSort,
+		}
 	}
 
-	resRepo, errRepo := alc.bookrepository.GetAllBooks(ctx, daos.FilterBook{
-		Limit:  params.Limit,
-		Offset: params.Page,
+	resRepo, total, errRepo := alc.bookrepository.GetAllBooks(ctx, daos.FilterBook{
+		Limit:  page.Limit,
+		Offset: page.Offset(),
 		Title:  params.Title,
+		Order:  order,
 	})
 	if errors.Is(errRepo, gorm.ErrRecordNotFound) {
 		return res, &helper.ErrorStruct{
@@ -67,8 +75,12 @@ func (alc *BookUseCaseImpl) GetAllBooks(ctx context.Context, params bookdto.Book
 		}
 	}
 
+	res = &bookdto.AllBookResp{
+		Meta: page.Meta(total),
+		Data: []bookdto.BookResp{},
+	}
 	for _, v := range resRepo {
-		res = append(res, bookdto.BookResp{
+		res.Data = append(res.Data, bookdto.BookResp{
 			ID:          v.ID,
 			Title:       v.Title,
 			Description: v.Description,
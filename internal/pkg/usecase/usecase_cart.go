@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CartUseCase interface {
+	GetMyCart(ctx context.Context, principal *utils.Principal) (res *dto.CartResp, customErr *helper.ErrorStruct)
+	AddCartItem(ctx context.Context, principal *utils.Principal, data *dto.CartItemCreateReq) (res uint, customErr *helper.ErrorStruct)
+	UpdateCartItem(ctx context.Context, principal *utils.Principal, id string, data *dto.CartItemUpdateReq) (customErr *helper.ErrorStruct)
+	DeleteCartItem(ctx context.Context, principal *utils.Principal, id string) (customErr *helper.ErrorStruct)
+	CheckoutCart(ctx context.Context, principal *utils.Principal, data *dto.CartCheckoutReq) (res uint, customErr *helper.ErrorStruct)
+}
+
+type CartUseCaseImpl struct {
+	cartRepository repository.CartRepository
+	trxUseCase     TrxUseCase
+}
+
+// NewCartUseCase returns the usecase for the cart group path
+func NewCartUseCase(cartRepository repository.CartRepository, trxUseCase TrxUseCase) CartUseCase {
+	return &CartUseCaseImpl{
+		cartRepository: cartRepository,
+		trxUseCase:     trxUseCase,
+	}
+}
+
+// GetMyCart handles the business logic to retrieve the cart of the current user
+func (alc *CartUseCaseImpl) GetMyCart(ctx context.Context, principal *utils.Principal) (res *dto.CartResp, customErr *helper.ErrorStruct) {
+	resRepo, customErr := alc.getCart(ctx, principal)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	res, err := utils.CartToCartResp(resRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// AddCartItem handles the business logic to put the produk into the cart of the current user
+func (alc *CartUseCaseImpl) AddCartItem(ctx context.Context, principal *utils.Principal, data *dto.CartItemCreateReq) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepoCart, customErr := alc.getCart(ctx, principal)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	resRepoProduk, err := alc.cartRepository.GetProdukById(ctx, strconv.Itoa(int(data.ProductId)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New("no data produk")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	variant, err := findProdukVariant(resRepoProduk, data.VariantId)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	var idVariant *uint
+	hargaKonsumen, stok := resRepoProduk.HargaKonsumen, resRepoProduk.Stok
+	if variant != nil {
+		idVariant = &variant.ID
+		hargaKonsumen, stok = variant.HargaKonsumenOf(resRepoProduk), variant.Stok
+	}
+
+	var existingItem *daos.CartItem
+	for _, v := range resRepoCart.CartItems {
+		if v.IdProduk == resRepoProduk.ID && sameProdukVariant(v.IdVariant, idVariant) {
+			existingItem = v
+			break
+		}
+	}
+
+	kuantitas := data.Kuantitas
+	if existingItem != nil {
+		kuantitas += existingItem.Kuantitas
+	}
+
+	if kuantitas > stok {
+		err = fmt.Errorf("stok produk %s tidak mencukupi, tersisa %d", resRepoProduk.NamaProduk, stok)
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if existingItem != nil {
+		err = alc.cartRepository.UpdateCartItem(ctx, existingItem, &daos.CartItem{
+			Kuantitas:     kuantitas,
+			HargaKonsumen: hargaKonsumen,
+		})
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		return existingItem.ID, nil
+	}
+
+	res, err = alc.cartRepository.CreateCartItem(ctx, &daos.CartItem{
+		IdCart:        resRepoCart.ID,
+		IdProduk:      resRepoProduk.ID,
+		IdVariant:     idVariant,
+		Kuantitas:     kuantitas,
+		HargaKonsumen: hargaKonsumen,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateCartItem handles the business logic to change the kuantitas of the cartitem having the id
+func (alc *CartUseCaseImpl) UpdateCartItem(ctx context.Context, principal *utils.Principal, id string, data *dto.CartItemUpdateReq) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepo, customErr := alc.getOwnedCartItem(ctx, principal, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	if resRepo.Produk == nil {
+		err := errors.New("produk sudah tidak tersedia")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if resRepo.IdVariant != nil && resRepo.Variant == nil {
+		err := errors.New("varian produk sudah tidak tersedia")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	stok := resRepo.Produk.Stok
+	if resRepo.Variant != nil {
+		stok = resRepo.Variant.Stok
+	}
+
+	if data.Kuantitas > stok {
+		err := fmt.Errorf("stok produk %s tidak mencukupi, tersisa %d", resRepo.Produk.NamaProduk, stok)
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	err := alc.cartRepository.UpdateCartItem(ctx, resRepo, &daos.CartItem{
+		Kuantitas: data.Kuantitas,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// DeleteCartItem handles the business logic to remove the cartitem having the id from the cart of the current user
+func (alc *CartUseCaseImpl) DeleteCartItem(ctx context.Context, principal *utils.Principal, id string) (customErr *helper.ErrorStruct) {
+	resRepo, customErr := alc.getOwnedCartItem(ctx, principal, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	if err := alc.cartRepository.DeleteCartItem(ctx, resRepo); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// CheckoutCart handles the business logic to turn the cart of the current user into a trx
+func (alc *CartUseCaseImpl) CheckoutCart(ctx context.Context, principal *utils.Principal, data *dto.CartCheckoutReq) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepo, customErr := alc.getCart(ctx, principal)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	cartResp, err := utils.CartToCartResp(resRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	if len(resRepo.CartItems) == 0 {
+		err = errors.New("keranjang kosong")
+	} else if !cartResp.Checkoutable {
+		err = errors.New("beberapa produk di keranjang tidak tersedia, perbarui keranjang terlebih dahulu")
+	}
+
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	trxCreateReq := &dto.TrxCreateReq{
+		MethodBayar: data.MethodBayar,
+		AlamatKirim: data.AlamatKirim,
+		Layanan:     data.Layanan,
+		KodeVoucher: data.KodeVoucher,
+		DetailTrxes: []*dto.DetailTrxCreateReq{},
+	}
+	for _, v := range resRepo.CartItems {
+		detailTrxCreateReq := &dto.DetailTrxCreateReq{
+			ProductId: v.IdProduk,
+			Kuantitas: v.Kuantitas,
+		}
+		if v.IdVariant != nil {
+			detailTrxCreateReq.VariantId = *v.IdVariant
+		}
+
+		trxCreateReq.DetailTrxes = append(trxCreateReq.DetailTrxes, detailTrxCreateReq)
+	}
+
+	res, customErr = alc.trxUseCase.CreateTrxFromCart(ctx, principal, trxCreateReq, resRepo.ID)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	return res, nil
+}
+
+// getCart returns the cart of the principal
+func (alc *CartUseCaseImpl) getCart(ctx context.Context, principal *utils.Principal) (res *daos.Cart, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	res, err := alc.cartRepository.GetCartByUserId(ctx, userId)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// getOwnedCartItem returns the cartitem having the id when it belongs to the cart of the principal
+func (alc *CartUseCaseImpl) getOwnedCartItem(ctx context.Context, principal *utils.Principal, id string) (res *daos.CartItem, customErr *helper.ErrorStruct) {
+	resRepoCart, customErr := alc.getCart(ctx, principal)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	res, err := alc.cartRepository.GetCartItemById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errors.New("no data cart item")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if res.IdCart != resRepoCart.ID {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, "Error : unauthorized")
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("you are unauthorized"),
+		}
+	}
+
+	return res, nil
+}
+
+// sameProdukVariant checks whether both variant ids point to the same variant, or both point to none
+func sameProdukVariant(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
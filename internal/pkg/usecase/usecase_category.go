@@ -6,6 +6,7 @@ import (
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
@@ -13,13 +14,19 @@ import (
 )
 
 type CategoryUseCase interface {
-	GetAllCategories(ctx context.Context) (res []*dto.CategoryResp, customErr *helper.ErrorStruct)
+	GetAllCategories(ctx context.Context, filter *dto.CategoryFilter) (res *dto.AllCategoryResp, customErr *helper.ErrorStruct)
 	GetCategoryById(ctx context.Context, id string) (res *dto.CategoryResp, customErr *helper.ErrorStruct)
 	CreateCategory(ctx context.Context, data *dto.CategoryCreateReq) (res uint, customErr *helper.ErrorStruct)
 	UpdateCategoryById(ctx context.Context, id string, data *dto.CategoryUpdateReq) (customErr *helper.ErrorStruct)
 	DeleteCategoryByID(ctx context.Context, id string) (customErr *helper.ErrorStruct)
 }
 
+// categorySortFields lists the columns the category data can be sorted by
+var categorySortFields = pagination.SortFields{
+	"nama":       "nama_category",
+	"created_at": "created_at",
+}
+
 type CategoryUseCaseImpl struct {
 	categoryRepository repository.CategoryRepository
 }
@@ -32,8 +39,23 @@ func NewCategoryUseCase(categoryRepository repository.CategoryRepository) Catego
 }
 
 // GetAllCategories handles the business logic to retrieve all stored category data
-func (alc *CategoryUseCaseImpl) GetAllCategories(ctx context.Context) (res []*dto.CategoryResp, customErr *helper.ErrorStruct) {
-	resRepo, err := alc.categoryRepository.GetAllCategory(ctx)
+func (alc *CategoryUseCaseImpl) GetAllCategories(ctx context.Context, filter *dto.CategoryFilter) (res *dto.AllCategoryResp, customErr *helper.ErrorStruct) {
+	page := pagination.New(filter.Page, filter.Limit)
+	order, err := categorySortFields.Clause(filter.Sort, filter.Order, "id asc")
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	resRepo, total, err := alc.categoryRepository.GetAllCategory(ctx, daos.FilterCategory{
+		Limit:        page.Limit,
+		Offset:       page.Offset(),
+		NamaCategory: filter.NamaCategory,
+		Order:        order,
+	})
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -42,8 +64,12 @@ func (alc *CategoryUseCaseImpl) GetAllCategories(ctx context.Context) (res []*dt
 		}
 	}
 
+	res = &dto.AllCategoryResp{
+		Meta: page.Meta(total),
+		Data: []*dto.CategoryResp{},
+	}
 	for _, v := range resRepo {
-		res = append(res, utils.CatergoryToCategoryResp(v))
+		res.Data = append(res.Data, utils.CatergoryToCategoryResp(v))
 	}
 	return res, nil
 }
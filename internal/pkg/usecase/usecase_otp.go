@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/notifier"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/otp"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type OtpUseCase interface {
+	RequestNotelpVerification(ctx context.Context, principal *utils.Principal) (res *dto.OtpResp, customErr *helper.ErrorStruct)
+	VerifyNotelp(ctx context.Context, principal *utils.Principal, data dto.AuthReqVerifyOtp) (customErr *helper.ErrorStruct)
+	RequestEmailVerification(ctx context.Context, principal *utils.Principal) (res *dto.OtpResp, customErr *helper.ErrorStruct)
+	VerifyEmail(ctx context.Context, principal *utils.Principal, data dto.AuthReqVerifyOtp) (customErr *helper.ErrorStruct)
+	ForgotPassword(ctx context.Context, data dto.AuthReqForgotPassword) (customErr *helper.ErrorStruct)
+	ResetPassword(ctx context.Context, data dto.AuthReqResetPassword) (customErr *helper.ErrorStruct)
+}
+
+const (
+	otpDefaultTtl            = 5 * time.Minute
+	otpDefaultResendInterval = time.Minute
+	otpDefaultMaxAttempts    = 5
+
+	// otpMaxPerHour caps the otps of a purpose sent to a user in an hour, whatever the resend interval
+	otpMaxPerHour = 5
+)
+
+var errOtpInvalid = errors.New("otp tidak valid atau telah kedaluwarsa")
+
+// otpSubjects lists the email subject and the description in the message of the otp of each purpose
+var otpSubjects = map[string]string{
+	daos.OtpPurposeVerifyNotelp:  "Verifikasi nomor telepon",
+	daos.OtpPurposeVerifyEmail:   "Verifikasi email",
+	daos.OtpPurposeResetPassword: "Reset kata sandi",
+}
+
+type OtpUseCaseImpl struct {
+	authRepository        repository.AuthRepository
+	userOtpRepository     repository.UserOtpRepository
+	userSessionRepository repository.UserSessionRepository
+	notifier              notifier.Notifier
+	secret                string
+	ttl                   time.Duration
+	resendInterval        time.Duration
+	maxAttempts           int
+}
+
+// NewOtpUseCase returns the usecase for the verification and the password reset paths of the auth group path
+func NewOtpUseCase(authRepository repository.AuthRepository, userOtpRepository repository.UserOtpRepository, userSessionRepository repository.UserSessionRepository,
+	notifier notifier.Notifier, secret string, ttl, resendInterval time.Duration, maxAttempts int) OtpUseCase {
+	if ttl <= 0 {
+		ttl = otpDefaultTtl
+	}
+	if resendInterval <= 0 {
+		resendInterval = otpDefaultResendInterval
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = otpDefaultMaxAttempts
+	}
+
+	return &OtpUseCaseImpl{
+		authRepository:        authRepository,
+		userOtpRepository:     userOtpRepository,
+		userSessionRepository: userSessionRepository,
+		notifier:              notifier,
+		secret:                secret,
+		ttl:                   ttl,
+		resendInterval:        resendInterval,
+		maxAttempts:           maxAttempts,
+	}
+}
+
+// RequestNotelpVerification handles the business logic to send an otp to the notelp of the principal by sms
+func (alc *OtpUseCaseImpl) RequestNotelpVerification(ctx context.Context, principal *utils.Principal) (res *dto.OtpResp, customErr *helper.ErrorStruct) {
+	user, customErr := alc.getUser(ctx, principal)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if user.NotelpVerifiedAt != nil {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errors.New("nomor telepon sudah terverifikasi"),
+		}
+	}
+
+	return alc.issueOtp(ctx, user, daos.OtpPurposeVerifyNotelp, notifier.ChannelSMS, user.Notelp)
+}
+
+// VerifyNotelp handles the business logic to verify the notelp of the principal with the otp sent to it
+func (alc *OtpUseCaseImpl) VerifyNotelp(ctx context.Context, principal *utils.Principal, data dto.AuthReqVerifyOtp) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	user, customErr := alc.getUser(ctx, principal)
+	if customErr != nil {
+		return customErr
+	}
+
+	latest, customErr := alc.checkOtp(ctx, user, daos.OtpPurposeVerifyNotelp, user.Notelp, data.Otp)
+	if customErr != nil {
+		return customErr
+	}
+
+	now := time.Now()
+	return alc.consumeOtp(ctx, latest, &daos.User{
+		NotelpVerifiedAt: &now,
+	})
+}
+
+// RequestEmailVerification handles the business logic to send an otp to the email of the principal
+func (alc *OtpUseCaseImpl) RequestEmailVerification(ctx context.Context, principal *utils.Principal) (res *dto.OtpResp, customErr *helper.ErrorStruct) {
+	user, customErr := alc.getUser(ctx, principal)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errors.New("email sudah terverifikasi"),
+		}
+	}
+
+	return alc.issueOtp(ctx, user, daos.OtpPurposeVerifyEmail, notifier.ChannelEmail, user.Email)
+}
+
+// VerifyEmail handles the business logic to verify the email of the principal with the otp sent to it
+func (alc *OtpUseCaseImpl) VerifyEmail(ctx context.Context, principal *utils.Principal, data dto.AuthReqVerifyOtp) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	user, customErr := alc.getUser(ctx, principal)
+	if customErr != nil {
+		return customErr
+	}
+
+	latest, customErr := alc.checkOtp(ctx, user, daos.OtpPurposeVerifyEmail, user.Email, data.Otp)
+	if customErr != nil {
+		return customErr
+	}
+
+	now := time.Now()
+	return alc.consumeOtp(ctx, latest, &daos.User{
+		EmailVerifiedAt: &now,
+	})
+}
+
+// ForgotPassword handles the business logic to send an otp resetting the kata sandi to the notelp by sms or to the email.
+// It succeeds whether or not a user has the notelp or the email, so it cannot be used to find out the registered users
+func (alc *OtpUseCaseImpl) ForgotPassword(ctx context.Context, data dto.AuthReqForgotPassword) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	user, channel, target, err := alc.getUserByContact(ctx, data.Notelp, data.Email)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if _, customErr := alc.issueOtp(ctx, user, daos.OtpPurposeResetPassword, channel, target); customErr != nil {
+		// a throttled request is not reported either, as only the registered users are throttled
+		if customErr.Code == fiber.StatusTooManyRequests {
+			return nil
+		}
+
+		return customErr
+	}
+
+	return nil
+}
+
+// ResetPassword handles the business logic to replace the kata sandi of the user having the notelp or the email with the otp sent to it,
+// revoking every session of the user. The otp also verifies the notelp or the email it was sent to. Like ForgotPassword, it cannot be used
+// to find out the registered users: an unknown contact, a wrong, expired or exhausted otp all fail with errOtpInvalid
+func (alc *OtpUseCaseImpl) ResetPassword(ctx context.Context, data dto.AuthReqResetPassword) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	user, channel, target, err := alc.getUserByContact(ctx, data.Notelp, data.Email)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errOtpInvalid,
+		}
+	}
+
+	latest, customErr := alc.checkOtp(ctx, user, daos.OtpPurposeResetPassword, target, data.Otp)
+	if customErr != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", customErr.Err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errOtpInvalid,
+		}
+	}
+
+	// the kata sandi is only hashed once the otp is right, so the anonymous calls cannot make the server spend a bcrypt each
+	katasandiHash, err := utils.HashPassword(data.KataSandi)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	now := time.Now()
+	update := &daos.User{
+		KataSandi: katasandiHash,
+	}
+	if channel == notifier.ChannelSMS {
+		update.NotelpVerifiedAt = &now
+	} else {
+		update.EmailVerifiedAt = &now
+	}
+
+	if customErr := alc.consumeOtp(ctx, latest, update); customErr != nil {
+		return customErr
+	}
+
+	if err := alc.userSessionRepository.RevokeUserSessionsByUserId(ctx, user.ID); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// getUser returns the user of the principal
+func (alc *OtpUseCaseImpl) getUser(ctx context.Context, principal *utils.Principal) (res *daos.User, customErr *helper.ErrorStruct) {
+	res, err := alc.authRepository.GetUserById(ctx, principal.UserId)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("user tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// getUserByContact returns the user having the notelp, or else the email, along with the channel and the target the otp is sent to
+func (alc *OtpUseCaseImpl) getUserByContact(ctx context.Context, notelp, email string) (res *daos.User, channel, target string, err error) {
+	if notelp != "" {
+		res, err = alc.authRepository.GetUserByNotelp(ctx, notelp)
+		return res, notifier.ChannelSMS, notelp, err
+	}
+
+	res, err = alc.authRepository.GetUserByEmail(ctx, email)
+	return res, notifier.ChannelEmail, email, err
+}
+
+// issueOtp sends a new otp of the purpose to the target of the user through the channel, unless an otp of the purpose
+// has been sent to the user within the resend interval or too many of them within the hour
+func (alc *OtpUseCaseImpl) issueOtp(ctx context.Context, user *daos.User, purpose, channel, target string) (res *dto.OtpResp, customErr *helper.ErrorStruct) {
+	now := time.Now()
+
+	latest, err := alc.userOtpRepository.GetLatestUserOtp(ctx, user.ID, purpose)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if latest != nil {
+		if wait := latest.CreatedAt.Add(alc.resendInterval).Sub(now); wait > 0 {
+			return nil, &helper.ErrorStruct{
+				Code: fiber.StatusTooManyRequests,
+				Err:  fmt.Errorf("otp baru dapat diminta dalam %d detik", int(wait.Seconds())+1),
+			}
+		}
+	}
+
+	count, err := alc.userOtpRepository.CountUserOtpsSince(ctx, user.ID, purpose, now.Add(-time.Hour))
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if count >= otpMaxPerHour {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusTooManyRequests,
+			Err:  errors.New("terlalu banyak permintaan otp, silakan coba lagi nanti"),
+		}
+	}
+
+	code, err := otp.Generate()
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	data := &daos.UserOtp{
+		IdUser:    user.ID,
+		Purpose:   purpose,
+		Target:    target,
+		CodeHash:  otp.Hash(alc.secret, user.ID, purpose, code),
+		ExpiredAt: now.Add(alc.ttl),
+	}
+	if _, err := alc.userOtpRepository.CreateUserOtp(ctx, data); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if err := alc.notifier.Notify(ctx, &notifier.Message{
+		Channel: channel,
+		To:      target,
+		Subject: otpSubjects[purpose],
+		Body: fmt.Sprintf("Kode OTP %s anda adalah %s, berlaku %d menit. Jangan berikan kode ini kepada siapapun.",
+			otpSubjects[purpose], code, int(alc.ttl.Minutes())),
+	}); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadGateway,
+			Err:  errors.New("gagal mengirim otp, silakan coba lagi"),
+		}
+	}
+
+	return &dto.OtpResp{
+		Channel:   channel,
+		Target:    otp.Mask(target),
+		ExpiredAt: data.ExpiredAt,
+		ResendAt:  data.CreatedAt.Add(alc.resendInterval),
+	}, nil
+}
+
+// checkOtp checks the code against the latest otp of the purpose sent to the target of the user, counting the attempt,
+// and returns the otp to consume
+func (alc *OtpUseCaseImpl) checkOtp(ctx context.Context, user *daos.User, purpose, target, otpCode string) (res *daos.UserOtp, customErr *helper.ErrorStruct) {
+	latest, err := alc.userOtpRepository.GetLatestUserOtp(ctx, user.ID, purpose)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	// the otp of a notelp or an email changed since it was sent cannot verify the new one
+	if latest == nil || latest.ConsumedAt != nil || time.Now().After(latest.ExpiredAt) || latest.Target != target {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errOtpInvalid,
+		}
+	}
+
+	if err := alc.userOtpRepository.AttemptUserOtp(ctx, latest.ID, alc.maxAttempts); err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, repository.ErrUserOtpExhausted) {
+			code = fiber.StatusTooManyRequests
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	if !otp.Equal(latest.CodeHash, alc.secret, user.ID, purpose, otpCode) {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  fmt.Errorf("otp salah, sisa %d percobaan", alc.maxAttempts-latest.Attempts-1),
+		}
+	}
+
+	return latest, nil
+}
+
+// consumeOtp consumes the otp checked by checkOtp while applying the update to its user
+func (alc *OtpUseCaseImpl) consumeOtp(ctx context.Context, data *daos.UserOtp, update *daos.User) (customErr *helper.ErrorStruct) {
+	if err := alc.userOtpRepository.ConsumeUserOtp(ctx, data, update); err != nil {
+		if errors.Is(err, repository.ErrUserOtpConsumed) {
+			err = errOtpInvalid
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
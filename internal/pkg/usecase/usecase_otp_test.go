@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/infrastructure/notifier"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/otp"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/pkg/testfixture"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const testOtpSecret = "secret"
+
+// fakeAuthRepository answers the user lookups by notelp from the users it holds
+type fakeAuthRepository struct {
+	repository.AuthRepository
+	users map[string]*daos.User
+}
+
+func (f *fakeAuthRepository) GetUserByNotelp(ctx context.Context, notelp string) (res *daos.User, err error) {
+	if res, ok := f.users[notelp]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeUserOtpRepository holds the latest otp of each user, recording the update applied when it is consumed
+type fakeUserOtpRepository struct {
+	repository.UserOtpRepository
+	otps    map[uint]*daos.UserOtp
+	updated *daos.User
+}
+
+func (f *fakeUserOtpRepository) GetLatestUserOtp(ctx context.Context, idUser uint, purpose string) (res *daos.UserOtp, err error) {
+	if res, ok := f.otps[idUser]; ok && res.Purpose == purpose {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUserOtpRepository) AttemptUserOtp(ctx context.Context, id uint, maxAttempts int) (err error) {
+	for _, v := range f.otps {
+		if v.ID == id {
+			if v.Attempts >= maxAttempts {
+				return repository.ErrUserOtpExhausted
+			}
+			v.Attempts++
+		}
+	}
+	return nil
+}
+
+func (f *fakeUserOtpRepository) ConsumeUserOtp(ctx context.Context, data *daos.UserOtp, user *daos.User) (err error) {
+	now := time.Now()
+	data.ConsumedAt = &now
+	f.updated = user
+	return nil
+}
+
+// newTestOtpUseCase returns the otp usecase for a user of notelp 0811 sent the reset password otp 123456
+func newTestOtpUseCase() (*OtpUseCaseImpl, *fakeUserOtpRepository, *testfixture.UserSessions) {
+	user := &daos.User{Model: gorm.Model{ID: 7}, Notelp: "0811"}
+	otps := &fakeUserOtpRepository{otps: map[uint]*daos.UserOtp{
+		user.ID: {
+			Model:     gorm.Model{ID: 1},
+			IdUser:    user.ID,
+			Purpose:   daos.OtpPurposeResetPassword,
+			Target:    user.Notelp,
+			CodeHash:  otp.Hash(testOtpSecret, user.ID, daos.OtpPurposeResetPassword, "123456"),
+			ExpiredAt: time.Now().Add(time.Minute),
+		},
+	}}
+	sessions := &testfixture.UserSessions{}
+
+	usecase := NewOtpUseCase(&fakeAuthRepository{users: map[string]*daos.User{user.Notelp: user}}, otps, sessions,
+		notifier.NewLogNotifier(), testOtpSecret, 0, 0, 3).(*OtpUseCaseImpl)
+	return usecase, otps, sessions
+}
+
+func TestResetPasswordFailuresLookAlike(t *testing.T) {
+	usecase, otps, sessions := newTestOtpUseCase()
+
+	reset := func(notelp, code string) error {
+		customErr := usecase.ResetPassword(context.Background(), dto.AuthReqResetPassword{Notelp: notelp, Otp: code, KataSandi: "rahasia baru"})
+		if customErr == nil {
+			return nil
+		}
+		if customErr.Code != fiber.StatusBadRequest {
+			t.Errorf("ResetPassword(%s, %s) code = %d, want %d", notelp, code, customErr.Code, fiber.StatusBadRequest)
+		}
+		return customErr.Err
+	}
+
+	// an unknown notelp fails like a registered one given a wrong otp, before and after its attempts run out
+	if err := reset("0899", "000000"); !errors.Is(err, errOtpInvalid) {
+		t.Errorf("unknown notelp error = %v, want errOtpInvalid", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := reset("0811", "000000"); !errors.Is(err, errOtpInvalid) {
+			t.Errorf("wrong otp %d error = %v, want errOtpInvalid", i+1, err)
+		}
+	}
+
+	if otps.updated != nil || len(sessions.Revoked) != 0 {
+		t.Fatalf("failed resets consumed the otp or revoked the sessions")
+	}
+
+	// the right otp no longer works once the attempts run out
+	if err := reset("0811", "123456"); !errors.Is(err, errOtpInvalid) {
+		t.Errorf("exhausted otp error = %v, want errOtpInvalid", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	usecase, otps, sessions := newTestOtpUseCase()
+
+	if customErr := usecase.ResetPassword(context.Background(), dto.AuthReqResetPassword{Notelp: "0811", Otp: "123456", KataSandi: "rahasia baru"}); customErr != nil {
+		t.Fatalf("ResetPassword error = %v", customErr.Err)
+	}
+
+	if otps.updated == nil || utils.ValidatePassword(otps.updated.KataSandi, "rahasia baru") != nil {
+		t.Fatalf("kata sandi not replaced by the hash of the new one")
+	}
+	if otps.updated.NotelpVerifiedAt == nil {
+		t.Errorf("notelp the otp was sent to not verified")
+	}
+	if len(sessions.Revoked) != 1 || sessions.Revoked[0] != 7 {
+		t.Errorf("revoked sessions of users %v, want [7]", sessions.Revoked)
+	}
+
+	// the consumed otp cannot reset the kata sandi again
+	customErr := usecase.ResetPassword(context.Background(), dto.AuthReqResetPassword{Notelp: "0811", Otp: "123456", KataSandi: "lagi"})
+	if customErr == nil || !errors.Is(customErr.Err, errOtpInvalid) {
+		t.Errorf("second ResetPassword error = %v, want errOtpInvalid", customErr)
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/payment"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PaymentUseCase interface {
+	HandleWebhook(ctx context.Context, provider, signature string, body []byte) (res *dto.PaymentWebhookResp, customErr *helper.ErrorStruct)
+}
+
+type PaymentUseCaseImpl struct {
+	paymentRepository repository.PaymentRepository
+	paymentProviders  *payment.Registry
+}
+
+// NewPaymentUseCase returns the usecase for the payment group path
+func NewPaymentUseCase(paymentRepository repository.PaymentRepository, paymentProviders *payment.Registry) PaymentUseCase {
+	return &PaymentUseCaseImpl{
+		paymentRepository: paymentRepository,
+		paymentProviders:  paymentProviders,
+	}
+}
+
+// HandleWebhook handles the business logic to settle the payment and its trx based on the webhook sent by the provider
+func (alc *PaymentUseCaseImpl) HandleWebhook(ctx context.Context, provider, signature string, body []byte) (res *dto.PaymentWebhookResp, customErr *helper.ErrorStruct) {
+	paymentProvider, err := alc.paymentProviders.Get(provider)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusNotFound,
+			Err:  err,
+		}
+	}
+
+	event, err := paymentProvider.ParseWebhook(signature, body)
+	if err == nil && (event.EventId == "" || event.Reference == "") {
+		err = errors.New("event id and reference are required")
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			code = fiber.StatusUnauthorized
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	resRepo, err := alc.paymentRepository.GetPaymentByReference(ctx, event.Reference)
+	if err == nil && resRepo.Provider != paymentProvider.Name() {
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data payment")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	status := resRepo.Status
+	var history *daos.TrxStatusHistory
+	if resRepo.Status == daos.PaymentStatusPending && event.Status != payment.StatusPending {
+		status = event.Status
+
+		nextTrxStatus := daos.TrxStatusPaid
+		if status == daos.PaymentStatusFailed {
+			nextTrxStatus = daos.TrxStatusCancelled
+		}
+
+		trx := resRepo.Trx
+		if validateTrxStatusTransition(trx.Status, nextTrxStatus, daos.TrxActorSystem, trx.MethodBayar) == nil {
+			history = &daos.TrxStatusHistory{
+				Peran:      daos.TrxActorSystem,
+				StatusLama: trx.Status,
+				StatusBaru: nextTrxStatus,
+				Alasan:     fmt.Sprintf("pembayaran %s %s (%s)", paymentProvider.Name(), status, event.EventId),
+			}
+		}
+	}
+
+	res = &dto.PaymentWebhookResp{
+		Reference: resRepo.Reference,
+		Status:    status,
+	}
+
+	err = alc.paymentRepository.ApplyPaymentEvent(ctx, resRepo, status, &daos.PaymentEvent{
+		Provider:  paymentProvider.Name(),
+		EventId:   event.EventId,
+		Reference: event.Reference,
+		Status:    event.Status,
+		Payload:   string(body),
+	}, history, history != nil && history.StatusBaru == daos.TrxStatusCancelled)
+	if errors.Is(err, repository.ErrPaymentEventProcessed) {
+		res.Duplicate = true
+		return res, nil
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, repository.ErrPaymentStatusChanged) || errors.Is(err, repository.ErrTrxStatusChanged) {
+			code = fiber.StatusConflict
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
@@ -2,17 +2,23 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/search"
+	"tugas_akhir_example/internal/infrastructure/storage"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/imaging"
+	"tugas_akhir_example/internal/pkg/pagination"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
@@ -22,25 +28,110 @@ import (
 
 type ProdukUseCase interface {
 	GetAllProduks(ctx context.Context, filter *dto.ProdukFilter) (res *dto.AllProdukResp, customErr *helper.ErrorStruct)
+	SearchProduks(ctx context.Context, filter *dto.ProdukSearchFilter) (res *dto.ProdukSearchResp, customErr *helper.ErrorStruct)
 	GetProdukById(ctx context.Context, param string) (res *dto.ProdukResp, customErr *helper.ErrorStruct)
-	CreateProduk(ctx context.Context, data *dto.ProdukCreateReq, token string, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct)
+	GetProdukBySlug(ctx context.Context, slug string) (res *dto.ProdukResp, redirectSlug string, customErr *helper.ErrorStruct)
+	CreateProduk(ctx context.Context, data *dto.ProdukCreateReq, principal *utils.Principal, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct)
 	UpdateProdukByID(ctx context.Context, data *dto.ProdukUpdateReq, id string, photos []*multipart.FileHeader) (customErr *helper.ErrorStruct)
 	DeleteProdukByID(ctx context.Context, id string) (customErr *helper.ErrorStruct)
+	GetProdukVariants(ctx context.Context, produkId string) (res []*dto.ProdukVariantResp, customErr *helper.ErrorStruct)
+	GetProdukVariantById(ctx context.Context, produkId, id string) (res *dto.ProdukVariantResp, customErr *helper.ErrorStruct)
+	CreateProdukVariant(ctx context.Context, produkId string, data *dto.ProdukVariantCreateReq, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct)
+	UpdateProdukVariantById(ctx context.Context, produkId, id string, data *dto.ProdukVariantUpdateReq, photos []*multipart.FileHeader) (customErr *helper.ErrorStruct)
+	DeleteProdukVariantById(ctx context.Context, produkId, id string) (customErr *helper.ErrorStruct)
+}
+
+// produkSortFields lists the columns the produk data can be sorted by
+var produkSortFields = pagination.SortFields{
+	"harga":      "harga_konsumen",
+	"created_at": "created_at",
+	"nama":       "nama_produk",
+	"stok":       "stok",
 }
 
 type ProdukUseCaseImpl struct {
 	produkRepository repository.ProdukRepository
+	searcher         search.ProductSearcher
+	blobStorage      storage.BlobStorage
 }
 
 // NewProdukUseCase returns the usecase for the produk group path
-func NewProdukUseCase(produkRepository repository.ProdukRepository) ProdukUseCase {
+func NewProdukUseCase(produkRepository repository.ProdukRepository, searcher search.ProductSearcher, blobStorage storage.BlobStorage) ProdukUseCase {
 	return &ProdukUseCaseImpl{
 		produkRepository: produkRepository,
+		searcher:         searcher,
+		blobStorage:      blobStorage,
 	}
 }
 
 // GetAllProduks handles the business logic to retrieve all produk data
 func (alc *ProdukUseCaseImpl) GetAllProduks(ctx context.Context, filter *dto.ProdukFilter) (res *dto.AllProdukResp, customErr *helper.ErrorStruct) {
+	page := pagination.New(filter.Page, filter.Limit)
+	cursor, order, err := produkSortFields.CursorClause(filter.Cursor, filter.Sort, filter.Order, "id asc")
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if filter.MaxHarga > 0 && filter.MinHarga > filter.MaxHarga {
+		err := errors.New("min_harga tidak boleh lebih besar dari max_harga")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	offset, limit := page.Window(cursor, order)
+	filterRepo := &daos.FilterProduk{
+		Limit:      limit,
+		Offset:     offset,
+		CategoryId: filter.CategoryId,
+		TokoId:     filter.TokoId,
+		NamaProduk: filter.NamaProduk,
+		MinHarga:   filter.MinHarga,
+		MaxHarga:   filter.MaxHarga,
+		Order:      order,
+	}
+	if cursor != nil {
+		filterRepo.CursorCreatedAt, filterRepo.CursorId = &cursor.CreatedAt, cursor.Id
+	}
+
+	resRepo, total, err := alc.produkRepository.GetAllProduks(ctx, filterRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	nextCursor := ""
+	if len(resRepo) > page.Limit {
+		resRepo = resRepo[:page.Limit]
+		last := resRepo[page.Limit-1]
+		nextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.ID}.Encode()
+	}
+
+	res, err = utils.ProdukArrayToAllProdukResp(resRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+	res.Meta = page.Meta(total)
+	res.NextCursor = nextCursor
+
+	return res, nil
+}
+
+// SearchProduks handles the business logic to retrieve the produk data matching the keyword ordered by relevance along with the facets of the matches
+func (alc *ProdukUseCaseImpl) SearchProduks(ctx context.Context, filter *dto.ProdukSearchFilter) (res *dto.ProdukSearchResp, customErr *helper.ErrorStruct) {
 	if filter.Limit < 1 {
 		filter.Limit = 10
 	}
@@ -49,24 +140,38 @@ func (alc *ProdukUseCaseImpl) GetAllProduks(ctx context.Context, filter *dto.Pro
 		filter.Page = 1
 	}
 
-	resRepo, err := alc.produkRepository.GetAllProduks(ctx, &daos.FilterProduk{
-		Limit:      filter.Limit,
-		Offset:     (filter.Page - 1) * filter.Limit,
+	if filter.MaxHarga > 0 && filter.MinHarga > filter.MaxHarga {
+		err := errors.New("min_harga tidak boleh lebih besar dari max_harga")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	resSearch, err := alc.searcher.Search(ctx, &search.Query{
+		Keyword:    filter.Q,
 		CategoryId: filter.CategoryId,
 		TokoId:     filter.TokoId,
-		NamaProduk: filter.NamaProduk,
 		MinHarga:   filter.MinHarga,
 		MaxHarga:   filter.MaxHarga,
+		Limit:      filter.Limit,
+		Offset:     (filter.Page - 1) * filter.Limit,
 	})
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
+			Code: fiber.StatusInternalServerError,
 			Err:  err,
 		}
 	}
 
-	res, err = utils.ProdukArrayToAllProdukResp(resRepo)
+	ids := []uint{}
+	for _, v := range resSearch.Hits {
+		ids = append(ids, v.IdProduk)
+	}
+
+	resRepo, err := alc.produkRepository.GetProduksByIds(ctx, ids)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -74,9 +179,18 @@ func (alc *ProdukUseCaseImpl) GetAllProduks(ctx context.Context, filter *dto.Pro
 			Err:  err,
 		}
 	}
+
+	res, err = utils.SearchResultToProdukSearchResp(resSearch, resRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
 	res.Page = filter.Page
 	res.Limit = filter.Limit
-
 	return res, nil
 }
 
@@ -106,25 +220,57 @@ func (alc *ProdukUseCaseImpl) GetProdukById(ctx context.Context, param string) (
 	return produkResp, nil
 }
 
-// CreateProduk handles the business logic to insert the produk data
-func (alc *ProdukUseCaseImpl) CreateProduk(ctx context.Context, data *dto.ProdukCreateReq, token string, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct) {
-	if errValidate := helper.Validate.Struct(data); errValidate != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
-		return 0, &helper.ErrorStruct{
-			Err:  errValidate,
-			Code: fiber.StatusBadRequest,
+// GetProdukBySlug handles the business logic to retrieve produk data having the slug. A previous slug of a renamed produk
+// returns the current slug of the produk to redirect to instead
+func (alc *ProdukUseCaseImpl) GetProdukBySlug(ctx context.Context, slug string) (res *dto.ProdukResp, redirectSlug string, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.produkRepository.GetProdukBySlug(ctx, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var resRepoHistory *daos.SlugHistory
+		resRepoHistory, err = alc.produkRepository.GetProdukSlugHistory(ctx, slug)
+		if err == nil {
+			resRepo, err = alc.produkRepository.GetProdukById(ctx, fmt.Sprint(resRepoHistory.IdRef))
+			if err == nil {
+				return nil, resRepo.Slug, nil
+			}
+		}
+	}
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data produk")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
 		}
 	}
 
-	userId, err := utils.GetJWTUserIdString(token)
+	produkResp, err := utils.ProdukToProdukResp(resRepo)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+	return produkResp, "", nil
+}
+
+// CreateProduk handles the business logic to insert the produk data
+func (alc *ProdukUseCaseImpl) CreateProduk(ctx context.Context, data *dto.ProdukCreateReq, principal *utils.Principal, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
 		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
 			Code: fiber.StatusBadRequest,
-			Err:  err,
 		}
 	}
 
+	userId := principal.UserIdString()
+
 	resRepo, err := alc.produkRepository.GetUserById(ctx, userId)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
@@ -152,12 +298,24 @@ func (alc *ProdukUseCaseImpl) CreateProduk(ctx context.Context, data *dto.Produk
 		}
 	}
 
+	berat := 0
+	if data.Berat != "" {
+		berat, err = strconv.Atoi(data.Berat)
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+	}
+
 	idProduk, err := alc.produkRepository.CreateProduk(ctx, &daos.Produk{
 		NamaProduk:    data.NamaProduk,
-		Slug:          strings.Replace(strings.ToLower(data.NamaProduk), " ", "-", -1),
 		HargaKonsumen: data.HargaKonsumen,
 		HargaReseller: data.HargaReseller,
 		Stok:          stok,
+		Berat:         berat,
 		Deskripsi:     data.Deskripsi,
 		IdCategory:    uint(idCategory),
 		IdToko:        resRepo.Toko.ID,
@@ -172,8 +330,8 @@ func (alc *ProdukUseCaseImpl) CreateProduk(ctx context.Context, data *dto.Produk
 
 	if len(photos) > 0 {
 		for _, fileHeader := range photos {
-			internalFilepath := fmt.Sprintf("%s%d%s", utils.ProdukImagesPath, time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-			err = utils.SaveMultiFormImage(fileHeader, internalFilepath, 1000000, map[string]struct{}{
+			keyPrefix := fmt.Sprintf("%s%d", utils.ProdukImagesKey, time.Now().UnixNano())
+			sizes, err := utils.SaveMultiFormImageSizes(ctx, alc.blobStorage, fileHeader, keyPrefix, 1000000, map[string]struct{}{
 				"image/jpg":  {},
 				"image/png":  {},
 				"image/jpeg": {},
@@ -188,11 +346,13 @@ func (alc *ProdukUseCaseImpl) CreateProduk(ctx context.Context, data *dto.Produk
 
 			alc.produkRepository.CreateFotoProduk(ctx, &daos.FotoProduk{
 				IdProduk: idProduk,
-				Url:      internalFilepath[1:],
+				Url:      sizes[imaging.SizeLarge].Url,
+				Sizes:    sizes,
 			})
 		}
 	}
 
+	alc.reindexProduk(ctx, idProduk)
 	return idProduk, nil
 }
 
@@ -217,7 +377,6 @@ func (alc *ProdukUseCaseImpl) UpdateProdukByID(ctx context.Context, data *dto.Pr
 
 	produkData := &daos.Produk{
 		NamaProduk:    data.NamaProduk,
-		Slug:          strings.ReplaceAll(strings.ToLower(data.NamaProduk), " ", "-"),
 		HargaReseller: data.HargaReseller,
 		HargaKonsumen: data.HargaKonsumen,
 		Deskripsi:     data.Deskripsi,
@@ -235,6 +394,18 @@ func (alc *ProdukUseCaseImpl) UpdateProdukByID(ctx context.Context, data *dto.Pr
 		produkData.Stok = stok
 	}
 
+	if data.Berat != "" {
+		berat, err := strconv.Atoi(data.Berat)
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+		produkData.Berat = berat
+	}
+
 	if data.CategoryId != "" {
 		categoryId, err := strconv.Atoi(data.CategoryId)
 		if err != nil {
@@ -259,12 +430,12 @@ func (alc *ProdukUseCaseImpl) UpdateProdukByID(ctx context.Context, data *dto.Pr
 	if len(photos) > 0 {
 		for _, v := range resRepo.FotoProduks {
 			alc.produkRepository.DeleteFotoProduk(ctx, v)
-			os.Remove(fmt.Sprintf(".%s", v.Url))
+			utils.DeleteStoredImages(ctx, alc.blobStorage, v.Urls()...)
 		}
 
 		for _, fileHeader := range photos {
-			internalFilepath := fmt.Sprintf("%s%d%s", utils.ProdukImagesPath, time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-			err = utils.SaveMultiFormImage(fileHeader, internalFilepath, 1000000, map[string]struct{}{
+			keyPrefix := fmt.Sprintf("%s%d", utils.ProdukImagesKey, time.Now().UnixNano())
+			sizes, err := utils.SaveMultiFormImageSizes(ctx, alc.blobStorage, fileHeader, keyPrefix, 1000000, map[string]struct{}{
 				"image/jpg":  {},
 				"image/png":  {},
 				"image/jpeg": {},
@@ -279,11 +450,13 @@ func (alc *ProdukUseCaseImpl) UpdateProdukByID(ctx context.Context, data *dto.Pr
 
 			alc.produkRepository.CreateFotoProduk(ctx, &daos.FotoProduk{
 				IdProduk: resRepo.ID,
-				Url:      internalFilepath[1:],
+				Url:      sizes[imaging.SizeLarge].Url,
+				Sizes:    sizes,
 			})
 		}
 	}
 
+	alc.reindexProduk(ctx, resRepo.ID)
 	return nil
 }
 
@@ -308,8 +481,400 @@ func (alc *ProdukUseCaseImpl) DeleteProdukByID(ctx context.Context, id string) (
 
 	for _, v := range resRepo.FotoProduks {
 		alc.produkRepository.DeleteFotoProduk(ctx, v)
-		os.Remove(fmt.Sprintf(".%s", v.Url))
+		utils.DeleteStoredImages(ctx, alc.blobStorage, v.Urls()...)
+	}
+
+	if err := alc.searcher.Remove(ctx, resRepo.ID); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	}
+
+	return nil
+}
+
+// GetProdukVariants handles the business logic to retrieve all variant data of the produk
+func (alc *ProdukUseCaseImpl) GetProdukVariants(ctx context.Context, produkId string) (res []*dto.ProdukVariantResp, customErr *helper.ErrorStruct) {
+	resRepo, customErr := alc.getProduk(ctx, produkId)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	res = []*dto.ProdukVariantResp{}
+	for _, v := range resRepo.Variants {
+		res = append(res, utils.ProdukVariantToProdukVariantResp(v, resRepo))
+	}
+
+	return res, nil
+}
+
+// GetProdukVariantById handles the business logic to retrieve the variant data having the id of the produk
+func (alc *ProdukUseCaseImpl) GetProdukVariantById(ctx context.Context, produkId, id string) (res *dto.ProdukVariantResp, customErr *helper.ErrorStruct) {
+	resRepoProduk, customErr := alc.getProduk(ctx, produkId)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	resRepo, customErr := alc.getProdukVariant(ctx, produkId, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	return utils.ProdukVariantToProdukVariantResp(resRepo, resRepoProduk), nil
+}
+
+// CreateProdukVariant handles the business logic to insert the variant data of the produk
+func (alc *ProdukUseCaseImpl) CreateProdukVariant(ctx context.Context, produkId string, data *dto.ProdukVariantCreateReq, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepoProduk, customErr := alc.getProduk(ctx, produkId)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	stok, err := strconv.Atoi(data.Stok)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	variantData := &daos.ProdukVariant{
+		IdProduk: resRepoProduk.ID,
+		Sku:      strings.TrimSpace(data.Sku),
+		Stok:     stok,
+	}
+
+	variantData.Opsi, customErr = alc.validateProdukVariant(ctx, resRepoProduk, nil, variantData.Sku, data.Opsi)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	if data.HargaReseller > 0 {
+		variantData.HargaReseller = &data.HargaReseller
+	}
+
+	if data.HargaKonsumen > 0 {
+		variantData.HargaKonsumen = &data.HargaKonsumen
+	}
+
+	res, err = alc.produkRepository.CreateProdukVariant(ctx, variantData)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	for _, fileHeader := range photos {
+		key := fmt.Sprintf("%s%d%s", utils.ProdukImagesKey, time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
+		url, err := utils.SaveMultiFormImage(ctx, alc.blobStorage, fileHeader, key, 1000000, map[string]struct{}{
+			"image/jpg":  {},
+			"image/png":  {},
+			"image/jpeg": {},
+		})
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		alc.produkRepository.CreateFotoProdukVariant(ctx, &daos.FotoProdukVariant{
+			IdProdukVariant: res,
+			Url:             url,
+		})
+	}
+
+	return res, nil
+}
+
+// UpdateProdukVariantById handles the business logic to update the variant data having the id of the produk
+func (alc *ProdukUseCaseImpl) UpdateProdukVariantById(ctx context.Context, produkId, id string, data *dto.ProdukVariantUpdateReq, photos []*multipart.FileHeader) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	resRepoProduk, customErr := alc.getProduk(ctx, produkId)
+	if customErr != nil {
+		return customErr
+	}
+
+	resRepo, customErr := alc.getProdukVariant(ctx, produkId, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	variantData := &daos.ProdukVariant{
+		Sku: strings.TrimSpace(data.Sku),
+	}
+	columns := []string{}
+
+	if variantData.Sku != "" || data.Opsi != "" {
+		variantData.Opsi, customErr = alc.validateProdukVariant(ctx, resRepoProduk, resRepo, variantData.Sku, data.Opsi)
+		if customErr != nil {
+			return customErr
+		}
+	}
+
+	if variantData.Sku != "" {
+		columns = append(columns, "sku")
+	}
+
+	if variantData.Opsi != nil {
+		columns = append(columns, "opsi")
+	}
+
+	if data.Stok != "" {
+		stok, err := strconv.Atoi(data.Stok)
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+		variantData.Stok = stok
+		columns = append(columns, "stok")
+	}
+
+	if data.HargaReseller > 0 {
+		variantData.HargaReseller = &data.HargaReseller
+		columns = append(columns, "harga_reseller")
+	}
+
+	if data.HargaKonsumen > 0 {
+		variantData.HargaKonsumen = &data.HargaKonsumen
+		columns = append(columns, "harga_konsumen")
+	}
+
+	err := alc.produkRepository.UpdateProdukVariant(ctx, resRepo, variantData, columns)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if len(photos) > 0 {
+		for _, v := range resRepo.FotoProdukVariants {
+			alc.produkRepository.DeleteFotoProdukVariant(ctx, v)
+			utils.DeleteStoredImages(ctx, alc.blobStorage, v.Url)
+		}
+
+		for _, fileHeader := range photos {
+			key := fmt.Sprintf("%s%d%s", utils.ProdukImagesKey, time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
+			url, err := utils.SaveMultiFormImage(ctx, alc.blobStorage, fileHeader, key, 1000000, map[string]struct{}{
+				"image/jpg":  {},
+				"image/png":  {},
+				"image/jpeg": {},
+			})
+			if err != nil {
+				helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+				return &helper.ErrorStruct{
+					Code: fiber.StatusBadRequest,
+					Err:  err,
+				}
+			}
+
+			alc.produkRepository.CreateFotoProdukVariant(ctx, &daos.FotoProdukVariant{
+				IdProdukVariant: resRepo.ID,
+				Url:             url,
+			})
+		}
+	}
+
+	return nil
+}
+
+// DeleteProdukVariantById handles the business logic to delete the variant data having the id of the produk
+func (alc *ProdukUseCaseImpl) DeleteProdukVariantById(ctx context.Context, produkId, id string) (customErr *helper.ErrorStruct) {
+	resRepo, customErr := alc.getProdukVariant(ctx, produkId, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	err := alc.produkRepository.DeleteProdukVariant(ctx, resRepo)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	for _, v := range resRepo.FotoProdukVariants {
+		alc.produkRepository.DeleteFotoProdukVariant(ctx, v)
+		utils.DeleteStoredImages(ctx, alc.blobStorage, v.Url)
 	}
 
 	return nil
 }
+
+// reindexProduk puts the latest produk data having the id into the search index
+func (alc *ProdukUseCaseImpl) reindexProduk(ctx context.Context, id uint) {
+	resRepo, err := alc.produkRepository.GetProdukById(ctx, strconv.Itoa(int(id)))
+	if err == nil {
+		err = alc.searcher.Index(ctx, search.DocumentFromProduk(resRepo))
+	}
+
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	}
+}
+
+// getProduk returns the produk having the id along with its variants
+func (alc *ProdukUseCaseImpl) getProduk(ctx context.Context, id string) (res *daos.Produk, customErr *helper.ErrorStruct) {
+	res, err := alc.produkRepository.GetProdukById(ctx, id)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data produk")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// getProdukVariant returns the variant having the id of the produk
+func (alc *ProdukUseCaseImpl) getProdukVariant(ctx context.Context, produkId, id string) (res *daos.ProdukVariant, customErr *helper.ErrorStruct) {
+	res, err := alc.produkRepository.GetProdukVariantById(ctx, produkId, id)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data variant")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// validateProdukVariant checks the sku is not taken by another variant and the opsi, when given, follows the option axes
+// the other variants of the produk already use without repeating any of them, returning the parsed opsi
+func (alc *ProdukUseCaseImpl) validateProdukVariant(ctx context.Context, produk *daos.Produk, current *daos.ProdukVariant, sku, opsiJSON string) (res map[string]string, customErr *helper.ErrorStruct) {
+	var err error
+	if sku != "" {
+		existing, errRepo := alc.produkRepository.GetProdukVariantBySku(ctx, sku)
+		if errRepo == nil && (current == nil || existing.ID != current.ID) {
+			err = fmt.Errorf("sku %s sudah digunakan", sku)
+		} else if errRepo != nil && !errors.Is(errRepo, gorm.ErrRecordNotFound) {
+			err = errRepo
+		}
+	}
+
+	if err == nil && opsiJSON != "" {
+		res, err = parseProdukVariantOpsi(opsiJSON)
+	}
+
+	if err == nil && res != nil {
+		for _, v := range produk.Variants {
+			if current != nil && v.ID == current.ID {
+				continue
+			}
+
+			if !sameOpsiAxes(v.Opsi, res) {
+				err = fmt.Errorf("opsi varian harus memiliki pilihan %s", strings.Join(opsiAxes(v.Opsi), ", "))
+				break
+			}
+
+			if reflect.DeepEqual(v.Opsi, res) {
+				err = fmt.Errorf("varian dengan opsi yang sama sudah ada (sku %s)", v.Sku)
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// parseProdukVariantOpsi parses the opsi sent as a json object of option axis and value, e.g. {"ukuran":"L","warna":"Merah"}
+func parseProdukVariantOpsi(opsiJSON string) (res map[string]string, err error) {
+	opsi := map[string]string{}
+	if err := json.Unmarshal([]byte(opsiJSON), &opsi); err != nil {
+		return nil, errors.New(`opsi harus berupa objek json, contoh {"ukuran":"L","warna":"Merah"}`)
+	}
+
+	res = map[string]string{}
+	for axis, value := range opsi {
+		axis, value = strings.ToLower(strings.TrimSpace(axis)), strings.TrimSpace(value)
+		if axis == "" || value == "" {
+			return nil, errors.New("pilihan dan nilai opsi varian tidak boleh kosong")
+		}
+		res[axis] = value
+	}
+
+	if len(res) == 0 {
+		return nil, errors.New("opsi varian tidak boleh kosong")
+	}
+
+	return res, nil
+}
+
+// opsiAxes returns the sorted option axes of the opsi
+func opsiAxes(opsi map[string]string) (res []string) {
+	for axis := range opsi {
+		res = append(res, axis)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// sameOpsiAxes checks whether both opsi use the same option axes
+func sameOpsiAxes(a, b map[string]string) bool {
+	return reflect.DeepEqual(opsiAxes(a), opsiAxes(b))
+}
+
+// findProdukVariant returns the variant having the variantid of the produk, requiring one to be picked when the produk has variants
+func findProdukVariant(produk *daos.Produk, variantId uint) (res *daos.ProdukVariant, err error) {
+	if variantId == 0 {
+		if len(produk.Variants) > 0 {
+			return nil, fmt.Errorf("pilih varian produk %s", produk.NamaProduk)
+		}
+
+		return nil, nil
+	}
+
+	for _, v := range produk.Variants {
+		if v.ID == variantId {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("varian produk %s tidak ditemukan", produk.NamaProduk)
+}
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/storage"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ReviewUseCase interface {
+	GetProdukReviews(ctx context.Context, produkId string, filter *dto.ReviewFilter) (res *dto.AllReviewResp, customErr *helper.ErrorStruct)
+	GetReviewById(ctx context.Context, id string) (res *dto.ReviewResp, customErr *helper.ErrorStruct)
+	CreateReview(ctx context.Context, principal *utils.Principal, data *dto.ReviewCreateReq, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct)
+	ReplyReview(ctx context.Context, principal *utils.Principal, id string, data *dto.ReviewReplyReq) (customErr *helper.ErrorStruct)
+}
+
+// reviewSortFields lists the columns the review data can be sorted by
+var reviewSortFields = pagination.SortFields{
+	"rating":     "rating",
+	"created_at": "created_at",
+}
+
+type ReviewUseCaseImpl struct {
+	reviewRepository repository.ReviewRepository
+	blobStorage      storage.BlobStorage
+}
+
+// NewReviewUseCase returns the usecase for the review group path
+func NewReviewUseCase(reviewRepository repository.ReviewRepository, blobStorage storage.BlobStorage) ReviewUseCase {
+	return &ReviewUseCaseImpl{
+		reviewRepository: reviewRepository,
+		blobStorage:      blobStorage,
+	}
+}
+
+// GetProdukReviews handles the business logic to retrieve the review data of the produk having the id along with its rating
+func (alc *ReviewUseCaseImpl) GetProdukReviews(ctx context.Context, produkId string, filter *dto.ReviewFilter) (res *dto.AllReviewResp, customErr *helper.ErrorStruct) {
+	page := pagination.New(filter.Page, filter.Limit)
+	order, err := reviewSortFields.Clause(filter.Sort, filter.Order, pagination.KeysetClause)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	resRepoProduk, err := alc.reviewRepository.GetProdukById(ctx, produkId)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("produk tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	resRepo, total, err := alc.reviewRepository.GetReviewsByProdukId(ctx, &daos.FilterReview{
+		Limit:    page.Limit,
+		Offset:   page.Offset(),
+		IdProduk: resRepoProduk.ID,
+		Rating:   filter.Rating,
+		Order:    order,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	resRepoRatings, err := alc.reviewRepository.GetRatingCountsByProdukId(ctx, resRepoProduk.ID)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	res = &dto.AllReviewResp{
+		Meta:   page.Meta(total),
+		Rating: utils.RatingCountsToRatingResp(resRepoRatings),
+		Data:   []*dto.ReviewResp{},
+	}
+	for _, v := range resRepo {
+		res.Data = append(res.Data, utils.ReviewToReviewResp(v))
+	}
+
+	return res, nil
+}
+
+// GetReviewById handles the business logic to retrieve review data having the id
+func (alc *ReviewUseCaseImpl) GetReviewById(ctx context.Context, id string) (res *dto.ReviewResp, customErr *helper.ErrorStruct) {
+	resRepo, customErr := alc.getReview(ctx, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	return utils.ReviewToReviewResp(resRepo), nil
+}
+
+// CreateReview handles the business logic to review the produk bought on a detailtrx of the current user, once the trx has been delivered
+func (alc *ReviewUseCaseImpl) CreateReview(ctx context.Context, principal *utils.Principal, data *dto.ReviewCreateReq, photos []*multipart.FileHeader) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	userId := principal.UserId
+
+	resRepoDetailTrx, err := alc.reviewRepository.GetDetailTrxById(ctx, data.DetailTrxId)
+	if err == nil && resRepoDetailTrx.Trx.IdUser != userId {
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data detail trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	if resRepoDetailTrx.Trx.Status != daos.TrxStatusDelivered {
+		err := fmt.Errorf("ulasan hanya dapat diberikan setelah trx berstatus %s", daos.TrxStatusDelivered)
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	// the photos are stored before the review is inserted so a refused photo leaves no review behind
+	fotoReviews := []*daos.FotoReview{}
+	urls := []string{}
+	for _, fileHeader := range photos {
+		key := fmt.Sprintf("%s%d%s", utils.ReviewImagesKey, time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
+		url, err := utils.SaveMultiFormImage(ctx, alc.blobStorage, fileHeader, key, 1000000, map[string]struct{}{
+			"image/jpg":  {},
+			"image/png":  {},
+			"image/jpeg": {},
+		})
+		if err != nil {
+			utils.DeleteStoredImages(ctx, alc.blobStorage, urls...)
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, err.Error())
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		urls = append(urls, url)
+		fotoReviews = append(fotoReviews, &daos.FotoReview{
+			Url: url,
+		})
+	}
+
+	res, err = alc.reviewRepository.CreateReview(ctx, &daos.Review{
+		IdDetailTrx: resRepoDetailTrx.ID,
+		IdUser:      userId,
+		IdProduk:    resRepoDetailTrx.LogProduk.IdProduk,
+		IdToko:      resRepoDetailTrx.IdToko,
+		Rating:      data.Rating,
+		Ulasan:      strings.TrimSpace(data.Ulasan),
+		FotoReviews: fotoReviews,
+	})
+	if err != nil {
+		utils.DeleteStoredImages(ctx, alc.blobStorage, urls...)
+
+		code := fiber.StatusBadRequest
+		if errors.Is(err, repository.ErrReviewExists) {
+			code = fiber.StatusConflict
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// ReplyReview handles the business logic to reply to the review having the id on behalf of the toko of the current user
+func (alc *ReviewUseCaseImpl) ReplyReview(ctx context.Context, principal *utils.Principal, id string, data *dto.ReviewReplyReq) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	userId := principal.UserId
+
+	resRepo, customErr := alc.getReview(ctx, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	resRepoToko, err := alc.reviewRepository.GetTokoByUserId(ctx, strconv.Itoa(int(userId)))
+	if err != nil || resRepoToko.ID != resRepo.IdToko {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, "Error : unauthorized")
+		return &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("you are unauthorized"),
+		}
+	}
+
+	dibalasAt := time.Now()
+	err = alc.reviewRepository.UpdateReview(ctx, resRepo, &daos.Review{
+		Balasan:   strings.TrimSpace(data.Balasan),
+		DibalasAt: &dibalasAt,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// getReview returns the review data having the id, reporting a missing review as not found
+func (alc *ReviewUseCaseImpl) getReview(ctx context.Context, id string) (res *daos.Review, customErr *helper.ErrorStruct) {
+	res, err := alc.reviewRepository.GetReviewById(ctx, id)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data review")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
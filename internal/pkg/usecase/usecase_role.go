@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type RoleUseCase interface {
+	GetAllRoles(ctx context.Context) (res []*dto.RoleResp, customErr *helper.ErrorStruct)
+	GetUserRoles(ctx context.Context, idUser string) (res *dto.UserRolesResp, customErr *helper.ErrorStruct)
+	GrantUserRole(ctx context.Context, idUser string, data *dto.UserRoleReq) (res *dto.UserRolesResp, customErr *helper.ErrorStruct)
+	RevokeUserRole(ctx context.Context, idUser string, role string) (res *dto.UserRolesResp, customErr *helper.ErrorStruct)
+}
+
+type RoleUseCaseImpl struct {
+	userRoleRepository repository.UserRoleRepository
+}
+
+// NewRoleUseCase returns the usecase for the role group path
+func NewRoleUseCase(userRoleRepository repository.UserRoleRepository) RoleUseCase {
+	return &RoleUseCaseImpl{
+		userRoleRepository: userRoleRepository,
+	}
+}
+
+// GetAllRoles handles the business logic to retrieve the roles along with the permissions they grant
+func (alc *RoleUseCaseImpl) GetAllRoles(ctx context.Context) (res []*dto.RoleResp, customErr *helper.ErrorStruct) {
+	res = []*dto.RoleResp{}
+	for _, role := range rbac.Roles() {
+		res = append(res, &dto.RoleResp{
+			Role:        role,
+			Permissions: rbac.Permissions(role),
+		})
+	}
+
+	return res, nil
+}
+
+// GetUserRoles handles the business logic to retrieve the roles of the user having the id
+func (alc *RoleUseCaseImpl) GetUserRoles(ctx context.Context, idUser string) (res *dto.UserRolesResp, customErr *helper.ErrorStruct) {
+	user, customErr := alc.getUser(ctx, idUser)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	return userToUserRolesResp(user), nil
+}
+
+// GrantUserRole handles the business logic to grant the role to the user having the id, granting a role the user already has succeeds
+func (alc *RoleUseCaseImpl) GrantUserRole(ctx context.Context, idUser string, data *dto.UserRoleReq) (res *dto.UserRolesResp, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	if !rbac.ValidRole(data.Role) {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  fmt.Errorf("role %q tidak valid", data.Role),
+		}
+	}
+
+	user, customErr := alc.getUser(ctx, idUser)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if _, err := alc.userRoleRepository.CreateUserRole(ctx, &daos.UserRole{
+		IdUser: user.ID,
+		Role:   data.Role,
+	}); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return alc.GetUserRoles(ctx, idUser)
+}
+
+// RevokeUserRole handles the business logic to revoke the role of the user having the id
+func (alc *RoleUseCaseImpl) RevokeUserRole(ctx context.Context, idUser string, role string) (res *dto.UserRolesResp, customErr *helper.ErrorStruct) {
+	user, customErr := alc.getUser(ctx, idUser)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if err := alc.userRoleRepository.DeleteUserRole(ctx, user.ID, role); err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = fmt.Errorf("user tidak memiliki role %q", role)
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return alc.GetUserRoles(ctx, idUser)
+}
+
+// getUser returns the user having the id along with its roles
+func (alc *RoleUseCaseImpl) getUser(ctx context.Context, idUser string) (res *daos.User, customErr *helper.ErrorStruct) {
+	id, err := strconv.ParseUint(idUser, 10, 64)
+	if err != nil {
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errors.New("id user tidak valid"),
+		}
+	}
+
+	res, err = alc.userRoleRepository.GetUserById(ctx, uint(id))
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("user tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// userToUserRolesResp returns the roles of the user in the response format
+func userToUserRolesResp(user *daos.User) *dto.UserRolesResp {
+	res := &dto.UserRolesResp{
+		IdUser: user.ID,
+		Roles:  []string{},
+	}
+	for _, v := range user.Roles {
+		res.Roles = append(res.Roles, v.Role)
+	}
+
+	return res
+}
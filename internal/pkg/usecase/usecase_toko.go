@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
-	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/storage"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
@@ -20,38 +22,53 @@ import (
 
 type TokoUseCase interface {
 	GetAllTokos(ctx context.Context, queries *dto.TokoFilter) (res *dto.AllTokoResp, err *helper.ErrorStruct)
-	GetTokoById(ctx context.Context, param, header string) (res *dto.TokoResp, err *helper.ErrorStruct)
-	GetMyToko(ctx context.Context, header string) (res *dto.TokoResp, err *helper.ErrorStruct)
+	GetTokoById(ctx context.Context, param string) (res *dto.TokoResp, err *helper.ErrorStruct)
+	GetTokoBySlug(ctx context.Context, slug string) (res *dto.TokoResp, redirectSlug string, customErr *helper.ErrorStruct)
+	GetMyToko(ctx context.Context, principal *utils.Principal) (res *dto.TokoResp, err *helper.ErrorStruct)
+	GetMyOrders(ctx context.Context, principal *utils.Principal, filter *dto.TokoOrderFilter) (res *dto.AllTokoOrderResp, err *helper.ErrorStruct)
 	UpdateTokoByID(ctx context.Context, id string, photo *multipart.FileHeader, data *dto.TokoUpdateReq) (customErr *helper.ErrorStruct)
+	FollowToko(ctx context.Context, principal *utils.Principal, tokoId string) (res uint, customErr *helper.ErrorStruct)
+	UnfollowToko(ctx context.Context, principal *utils.Principal, tokoId string) (customErr *helper.ErrorStruct)
+}
+
+// tokoSortFields lists the columns the toko data can be sorted by
+var tokoSortFields = pagination.SortFields{
+	"nama":       "nama_toko",
+	"created_at": "created_at",
 }
 
 type TokoUseCaseImpl struct {
 	tokoRepository repository.TokoRepository
+	blobStorage    storage.BlobStorage
 	jwtSecret      string
 }
 
 // NewTokoUseCase returns the usecase for the toko group path
-func NewTokoUseCase(tokoRepository repository.TokoRepository, jwtSecret string) TokoUseCase {
+func NewTokoUseCase(tokoRepository repository.TokoRepository, blobStorage storage.BlobStorage, jwtSecret string) TokoUseCase {
 	return &TokoUseCaseImpl{
 		tokoRepository: tokoRepository,
+		blobStorage:    blobStorage,
 		jwtSecret:      jwtSecret,
 	}
 }
 
 // GetAllTokos handles the business logic to retrieve all toko data
 func (alc *TokoUseCaseImpl) GetAllTokos(ctx context.Context, queries *dto.TokoFilter) (res *dto.AllTokoResp, err *helper.ErrorStruct) {
-	if queries.Limit < 1 {
-		queries.Limit = 10
-	}
-
-	if queries.Page < 1 {
-		queries.Page = 1
+	page := pagination.New(queries.Page, queries.Limit)
+	order, errSort := tokoSortFields.Clause(queries.Sort, queries.Order, "id asc")
+	if errSort != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errSort.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errSort,
+		}
 	}
 
-	resRepo, errRepo := alc.tokoRepository.GetAllTokos(ctx, daos.FilterToko{
-		Limit:    queries.Limit,
-		Offset:   (queries.Page - 1) * queries.Limit,
+	resRepo, total, errRepo := alc.tokoRepository.GetAllTokos(ctx, daos.FilterToko{
+		Limit:    page.Limit,
+		Offset:   page.Offset(),
 		NamaToko: queries.NamaToko,
+		Order:    order,
 	})
 	if errors.Is(errRepo, gorm.ErrRecordNotFound) {
 		return res, &helper.ErrorStruct{
@@ -69,13 +86,12 @@ func (alc *TokoUseCaseImpl) GetAllTokos(ctx context.Context, queries *dto.TokoFi
 	}
 
 	res = utils.TokoArrayToAllTokoResp(resRepo)
-	res.Page = queries.Page
-	res.Limit = queries.Limit
+	res.Meta = page.Meta(total)
 	return res, nil
 }
 
 // GetTokoById handles the business logic to retrieve toko data having the id
-func (alc *TokoUseCaseImpl) GetTokoById(ctx context.Context, param, header string) (res *dto.TokoResp, err *helper.ErrorStruct) {
+func (alc *TokoUseCaseImpl) GetTokoById(ctx context.Context, param string) (res *dto.TokoResp, err *helper.ErrorStruct) {
 	resRepo, errRepo := alc.tokoRepository.GetTokoById(ctx, param)
 	if errRepo != nil {
 		if errRepo == gorm.ErrRecordNotFound {
@@ -93,16 +109,40 @@ func (alc *TokoUseCaseImpl) GetTokoById(ctx context.Context, param, header strin
 	return res, nil
 }
 
-// GetMyToko handles the business logic to retrieve toko data of the user specified on the token
-func (alc *TokoUseCaseImpl) GetMyToko(ctx context.Context, token string) (res *dto.TokoResp, err *helper.ErrorStruct) {
-	userId, errGetClaims := utils.GetJWTUserIdString(token)
-	if errGetClaims != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errGetClaims.Error()))
-		return res, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  errGetClaims,
+// GetTokoBySlug handles the business logic to retrieve toko data having the slug. A previous slug of a renamed toko
+// returns the current slug of the toko to redirect to instead
+func (alc *TokoUseCaseImpl) GetTokoBySlug(ctx context.Context, slug string) (res *dto.TokoResp, redirectSlug string, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.tokoRepository.GetTokoBySlug(ctx, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var resRepoHistory *daos.SlugHistory
+		resRepoHistory, err = alc.tokoRepository.GetTokoSlugHistory(ctx, slug)
+		if err == nil {
+			resRepo, err = alc.tokoRepository.GetTokoById(ctx, fmt.Sprint(resRepoHistory.IdRef))
+			if err == nil {
+				return nil, resRepo.Slug, nil
+			}
 		}
 	}
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("toko tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return utils.TokoToTokoResp(resRepo), "", nil
+}
+
+// GetMyToko handles the business logic to retrieve toko data of the principal
+func (alc *TokoUseCaseImpl) GetMyToko(ctx context.Context, principal *utils.Principal) (res *dto.TokoResp, err *helper.ErrorStruct) {
+	userId := principal.UserIdString()
 
 	resRepo, errRepo := alc.tokoRepository.GetTokoByUserID(ctx, userId)
 	if errRepo != nil {
@@ -121,6 +161,92 @@ func (alc *TokoUseCaseImpl) GetMyToko(ctx context.Context, token string) (res *d
 	return res, nil
 }
 
+// GetMyOrders handles the business logic to retrieve the detailtrx data sold by the toko of the current user
+func (alc *TokoUseCaseImpl) GetMyOrders(ctx context.Context, principal *utils.Principal, filter *dto.TokoOrderFilter) (res *dto.AllTokoOrderResp, err *helper.ErrorStruct) {
+	userId := principal.UserIdString()
+
+	resRepoToko, errRepo := alc.tokoRepository.GetTokoByUserID(ctx, userId)
+	if errRepo != nil {
+		if errRepo == gorm.ErrRecordNotFound {
+			errRepo = errors.New("toko tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errRepo.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errRepo,
+		}
+	}
+
+	if filter.Limit < 1 {
+		filter.Limit = 10
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	filterRepo := &daos.FilterDetailTrx{
+		Limit:       filter.Limit,
+		Offset:      (filter.Page - 1) * filter.Limit,
+		IdToko:      resRepoToko.ID,
+		Status:      filter.Status,
+		KodeInvoice: filter.KodeInvoice,
+	}
+
+	if filter.TanggalMulai != "" {
+		tanggalMulai, errParse := utils.StringToDate(filter.TanggalMulai)
+		if errParse != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errParse.Error()))
+			return res, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  errParse,
+			}
+		}
+		filterRepo.TanggalMulai = tanggalMulai
+	}
+
+	if filter.TanggalSelesai != "" {
+		tanggalSelesai, errParse := utils.StringToDate(filter.TanggalSelesai)
+		if errParse != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errParse.Error()))
+			return res, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  errParse,
+			}
+		}
+		filterRepo.TanggalSelesai = tanggalSelesai.AddDate(0, 0, 1)
+	}
+
+	resRepo, errRepo := alc.tokoRepository.GetDetailTrxsByTokoId(ctx, filterRepo)
+	if errRepo != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errRepo.Error()))
+		return res, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errRepo,
+		}
+	}
+
+	res = &dto.AllTokoOrderResp{
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Data:  []*dto.TokoOrderResp{},
+	}
+	for _, v := range resRepo {
+		orderResp, errParse := utils.DetailTrxToTokoOrderResp(v)
+		if errParse != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errParse.Error()))
+			return nil, &helper.ErrorStruct{
+				Code: fiber.StatusInternalServerError,
+				Err:  errParse,
+			}
+		}
+		res.Data = append(res.Data, orderResp)
+	}
+
+	return res, nil
+}
+
 // UpdateTokoByID handles the business logic to update toko data having the id
 func (alc *TokoUseCaseImpl) UpdateTokoByID(ctx context.Context, id string, photo *multipart.FileHeader, data *dto.TokoUpdateReq) (customErr *helper.ErrorStruct) {
 	if errValidate := helper.Validate.Struct(data); errValidate != nil {
@@ -145,10 +271,8 @@ func (alc *TokoUseCaseImpl) UpdateTokoByID(ctx context.Context, id string, photo
 	}
 
 	if photo != nil {
-		os.Remove(fmt.Sprintf(".%s", res.UrlFoto))
-
-		internalFilepath := fmt.Sprintf("%s%d%s", utils.TokoImagesPath, time.Now().UnixNano(), filepath.Ext(photo.Filename))
-		err := utils.SaveMultiFormImage(photo, internalFilepath, 1000000, map[string]struct{}{
+		key := fmt.Sprintf("%s%d%s", utils.TokoImagesKey, time.Now().UnixNano(), filepath.Ext(photo.Filename))
+		url, err := utils.SaveMultiFormImage(ctx, alc.blobStorage, photo, key, 1000000, map[string]struct{}{
 			"image/jpg":  {},
 			"image/png":  {},
 			"image/jpeg": {},
@@ -161,10 +285,14 @@ func (alc *TokoUseCaseImpl) UpdateTokoByID(ctx context.Context, id string, photo
 			}
 		}
 
-		updatedToko.UrlFoto = internalFilepath[1:]
+		updatedToko.UrlFoto = url
 	}
 
 	if errRepo := alc.tokoRepository.UpdateToko(ctx, res, updatedToko); errRepo != nil {
+		if updatedToko.UrlFoto != "" {
+			utils.DeleteStoredImages(ctx, alc.blobStorage, updatedToko.UrlFoto)
+		}
+
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errRepo.Error()))
 		return &helper.ErrorStruct{
 			Code: fiber.StatusBadRequest,
@@ -172,5 +300,83 @@ func (alc *TokoUseCaseImpl) UpdateTokoByID(ctx context.Context, id string, photo
 		}
 	}
 
+	// the previous photo is only deleted once the new one replaced it
+	if updatedToko.UrlFoto != "" && res.UrlFoto != "" {
+		utils.DeleteStoredImages(ctx, alc.blobStorage, res.UrlFoto)
+	}
+
+	return nil
+}
+
+// FollowToko handles the business logic to add the toko having the id to the favourite toko of the principal
+func (alc *TokoUseCaseImpl) FollowToko(ctx context.Context, principal *utils.Principal, tokoId string) (res uint, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	resRepoToko, err := alc.tokoRepository.GetTokoById(ctx, tokoId)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("toko tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	if resRepoToko.IdUser == userId {
+		err = errors.New("tidak dapat mengikuti toko sendiri")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	res, err = alc.tokoRepository.CreateTokoFollower(ctx, &daos.TokoFollower{
+		IdUser: userId,
+		IdToko: resRepoToko.ID,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// UnfollowToko handles the business logic to remove the toko having the id from the favourite toko of the principal
+func (alc *TokoUseCaseImpl) UnfollowToko(ctx context.Context, principal *utils.Principal, tokoId string) (customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	id, err := strconv.ParseUint(tokoId, 10, 64)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  errors.New("id toko tidak valid"),
+		}
+	}
+
+	if err := alc.tokoRepository.DeleteTokoFollower(ctx, userId, uint(id)); err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("toko tidak diikuti")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeTokoRepository holds the tokos by the userid of their owner and records the filter of the detailtrx lookup
+type fakeTokoRepository struct {
+	repository.TokoRepository
+	tokos  map[string]*daos.Toko
+	filter *daos.FilterDetailTrx
+}
+
+func (f *fakeTokoRepository) GetTokoByUserID(ctx context.Context, userId string) (res *daos.Toko, err error) {
+	if res, ok := f.tokos[userId]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTokoRepository) GetDetailTrxsByTokoId(ctx context.Context, filter *daos.FilterDetailTrx) (res []*daos.DetailTrx, err error) {
+	f.filter = filter
+	return nil, nil
+}
+
+func TestGetMyOrdersOfTheTokoOfTheCaller(t *testing.T) {
+	tests := []struct {
+		name       string
+		userId     uint
+		want       int
+		wantIdToko uint
+	}{
+		{"seller", 11, 0, 1},
+		{"other seller", 12, 0, 2},
+		{"user without toko", 9, fiber.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTokoRepository{tokos: map[string]*daos.Toko{
+				"11": {Model: gorm.Model{ID: 1}},
+				"12": {Model: gorm.Model{ID: 2}},
+			}}
+			usecase := NewTokoUseCase(repo, nil, "")
+
+			_, customErr := usecase.GetMyOrders(context.Background(), &utils.Principal{UserId: tt.userId}, &dto.TokoOrderFilter{})
+			code := 0
+			if customErr != nil {
+				code = customErr.Code
+			}
+			if code != tt.want {
+				t.Errorf("GetMyOrders error = %v, want code %d", customErr, tt.want)
+			}
+
+			// the detailtrxs are looked up by the toko of the caller, never by one of the request
+			if tt.wantIdToko != 0 && (repo.filter == nil || repo.filter.IdToko != tt.wantIdToko) {
+				t.Errorf("GetMyOrders filter = %+v, want toko %d", repo.filter, tt.wantIdToko)
+			}
+			if tt.wantIdToko == 0 && repo.filter != nil {
+				t.Errorf("GetMyOrders looked up the detailtrxs of toko %d", repo.filter.IdToko)
+			}
+		})
+	}
+}
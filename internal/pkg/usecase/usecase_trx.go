@@ -5,48 +5,171 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/payment"
+	"tugas_akhir_example/internal/infrastructure/shipping"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type TrxUseCase interface {
-	GetAllTrxs(ctx context.Context, filter *dto.TrxFilter) (res *dto.AllTrxResp, customErr *helper.ErrorStruct)
-	GetTrxById(ctx context.Context, id string) (res *dto.TrxResp, customErr *helper.ErrorStruct)
-	CreateTrx(ctx context.Context, token string, data *dto.TrxCreateReq) (res uint, customErr *helper.ErrorStruct)
+	GetAllTrxs(ctx context.Context, principal *utils.Principal, filter *dto.TrxFilter) (res *dto.AllTrxResp, customErr *helper.ErrorStruct)
+	GetTrxById(ctx context.Context, principal *utils.Principal, id string) (res *dto.TrxResp, customErr *helper.ErrorStruct)
+	GetTrxByKodeInvoice(ctx context.Context, principal *utils.Principal, kodeInvoice string) (res *dto.TrxResp, customErr *helper.ErrorStruct)
+	CreateTrx(ctx context.Context, principal *utils.Principal, data *dto.TrxCreateReq) (res uint, customErr *helper.ErrorStruct)
+	CreateTrxFromCart(ctx context.Context, principal *utils.Principal, data *dto.TrxCreateReq, idCart uint) (res uint, customErr *helper.ErrorStruct)
+	QuoteShipping(ctx context.Context, principal *utils.Principal, data *dto.ShippingQuoteReq) (res *dto.ShippingQuoteResp, customErr *helper.ErrorStruct)
+	GetTrxStatusHistories(ctx context.Context, principal *utils.Principal, id string) (res []*dto.TrxStatusHistoryResp, customErr *helper.ErrorStruct)
+	UpdateTrxStatus(ctx context.Context, principal *utils.Principal, id, peran string, data *dto.TrxStatusUpdateReq) (customErr *helper.ErrorStruct)
+	GetTrxInvoicePDF(ctx context.Context, principal *utils.Principal, id string) (res []byte, kodeInvoice string, customErr *helper.ErrorStruct)
+	GetTrxPackingSlipPDF(ctx context.Context, principal *utils.Principal, id, tokoId string) (res []byte, kodeInvoice string, customErr *helper.ErrorStruct)
+}
+
+// trxSortFields lists the columns the trx data can be sorted by
+var trxSortFields = pagination.SortFields{
+	"harga":      "harga_total",
+	"created_at": "created_at",
+}
+
+// trxStatusTransitions lists the legal next statuses of each trx status along with the actors allowed to make the move
+var trxStatusTransitions = map[string]map[string][]string{
+	daos.TrxStatusPending: {
+		daos.TrxStatusPaid:      {daos.TrxActorAdmin, daos.TrxActorSystem},
+		daos.TrxStatusCancelled: {daos.TrxActorBuyer, daos.TrxActorSeller, daos.TrxActorAdmin, daos.TrxActorSystem},
+	},
+	daos.TrxStatusPaid: {
+		daos.TrxStatusPacked:    {daos.TrxActorSeller, daos.TrxActorAdmin},
+		daos.TrxStatusCancelled: {daos.TrxActorSeller, daos.TrxActorAdmin},
+		daos.TrxStatusRefunded:  {daos.TrxActorAdmin},
+	},
+	daos.TrxStatusPacked: {
+		daos.TrxStatusShipped:   {daos.TrxActorSeller, daos.TrxActorAdmin},
+		daos.TrxStatusCancelled: {daos.TrxActorSeller, daos.TrxActorAdmin},
+	},
+	daos.TrxStatusShipped: {
+		daos.TrxStatusDelivered: {daos.TrxActorBuyer, daos.TrxActorAdmin},
+	},
+	daos.TrxStatusDelivered: {
+		daos.TrxStatusRefunded: {daos.TrxActorAdmin},
+	},
+	daos.TrxStatusCancelled: {},
+	daos.TrxStatusRefunded:  {},
+}
+
+// codTrxStatusTransitions lists the extra transitions of trx paid on delivery, which are packed before any payment is made
+var codTrxStatusTransitions = map[string]map[string][]string{
+	daos.TrxStatusPending: {
+		daos.TrxStatusPacked: {daos.TrxActorSeller, daos.TrxActorAdmin},
+	},
+}
+
+// validateTrxStatusTransition checks whether the actor is allowed to move a trx paid with the method bayar from the current status to the next status
+func validateTrxStatusTransition(current, next, peran, methodBayar string) error {
+	nextStatuses, ok := trxStatusTransitions[current]
+	if !ok {
+		return fmt.Errorf("status trx %s tidak dikenal", current)
+	}
+
+	actors, ok := nextStatuses[next]
+	if !ok && methodBayar == payment.MethodCOD {
+		actors, ok = codTrxStatusTransitions[current][next]
+	}
+
+	if !ok {
+		return fmt.Errorf("status trx tidak dapat diubah dari %s ke %s", current, next)
+	}
+
+	for _, v := range actors {
+		if v == peran {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s tidak dapat mengubah status trx dari %s ke %s", peran, current, next)
+}
+
+// kodeInvoicePrefix returns the invoice prefix of a trx made on the date, e.g. INV/20261018/TOKO12.
+// The repository appends the sequence number of the prefix, so every toko gets its own daily counter
+// while trx spanning several tokos share the MULTI counter
+func kodeInvoicePrefix(date time.Time, detailTrxs []*daos.DetailTrx) string {
+	toko := "MULTI"
+	if len(detailTrxs) > 0 {
+		toko = fmt.Sprintf("TOKO%d", detailTrxs[0].IdToko)
+	}
+
+	for _, v := range detailTrxs {
+		if v.IdToko != detailTrxs[0].IdToko {
+			toko = "MULTI"
+			break
+		}
+	}
+
+	return fmt.Sprintf("INV/%s/%s", date.Format("20060102"), toko)
+}
+
+// tokoShipment holds the ordered produks sent by one toko along with the rates of sending them
+type tokoShipment struct {
+	toko   *daos.Toko
+	asal   string
+	tujuan string
+	berat  int
+	rates  []*shipping.Rate
 }
 
 type TrxUseCaseImpl struct {
-	trxRepository repository.TrxRepository
+	trxRepository           repository.TrxRepository
+	paymentProviders        *payment.Registry
+	shippingProvider        shipping.ShippingRateProvider
+	requireVerifiedCheckout bool
 }
 
-// NewTrxUseCase returns the usecase for the trx group path
-func NewTrxUseCase(trxRepository repository.TrxRepository) TrxUseCase {
+// NewTrxUseCase returns the usecase for the trx group path, refusing the trx of the buyers whose notelp or email
+// has not been verified when requireVerifiedCheckout is set
+func NewTrxUseCase(trxRepository repository.TrxRepository, paymentProviders *payment.Registry, shippingProvider shipping.ShippingRateProvider, requireVerifiedCheckout bool) TrxUseCase {
 	return &TrxUseCaseImpl{
-		trxRepository: trxRepository,
+		trxRepository:           trxRepository,
+		paymentProviders:        paymentProviders,
+		shippingProvider:        shippingProvider,
+		requireVerifiedCheckout: requireVerifiedCheckout,
 	}
 }
 
-// GetAllTrxs handles the business logic to retrieve all trx data
-func (alc *TrxUseCaseImpl) GetAllTrxs(ctx context.Context, filter *dto.TrxFilter) (res *dto.AllTrxResp, customErr *helper.ErrorStruct) {
-	if filter.Limit < 1 {
-		filter.Limit = 10
-	}
+// GetAllTrxs handles the business logic to retrieve all trx data of the current user
+func (alc *TrxUseCaseImpl) GetAllTrxs(ctx context.Context, principal *utils.Principal, filter *dto.TrxFilter) (res *dto.AllTrxResp, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
 
-	if filter.Page < 1 {
-		filter.Page = 1
+	page := pagination.New(filter.Page, filter.Limit)
+	cursor, order, err := trxSortFields.CursorClause(filter.Cursor, filter.Sort, filter.Order, pagination.KeysetClause)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
 	}
 
-	resRepo, err := alc.trxRepository.GetAllTrxs(ctx, &daos.FilterTrx{
-		Limit:       filter.Limit,
-		Offset:      (filter.Page - 1) * filter.Limit,
+	offset, limit := page.Window(cursor, order)
+	filterRepo := &daos.FilterTrx{
+		Limit:       limit,
+		Offset:      offset,
+		IdUser:      userId,
 		KodeInvoice: filter.Search,
-	})
+		Status:      filter.Status,
+		Order:       order,
+	}
+	if cursor != nil {
+		filterRepo.CursorCreatedAt, filterRepo.CursorId = &cursor.CreatedAt, cursor.Id
+	}
+
+	resRepo, total, err := alc.trxRepository.GetAllTrxs(ctx, filterRepo)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -55,6 +178,13 @@ func (alc *TrxUseCaseImpl) GetAllTrxs(ctx context.Context, filter *dto.TrxFilter
 		}
 	}
 
+	nextCursor := ""
+	if len(resRepo) > page.Limit {
+		resRepo = resRepo[:page.Limit]
+		last := resRepo[page.Limit-1]
+		nextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.ID}.Encode()
+	}
+
 	res, err = utils.TrxArrayToAllTrxResp(resRepo)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
@@ -63,15 +193,36 @@ func (alc *TrxUseCaseImpl) GetAllTrxs(ctx context.Context, filter *dto.TrxFilter
 			Err:  err,
 		}
 	}
-	res.Page = filter.Page
-	res.Limit = filter.Limit
+	res.Meta = page.Meta(total)
+	res.NextCursor = nextCursor
 
 	return res, nil
 }
 
-// GetTrxById handles the business logic to retrieve trx data having the id
-func (alc *TrxUseCaseImpl) GetTrxById(ctx context.Context, id string) (res *dto.TrxResp, customErr *helper.ErrorStruct) {
+// GetTrxById handles the business logic to retrieve trx data of the current user having the id
+func (alc *TrxUseCaseImpl) GetTrxById(ctx context.Context, principal *utils.Principal, id string) (res *dto.TrxResp, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
 	resRepo, err := alc.trxRepository.GetTrxById(ctx, id)
+	if err == nil && resRepo.IdUser != userId {
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	res, err = utils.TrxToTrxResp(resRepo)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -80,6 +231,30 @@ func (alc *TrxUseCaseImpl) GetTrxById(ctx context.Context, id string) (res *dto.
 		}
 	}
 
+	return res, nil
+}
+
+// GetTrxByKodeInvoice handles the business logic to retrieve trx data having the kodeinvoice for its buyer or an admin
+func (alc *TrxUseCaseImpl) GetTrxByKodeInvoice(ctx context.Context, principal *utils.Principal, kodeInvoice string) (res *dto.TrxResp, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.trxRepository.GetTrxByKodeInvoice(ctx, kodeInvoice)
+	if err == nil && alc.authorizeTrxActor(ctx, principal, daos.TrxActorBuyer, resRepo) != nil && alc.authorizeTrxActor(ctx, principal, daos.TrxActorAdmin, resRepo) != nil {
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
 	res, err = utils.TrxToTrxResp(resRepo)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
@@ -93,7 +268,18 @@ func (alc *TrxUseCaseImpl) GetTrxById(ctx context.Context, id string) (res *dto.
 }
 
 // CreateTrx handles the business logic to insert the trx data
-func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dto.TrxCreateReq) (res uint, customErr *helper.ErrorStruct) {
+func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, principal *utils.Principal, data *dto.TrxCreateReq) (res uint, customErr *helper.ErrorStruct) {
+	return alc.createTrx(ctx, principal, data, 0)
+}
+
+// CreateTrxFromCart handles the business logic to insert the trx data ordering the items of the cart having the idcart,
+// emptying the cart along with the trx insertion
+func (alc *TrxUseCaseImpl) CreateTrxFromCart(ctx context.Context, principal *utils.Principal, data *dto.TrxCreateReq, idCart uint) (res uint, customErr *helper.ErrorStruct) {
+	return alc.createTrx(ctx, principal, data, idCart)
+}
+
+// createTrx inserts the trx data of CreateTrx, emptying the cart having the idcart in the same transaction unless it is 0
+func (alc *TrxUseCaseImpl) createTrx(ctx context.Context, principal *utils.Principal, data *dto.TrxCreateReq, idCart uint) (res uint, customErr *helper.ErrorStruct) {
 	if errValidate := helper.Validate.Struct(data); errValidate != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
 		return res, &helper.ErrorStruct{
@@ -102,7 +288,13 @@ func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dt
 		}
 	}
 
-	userId, err := utils.GetJWTUserId(token)
+	userId := principal.UserId
+
+	if customErr := alc.checkVerifiedBuyer(ctx, principal); customErr != nil {
+		return 0, customErr
+	}
+
+	paymentProvider, err := alc.paymentProviders.Get(data.MethodBayar)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return 0, &helper.ErrorStruct{
@@ -113,6 +305,9 @@ func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dt
 
 	detailTrxes := []*daos.DetailTrx{}
 	trxHargaTotal := 0
+	stokErrs := []string{}
+	produks := []*daos.Produk{}
+	kuantitas := []int{}
 	for _, v := range data.DetailTrxes {
 		resRepoProduk, err := alc.trxRepository.GetProdukById(ctx, strconv.Itoa(int(v.ProductId)))
 		if err != nil {
@@ -123,28 +318,49 @@ func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dt
 			}
 		}
 
+		variant, err := findProdukVariant(resRepoProduk, v.VariantId)
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		stok := resRepoProduk.Stok
+		if variant != nil {
+			stok = variant.Stok
+		}
+
+		if v.Kuantitas > stok {
+			stokErrs = append(stokErrs, fmt.Sprintf("stok produk %s tidak mencukupi, tersisa %d", resRepoProduk.NamaProduk, stok))
+			continue
+		}
+
 		logProduk := &daos.LogProduk{
 			IdProduk:      resRepoProduk.ID,
 			NamaProduk:    resRepoProduk.NamaProduk,
 			Slug:          resRepoProduk.Slug,
 			HargaReseller: resRepoProduk.HargaReseller,
 			HargaKonsumen: resRepoProduk.HargaKonsumen,
+			Berat:         resRepoProduk.Berat,
 			Deskripsi:     resRepoProduk.Deskripsi,
 			IdToko:        resRepoProduk.IdToko,
 			IdCategory:    resRepoProduk.IdCategory,
 		}
 
-		hargaKonsumen, err := strconv.Atoi(logProduk.HargaKonsumen)
-		if err != nil {
-			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return 0, &helper.ErrorStruct{
-				Code: fiber.StatusBadRequest,
-				Err:  err,
-			}
+		if variant != nil {
+			logProduk.IdVariant = &variant.ID
+			logProduk.Sku = variant.Sku
+			logProduk.Opsi = variant.Opsi
+			logProduk.HargaReseller = variant.HargaResellerOf(resRepoProduk)
+			logProduk.HargaKonsumen = variant.HargaKonsumenOf(resRepoProduk)
 		}
 
-		detailHargaTotal := v.Kuantitas * hargaKonsumen
+		detailHargaTotal := logProduk.HargaKonsumen.Mul(v.Kuantitas).Int()
 		trxHargaTotal += detailHargaTotal
+		produks = append(produks, resRepoProduk)
+		kuantitas = append(kuantitas, v.Kuantitas)
 
 		detailTrxes = append(detailTrxes, &daos.DetailTrx{
 			LogProduk:  logProduk,
@@ -154,6 +370,15 @@ func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dt
 		})
 	}
 
+	if len(stokErrs) > 0 {
+		err = errors.New(strings.Join(stokErrs, "; "))
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
 	resRepoAlamat, err := alc.trxRepository.GetAlamatById(ctx, strconv.Itoa(int(data.AlamatKirim)))
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
@@ -164,30 +389,646 @@ func (alc *TrxUseCaseImpl) CreateTrx(ctx context.Context, token string, data *dt
 	}
 
 	if resRepoAlamat.IdUser != userId {
+		err = errors.New("unauthorized alamat kirim")
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return 0, &helper.ErrorStruct{
 			Code: fiber.StatusBadRequest,
-			Err:  errors.New("unauthorized alamat kirim"),
+			Err:  err,
 		}
 	}
 
+	var voucher *daos.Voucher
+	diskon := 0
+	if data.KodeVoucher != "" {
+		voucher, err = alc.trxRepository.GetVoucherByKode(ctx, strings.ToUpper(data.KodeVoucher))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = fmt.Errorf("voucher %s tidak ditemukan", data.KodeVoucher)
+		}
+
+		if err == nil {
+			diskon, err = applyVoucher(voucher, detailTrxes, time.Now())
+		}
+
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		trxHargaTotal -= diskon
+	}
+
+	shipments, err := alc.quoteTokoShipments(ctx, userId, produks, kuantitas)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	layanan := data.Layanan
+	if layanan == "" {
+		layanan = shipping.ServiceREG
+	}
+
+	pengirimans := []*daos.TrxPengiriman{}
+	ongkirTotal := 0
+	for _, v := range shipments {
+		rate, err := shipping.FindRate(v.rates, layanan)
+		if err != nil {
+			err = fmt.Errorf("%s untuk toko %s", err.Error(), v.toko.NamaToko)
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return 0, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		ongkirTotal += rate.Ongkir
+		pengirimans = append(pengirimans, &daos.TrxPengiriman{
+			IdToko:  v.toko.ID,
+			Kurir:   rate.Kurir,
+			Layanan: rate.Layanan,
+			Asal:    v.asal,
+			Tujuan:  v.tujuan,
+			Berat:   v.berat,
+			Ongkir:  rate.Ongkir,
+			Etd:     rate.Etd,
+		})
+	}
+	trxHargaTotal += ongkirTotal
+
 	trx := &daos.Trx{
 		IdUser:           userId,
 		AlamatPengiriman: resRepoAlamat.ID,
 		HargaTotal:       trxHargaTotal,
-		KodeInvoice:      fmt.Sprintf("INV-%d", time.Now().UnixNano()),
-		MethodBayar:      data.MethodBayar,
+		OngkirTotal:      ongkirTotal,
+		Diskon:           diskon,
+		KodeInvoice:      kodeInvoicePrefix(time.Now(), detailTrxes),
+		MethodBayar:      paymentProvider.Name(),
+		Status:           daos.TrxStatusPending,
 		DetailTrxs:       detailTrxes,
+		Pengirimans:      pengirimans,
+		StatusHistories: []*daos.TrxStatusHistory{
+			{
+				IdUser:     userId,
+				Peran:      daos.TrxActorBuyer,
+				StatusBaru: daos.TrxStatusPending,
+			},
+		},
+	}
+
+	if voucher != nil {
+		trx.IdVoucher = &voucher.ID
+		trx.VoucherUsage = &daos.VoucherUsage{
+			IdVoucher: voucher.ID,
+			IdUser:    userId,
+			Diskon:    diskon,
+		}
 	}
 
-	resRepo, err := alc.trxRepository.CreateTrx(ctx, trx)
+	var resRepo uint
+	if idCart != 0 {
+		resRepo, err = alc.trxRepository.CreateTrxFromCart(ctx, trx, idCart)
+	} else {
+		resRepo, err = alc.trxRepository.CreateTrx(ctx, trx)
+	}
 	if err != nil {
+		code := fiber.StatusBadRequest
+		var stokErr *repository.InsufficientStokError
+		if errors.As(err, &stokErr) || errors.Is(err, repository.ErrVoucherQuotaExceeded) {
+			code = fiber.StatusConflict
+		}
+
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return res, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
+			Code: code,
 			Err:  err,
 		}
 	}
 
+	// the payment intent is only issued once the trx is committed, so a failed checkout never leaves a payable intent behind
+	if customErr := alc.createTrxPayment(ctx, paymentProvider, trx, resRepoAlamat); customErr != nil {
+		return res, customErr
+	}
+
 	return resRepo, nil
 }
+
+// createTrxPayment issues the payment intent of the created trx and attaches it to the trx. When the intent cannot be issued
+// or attached, the trx is cancelled by the system, giving back its stok and voucher
+func (alc *TrxUseCaseImpl) createTrxPayment(ctx context.Context, paymentProvider payment.PaymentProvider, trx *daos.Trx, alamat *daos.Alamat) (customErr *helper.ErrorStruct) {
+	code := fiber.StatusBadGateway
+	intent, err := paymentProvider.CreateIntent(ctx, &payment.IntentReq{
+		Amount:       trx.HargaTotal,
+		NamaPenerima: alamat.NamaPenerima,
+		Notelp:       alamat.Notelp,
+	})
+	if err == nil {
+		trxPayment := &daos.Payment{
+			IdTrx:     trx.ID,
+			Provider:  intent.Provider,
+			Reference: intent.Reference,
+			Amount:    intent.Amount,
+			Status:    daos.PaymentStatusPending,
+			Instruksi: intent.Instruksi,
+		}
+		if !intent.ExpiredAt.IsZero() {
+			trxPayment.ExpiredAt = &intent.ExpiredAt
+		}
+
+		if _, err = alc.trxRepository.CreateTrxPayment(ctx, trxPayment); err == nil {
+			trx.Payment = trxPayment
+			return nil
+		}
+		code = fiber.StatusInternalServerError
+	}
+
+	helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+
+	errCancel := alc.trxRepository.UpdateTrxStatus(ctx, trx, &daos.TrxStatusHistory{
+		Peran:      daos.TrxActorSystem,
+		StatusLama: daos.TrxStatusPending,
+		StatusBaru: daos.TrxStatusCancelled,
+		Alasan:     "pembayaran gagal dibuat",
+	}, true)
+	if errCancel != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errCancel.Error()))
+	}
+
+	return &helper.ErrorStruct{
+		Code: code,
+		Err:  err,
+	}
+}
+
+// QuoteShipping handles the business logic to retrieve the shipping rates of every toko sending the produks to the current user
+func (alc *TrxUseCaseImpl) QuoteShipping(ctx context.Context, principal *utils.Principal, data *dto.ShippingQuoteReq) (res *dto.ShippingQuoteResp, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return nil, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	userId := principal.UserId
+
+	produks := []*daos.Produk{}
+	kuantitas := []int{}
+	for _, v := range data.DetailTrxes {
+		resRepoProduk, err := alc.trxRepository.GetProdukById(ctx, strconv.Itoa(int(v.ProductId)))
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return nil, &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		produks = append(produks, resRepoProduk)
+		kuantitas = append(kuantitas, v.Kuantitas)
+	}
+
+	shipments, err := alc.quoteTokoShipments(ctx, userId, produks, kuantitas)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	res = &dto.ShippingQuoteResp{
+		Tokos: []*dto.ShippingQuoteTokoResp{},
+	}
+	for _, v := range shipments {
+		tokoResp := &dto.ShippingQuoteTokoResp{
+			Toko: &dto.TokoResp{
+				ID:       v.toko.ID,
+				NamaToko: v.toko.NamaToko,
+				UrlFoto:  v.toko.UrlFoto,
+			},
+			Asal:   v.asal,
+			Tujuan: v.tujuan,
+			Berat:  v.berat,
+			Rates:  []*dto.ShippingRateResp{},
+		}
+		for _, rate := range v.rates {
+			tokoResp.Rates = append(tokoResp.Rates, &dto.ShippingRateResp{
+				Kurir:     rate.Kurir,
+				Layanan:   rate.Layanan,
+				Deskripsi: rate.Deskripsi,
+				Ongkir:    rate.Ongkir,
+				Etd:       rate.Etd,
+			})
+		}
+
+		res.Tokos = append(res.Tokos, tokoResp)
+	}
+
+	return res, nil
+}
+
+// checkVerifiedBuyer refuses the trx of the principal while its notelp or email is not verified, when requireVerifiedCheckout is set
+func (alc *TrxUseCaseImpl) checkVerifiedBuyer(ctx context.Context, principal *utils.Principal) (customErr *helper.ErrorStruct) {
+	if !alc.requireVerifiedCheckout {
+		return nil
+	}
+
+	resRepo, err := alc.trxRepository.GetUserById(ctx, principal.UserIdString())
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if resRepo.NotelpVerifiedAt == nil || resRepo.EmailVerifiedAt == nil {
+		return &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("verifikasi nomor telepon dan email terlebih dahulu sebelum checkout"),
+		}
+	}
+
+	return nil
+}
+
+// quoteTokoShipments groups the ordered kuantitas of the produks by toko and retrieves the rates of sending each group
+// from the kota of the toko owner to the kota of the buyer
+func (alc *TrxUseCaseImpl) quoteTokoShipments(ctx context.Context, buyerId uint, produks []*daos.Produk, kuantitas []int) (res []*tokoShipment, err error) {
+	resRepoBuyer, err := alc.trxRepository.GetUserById(ctx, strconv.Itoa(int(buyerId)))
+	if err != nil {
+		return nil, err
+	}
+
+	shipments := map[uint]*tokoShipment{}
+	for i, v := range produks {
+		shipment, ok := shipments[v.IdToko]
+		if !ok {
+			if v.Toko == nil {
+				return nil, fmt.Errorf("toko produk %s tidak ditemukan", v.NamaProduk)
+			}
+
+			resRepoSeller, err := alc.trxRepository.GetUserById(ctx, strconv.Itoa(int(v.Toko.IdUser)))
+			if err != nil {
+				return nil, err
+			}
+
+			shipment = &tokoShipment{
+				toko:   v.Toko,
+				asal:   resRepoSeller.IdKota,
+				tujuan: resRepoBuyer.IdKota,
+			}
+			shipments[v.IdToko] = shipment
+			res = append(res, shipment)
+		}
+
+		shipment.berat += v.Berat * kuantitas[i]
+	}
+
+	for _, v := range res {
+		v.rates, err = alc.shippingProvider.GetRates(ctx, &shipping.RateReq{
+			Asal:   v.asal,
+			Tujuan: v.tujuan,
+			Berat:  v.berat,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// GetTrxStatusHistories handles the business logic to retrieve the status history of the trx having the id
+func (alc *TrxUseCaseImpl) GetTrxStatusHistories(ctx context.Context, principal *utils.Principal, id string) (res []*dto.TrxStatusHistoryResp, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.trxRepository.GetTrxById(ctx, id)
+	if err != nil {
+		code := fiber.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	authorized := false
+	for _, peran := range []string{daos.TrxActorBuyer, daos.TrxActorSeller, daos.TrxActorAdmin} {
+		if alc.authorizeTrxActor(ctx, principal, peran, resRepo) == nil {
+			authorized = true
+			break
+		}
+	}
+
+	if !authorized {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, "Error : unauthorized")
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("you are unauthorized"),
+		}
+	}
+
+	resRepoHistories, err := alc.trxRepository.GetTrxStatusHistories(ctx, id)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	res = []*dto.TrxStatusHistoryResp{}
+	for _, v := range resRepoHistories {
+		res = append(res, utils.TrxStatusHistoryToTrxStatusHistoryResp(v))
+	}
+	return res, nil
+}
+
+// UpdateTrxStatus handles the business logic to move the trx having the id to another status on behalf of the peran
+func (alc *TrxUseCaseImpl) UpdateTrxStatus(ctx context.Context, principal *utils.Principal, id, peran string, data *dto.TrxStatusUpdateReq) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	userId := principal.UserId
+
+	resRepo, err := alc.trxRepository.GetTrxById(ctx, id)
+	if err != nil {
+		code := fiber.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	if err := alc.authorizeTrxActor(ctx, principal, peran, resRepo); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  err,
+		}
+	}
+
+	var pengiriman *daos.TrxPengiriman
+	if peran == daos.TrxActorSeller {
+		pengiriman, err = alc.authorizeTrxSeller(ctx, principal, resRepo, data.Status)
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return &helper.ErrorStruct{
+				Code: fiber.StatusForbidden,
+				Err:  err,
+			}
+		}
+	}
+
+	current := resRepo.Status
+	if pengiriman != nil {
+		current = pengiriman.Status
+	}
+
+	if err := validateTrxStatusTransition(current, data.Status, peran, resRepo.MethodBayar); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	history := &daos.TrxStatusHistory{
+		IdUser:     userId,
+		Peran:      peran,
+		StatusLama: current,
+		StatusBaru: data.Status,
+		Alasan:     data.Alasan,
+	}
+	if pengiriman != nil {
+		err = alc.trxRepository.UpdateTrxPengirimanStatus(ctx, resRepo, pengiriman, history)
+	} else {
+		err = alc.trxRepository.UpdateTrxStatus(ctx, resRepo, history, data.Status == daos.TrxStatusCancelled)
+	}
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, repository.ErrTrxStatusChanged) {
+			code = fiber.StatusConflict
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// GetTrxInvoicePDF handles the business logic to render the invoice of the trx having the id, limited to the lines of the caller's toko when requested by a seller
+func (alc *TrxUseCaseImpl) GetTrxInvoicePDF(ctx context.Context, principal *utils.Principal, id string) (res []byte, kodeInvoice string, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	resRepo, err := alc.trxRepository.GetTrxById(ctx, id)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	detailTrxs := resRepo.DetailTrxs
+	pengirimans := resRepo.Pengirimans
+	if alc.authorizeTrxActor(ctx, principal, daos.TrxActorBuyer, resRepo) != nil && alc.authorizeTrxActor(ctx, principal, daos.TrxActorAdmin, resRepo) != nil {
+		if err := alc.authorizeTrxActor(ctx, principal, daos.TrxActorSeller, resRepo); err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return nil, "", &helper.ErrorStruct{
+				Code: fiber.StatusForbidden,
+				Err:  errors.New("you are unauthorized"),
+			}
+		}
+
+		resRepoToko, err := alc.trxRepository.GetTokoByUserId(ctx, strconv.Itoa(int(userId)))
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return nil, "", &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+
+		detailTrxs = filterDetailTrxsByToko(resRepo.DetailTrxs, resRepoToko.ID)
+		pengirimans = []*daos.TrxPengiriman{}
+		for _, v := range resRepo.Pengirimans {
+			if v.IdToko == resRepoToko.ID {
+				pengirimans = append(pengirimans, v)
+			}
+		}
+	}
+
+	res, err = utils.TrxToInvoicePDF(resRepo, detailTrxs, pengirimans)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	return res, resRepo.KodeInvoice, nil
+}
+
+// GetTrxPackingSlipPDF handles the business logic to render the packing slip of the toko lines of the trx having the id.
+// Sellers get the slip of their own toko while admins pick the toko with the tokoid
+func (alc *TrxUseCaseImpl) GetTrxPackingSlipPDF(ctx context.Context, principal *utils.Principal, id, tokoId string) (res []byte, kodeInvoice string, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	resRepo, err := alc.trxRepository.GetTrxById(ctx, id)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data trx")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	var toko *daos.Toko
+	if tokoId != "" && alc.authorizeTrxActor(ctx, principal, daos.TrxActorAdmin, resRepo) == nil {
+		for _, v := range resRepo.DetailTrxs {
+			if strconv.Itoa(int(v.IdToko)) == tokoId && v.LogProduk.Toko != nil {
+				toko = v.LogProduk.Toko
+				break
+			}
+		}
+	} else if alc.authorizeTrxActor(ctx, principal, daos.TrxActorSeller, resRepo) == nil {
+		toko, err = alc.trxRepository.GetTokoByUserId(ctx, strconv.Itoa(int(userId)))
+		if err != nil {
+			helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return nil, "", &helper.ErrorStruct{
+				Code: fiber.StatusBadRequest,
+				Err:  err,
+			}
+		}
+	} else {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, "Error : unauthorized")
+		return nil, "", &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("you are unauthorized"),
+		}
+	}
+
+	if toko == nil {
+		err = errors.New("no data toko on this trx")
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: fiber.StatusNotFound,
+			Err:  err,
+		}
+	}
+
+	res, err = utils.TrxToPackingSlipPDF(resRepo, toko, filterDetailTrxsByToko(resRepo.DetailTrxs, toko.ID))
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, "", &helper.ErrorStruct{
+			Code: fiber.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	return res, resRepo.KodeInvoice, nil
+}
+
+// filterDetailTrxsByToko returns the detailtrx data sold by the toko
+func filterDetailTrxsByToko(detailTrxs []*daos.DetailTrx, tokoId uint) (res []*daos.DetailTrx) {
+	for _, v := range detailTrxs {
+		if v.IdToko == tokoId {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// authorizeTrxActor checks whether the principal is allowed to act as the peran on the trx
+func (alc *TrxUseCaseImpl) authorizeTrxActor(ctx context.Context, principal *utils.Principal, peran string, trx *daos.Trx) error {
+	userId := principal.UserId
+	switch peran {
+	case daos.TrxActorBuyer:
+		if trx.IdUser == userId {
+			return nil
+		}
+	case daos.TrxActorSeller:
+		resRepoToko, err := alc.trxRepository.GetTokoByUserId(ctx, strconv.Itoa(int(userId)))
+		if err != nil {
+			return err
+		}
+
+		for _, v := range trx.DetailTrxs {
+			if v.IdToko == resRepoToko.ID {
+				return nil
+			}
+		}
+	case daos.TrxActorAdmin:
+		if principal.Can(rbac.PermissionTrxModerate) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("you are unauthorized to act as %s on this trx", peran)
+}
+
+// authorizeTrxSeller returns the pengiriman of the toko of the principal when the seller packs or ships it. The pengirimans of a trx
+// holding the lines of several tokos are packed and shipped apart, while the other moves of the trx as a whole, like calling it off,
+// are left to the seller of every line of it
+func (alc *TrxUseCaseImpl) authorizeTrxSeller(ctx context.Context, principal *utils.Principal, trx *daos.Trx, next string) (res *daos.TrxPengiriman, err error) {
+	resRepoToko, err := alc.trxRepository.GetTokoByUserId(ctx, strconv.Itoa(int(principal.UserId)))
+	if err != nil {
+		return nil, err
+	}
+
+	if next == daos.TrxStatusPacked || next == daos.TrxStatusShipped {
+		for _, v := range trx.Pengirimans {
+			if v.IdToko == resRepoToko.ID {
+				return v, nil
+			}
+		}
+	}
+
+	for _, v := range trx.DetailTrxs {
+		if v.IdToko != resRepoToko.ID {
+			return nil, errors.New("this trx holds produk of other tokos, only its pengiriman can be packed and shipped by the seller")
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,470 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/payment"
+	"tugas_akhir_example/internal/infrastructure/shipping"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeTrxRepository holds the trxs by id and the tokos by the userid of their owner, failing the trx lookups with err when set.
+// The trx created is held as the trx 1, unless createErr or paymentErr fail its creation or the creation of its payment
+type fakeTrxRepository struct {
+	repository.TrxRepository
+	trxs    map[string]*daos.Trx
+	tokos   map[string]*daos.Toko
+	produks map[string]*daos.Produk
+	alamats map[string]*daos.Alamat
+	users   map[string]*daos.User
+	err     error
+
+	createErr, paymentErr error
+	payments              []*daos.Payment
+	histories             []*daos.TrxStatusHistory
+}
+
+func (f *fakeTrxRepository) GetProdukById(ctx context.Context, id string) (res *daos.Produk, err error) {
+	if res, ok := f.produks[id]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTrxRepository) GetAlamatById(ctx context.Context, id string) (res *daos.Alamat, err error) {
+	if res, ok := f.alamats[id]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTrxRepository) GetUserById(ctx context.Context, id string) (res *daos.User, err error) {
+	if res, ok := f.users[id]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTrxRepository) CreateTrx(ctx context.Context, data *daos.Trx) (res uint, err error) {
+	if f.createErr != nil {
+		return 0, f.createErr
+	}
+
+	data.ID = 1
+	f.trxs = map[string]*daos.Trx{"1": data}
+	return data.ID, nil
+}
+
+func (f *fakeTrxRepository) CreateTrxPayment(ctx context.Context, data *daos.Payment) (res uint, err error) {
+	if f.paymentErr != nil {
+		return 0, f.paymentErr
+	}
+
+	f.payments = append(f.payments, data)
+	return uint(len(f.payments)), nil
+}
+
+func (f *fakeTrxRepository) GetTrxById(ctx context.Context, id string) (res *daos.Trx, err error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if res, ok := f.trxs[id]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTrxRepository) GetTokoByUserId(ctx context.Context, userId string) (res *daos.Toko, err error) {
+	if res, ok := f.tokos[userId]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTrxRepository) GetTrxStatusHistories(ctx context.Context, trxId string) (res []*daos.TrxStatusHistory, err error) {
+	return f.histories, nil
+}
+
+// UpdateTrxStatus moves the trx along with its pengirimans left at the former status
+func (f *fakeTrxRepository) UpdateTrxStatus(ctx context.Context, data *daos.Trx, history *daos.TrxStatusHistory, release bool) (err error) {
+	if data.Status != history.StatusLama {
+		return repository.ErrTrxStatusChanged
+	}
+
+	for _, v := range data.Pengirimans {
+		if v.Status == history.StatusLama {
+			v.Status = history.StatusBaru
+		}
+	}
+	data.Status = history.StatusBaru
+	f.histories = append(f.histories, history)
+	return nil
+}
+
+// UpdateTrxPengirimanStatus moves the pengiriman, then the trx once none of its pengirimans is left at the status of the trx
+func (f *fakeTrxRepository) UpdateTrxPengirimanStatus(ctx context.Context, data *daos.Trx, pengiriman *daos.TrxPengiriman, history *daos.TrxStatusHistory) (err error) {
+	if pengiriman.Status != history.StatusLama {
+		return repository.ErrTrxStatusChanged
+	}
+
+	pengiriman.Status = history.StatusBaru
+	history.IdToko = &pengiriman.IdToko
+	f.histories = append(f.histories, history)
+
+	for _, v := range data.Pengirimans {
+		if v.Status == data.Status {
+			return nil
+		}
+	}
+	return f.UpdateTrxStatus(ctx, data, &daos.TrxStatusHistory{Peran: daos.TrxActorSystem, StatusLama: data.Status, StatusBaru: history.StatusBaru}, false)
+}
+
+func TestKodeInvoicePrefix(t *testing.T) {
+	date := time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		idTokos []uint
+		want    string
+	}{
+		{"single line", []uint{12}, "INV/20261018/TOKO12"},
+		{"lines of one toko", []uint{12, 12, 12}, "INV/20261018/TOKO12"},
+		{"lines of two tokos", []uint{12, 7}, "INV/20261018/MULTI"},
+		{"other toko after the first lines", []uint{12, 12, 7}, "INV/20261018/MULTI"},
+		{"no line", nil, "INV/20261018/MULTI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detailTrxs := []*daos.DetailTrx{}
+			for _, v := range tt.idTokos {
+				detailTrxs = append(detailTrxs, &daos.DetailTrx{IdToko: v})
+			}
+
+			if got := kodeInvoicePrefix(date, detailTrxs); got != tt.want {
+				t.Errorf("kodeInvoicePrefix = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTrxByIdOfItsBuyerOnly(t *testing.T) {
+	toko := &daos.Toko{Model: gorm.Model{ID: 1}}
+	usecase := NewTrxUseCase(&fakeTrxRepository{
+		trxs: map[string]*daos.Trx{"1": {
+			IdUser: 9,
+			Status: daos.TrxStatusPaid,
+			Alamat: &daos.Alamat{},
+			DetailTrxs: []*daos.DetailTrx{{
+				IdToko:    toko.ID,
+				LogProduk: &daos.LogProduk{Produk: &daos.Produk{}, Toko: toko, Category: &daos.Category{}},
+			}},
+		}},
+		tokos: map[string]*daos.Toko{"11": toko},
+	}, nil, nil, false)
+
+	tests := []struct {
+		name   string
+		userId uint
+		want   int
+	}{
+		{"buyer", 9, 0},
+		{"other buyer", 8, fiber.StatusNotFound},
+		{"seller of its line", 11, fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, customErr := usecase.GetTrxById(context.Background(), &utils.Principal{UserId: tt.userId}, "1")
+			code := 0
+			if customErr != nil {
+				code = customErr.Code
+			}
+			if code != tt.want {
+				t.Errorf("GetTrxById error = %v, want code %d", customErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrxDocumentsOfTheSellerOfItsToko(t *testing.T) {
+	tokos := map[string]*daos.Toko{
+		"11": {Model: gorm.Model{ID: 1}, NamaToko: "toko 1"},
+		"12": {Model: gorm.Model{ID: 2}, NamaToko: "toko 2"},
+		"13": {Model: gorm.Model{ID: 3}, NamaToko: "toko 3"},
+	}
+	detailTrxs := []*daos.DetailTrx{}
+	for _, v := range []string{"11", "12"} {
+		detailTrxs = append(detailTrxs, &daos.DetailTrx{
+			IdToko:    tokos[v].ID,
+			Kuantitas: 1,
+			LogProduk: &daos.LogProduk{NamaProduk: "produk", Produk: &daos.Produk{}, Toko: tokos[v], Category: &daos.Category{}},
+		})
+	}
+	usecase := NewTrxUseCase(&fakeTrxRepository{
+		trxs: map[string]*daos.Trx{"1": {
+			IdUser:      9,
+			KodeInvoice: "INV/20261018/MULTI/000001",
+			Status:      daos.TrxStatusPaid,
+			Alamat:      &daos.Alamat{},
+			DetailTrxs:  detailTrxs,
+		}},
+		tokos: tokos,
+	}, nil, nil, false)
+
+	tests := []struct {
+		name            string
+		userId          uint
+		wantInvoice     int
+		wantPackingSlip int
+	}{
+		{"buyer", 9, 0, fiber.StatusForbidden},
+		{"seller of a toko of the trx", 11, 0, 0},
+		{"seller of a toko outside the trx", 13, fiber.StatusForbidden, fiber.StatusForbidden},
+		{"other buyer", 8, fiber.StatusForbidden, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &utils.Principal{UserId: tt.userId}
+
+			_, _, customErr := usecase.GetTrxInvoicePDF(context.Background(), principal, "1")
+			if code := errorCode(customErr); code != tt.wantInvoice {
+				t.Errorf("GetTrxInvoicePDF error = %v, want code %d", customErr, tt.wantInvoice)
+			}
+
+			// the seller picking another toko gets the slip of its own toko still
+			_, _, customErr = usecase.GetTrxPackingSlipPDF(context.Background(), principal, "1", "2")
+			if code := errorCode(customErr); code != tt.wantPackingSlip {
+				t.Errorf("GetTrxPackingSlipPDF error = %v, want code %d", customErr, tt.wantPackingSlip)
+			}
+		})
+	}
+}
+
+// errorCode returns the code of the error, 0 when there is none
+func errorCode(customErr *helper.ErrorStruct) int {
+	if customErr == nil {
+		return 0
+	}
+	return customErr.Code
+}
+
+func TestTrxLookupErrors(t *testing.T) {
+	principal := &utils.Principal{UserId: 1}
+	tests := []struct {
+		name string
+		id   string
+		err  error
+		want int
+	}{
+		{"unknown trx", "404", nil, fiber.StatusNotFound},
+		{"repository error", "1", errors.New("connection refused"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := NewTrxUseCase(&fakeTrxRepository{
+				trxs: map[string]*daos.Trx{"1": {IdUser: 1, Status: daos.TrxStatusPending}},
+				err:  tt.err,
+			}, nil, nil, false)
+
+			if _, customErr := usecase.GetTrxStatusHistories(context.Background(), principal, tt.id); customErr == nil || customErr.Code != tt.want {
+				t.Errorf("GetTrxStatusHistories error = %v, want code %d", customErr, tt.want)
+			}
+
+			customErr := usecase.UpdateTrxStatus(context.Background(), principal, tt.id, daos.TrxActorBuyer, &dto.TrxStatusUpdateReq{Status: daos.TrxStatusCancelled})
+			if customErr == nil || customErr.Code != tt.want {
+				t.Errorf("UpdateTrxStatus error = %v, want code %d", customErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTrxStatusOfTrxOfTwoTokos(t *testing.T) {
+	trx := &daos.Trx{
+		IdUser:      9,
+		Status:      daos.TrxStatusPaid,
+		MethodBayar: "bank_transfer",
+		DetailTrxs:  []*daos.DetailTrx{{IdToko: 1}, {IdToko: 2}},
+		Pengirimans: []*daos.TrxPengiriman{
+			{Model: gorm.Model{ID: 1}, IdToko: 1, Status: daos.TrxStatusPaid},
+			{Model: gorm.Model{ID: 2}, IdToko: 2, Status: daos.TrxStatusPaid},
+		},
+	}
+	repo := &fakeTrxRepository{
+		trxs: map[string]*daos.Trx{"1": trx},
+		tokos: map[string]*daos.Toko{
+			"11": {Model: gorm.Model{ID: 1}},
+			"12": {Model: gorm.Model{ID: 2}},
+			"13": {Model: gorm.Model{ID: 3}},
+		},
+	}
+	usecase := NewTrxUseCase(repo, nil, nil, false)
+
+	// each seller packs and ships the pengiriman of its toko only, the trx following the one left behind
+	steps := []struct {
+		name            string
+		userId          uint
+		peran, status   string
+		wantCode        int
+		wantTrx         string
+		wantPengirimans [2]string
+	}{
+		{"first toko packs", 11, daos.TrxActorSeller, daos.TrxStatusPacked, 0, daos.TrxStatusPaid, [2]string{daos.TrxStatusPacked, daos.TrxStatusPaid}},
+		{"second toko ships before packing", 12, daos.TrxActorSeller, daos.TrxStatusShipped, fiber.StatusBadRequest, daos.TrxStatusPaid, [2]string{daos.TrxStatusPacked, daos.TrxStatusPaid}},
+		{"first toko ships", 11, daos.TrxActorSeller, daos.TrxStatusShipped, 0, daos.TrxStatusPaid, [2]string{daos.TrxStatusShipped, daos.TrxStatusPaid}},
+		{"first toko packs again", 11, daos.TrxActorSeller, daos.TrxStatusPacked, fiber.StatusBadRequest, daos.TrxStatusPaid, [2]string{daos.TrxStatusShipped, daos.TrxStatusPaid}},
+		{"second toko cancels the whole trx", 12, daos.TrxActorSeller, daos.TrxStatusCancelled, fiber.StatusForbidden, daos.TrxStatusPaid, [2]string{daos.TrxStatusShipped, daos.TrxStatusPaid}},
+		{"toko outside the trx packs", 13, daos.TrxActorSeller, daos.TrxStatusPacked, fiber.StatusForbidden, daos.TrxStatusPaid, [2]string{daos.TrxStatusShipped, daos.TrxStatusPaid}},
+		{"buyer before the trx is shipped", 9, daos.TrxActorBuyer, daos.TrxStatusDelivered, fiber.StatusBadRequest, daos.TrxStatusPaid, [2]string{daos.TrxStatusShipped, daos.TrxStatusPaid}},
+		{"second toko packs", 12, daos.TrxActorSeller, daos.TrxStatusPacked, 0, daos.TrxStatusPacked, [2]string{daos.TrxStatusShipped, daos.TrxStatusPacked}},
+		{"second toko ships", 12, daos.TrxActorSeller, daos.TrxStatusShipped, 0, daos.TrxStatusShipped, [2]string{daos.TrxStatusShipped, daos.TrxStatusShipped}},
+		{"buyer receives", 9, daos.TrxActorBuyer, daos.TrxStatusDelivered, 0, daos.TrxStatusDelivered, [2]string{daos.TrxStatusDelivered, daos.TrxStatusDelivered}},
+	}
+
+	for _, tt := range steps {
+		customErr := usecase.UpdateTrxStatus(context.Background(), &utils.Principal{UserId: tt.userId}, "1", tt.peran, &dto.TrxStatusUpdateReq{Status: tt.status})
+		code := 0
+		if customErr != nil {
+			code = customErr.Code
+		}
+		if code != tt.wantCode {
+			t.Errorf("%s: UpdateTrxStatus error = %v, want code %d", tt.name, customErr, tt.wantCode)
+		}
+
+		if trx.Status != tt.wantTrx || trx.Pengirimans[0].Status != tt.wantPengirimans[0] || trx.Pengirimans[1].Status != tt.wantPengirimans[1] {
+			t.Errorf("%s: trx %s, pengirimans %s and %s, want %s, %v", tt.name, trx.Status, trx.Pengirimans[0].Status, trx.Pengirimans[1].Status, tt.wantTrx, tt.wantPengirimans)
+		}
+	}
+
+	// the moves of the pengirimans are recorded with their toko
+	tokoIds := []uint{}
+	for _, v := range repo.histories {
+		if v.Peran == daos.TrxActorSeller {
+			if v.IdToko == nil {
+				t.Fatalf("seller history %s to %s without its toko", v.StatusLama, v.StatusBaru)
+			}
+			tokoIds = append(tokoIds, *v.IdToko)
+		}
+	}
+	if fmt.Sprint(tokoIds) != "[1 1 2 2]" {
+		t.Errorf("seller histories of tokos %v, want [1 1 2 2]", tokoIds)
+	}
+}
+
+// fakePaymentProvider issues the intents of the mock method bayar, failing with err when set.
+// It records whether the trx had been created by the repository when each intent was issued
+type fakePaymentProvider struct {
+	repo    *fakeTrxRepository
+	err     error
+	intents []bool
+}
+
+func (p *fakePaymentProvider) Name() string {
+	return payment.MethodMock
+}
+
+func (p *fakePaymentProvider) CreateIntent(ctx context.Context, req *payment.IntentReq) (res *payment.Intent, err error) {
+	p.intents = append(p.intents, p.repo.trxs["1"] != nil)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &payment.Intent{Provider: p.Name(), Reference: "mock_1", Amount: req.Amount, Status: payment.StatusPending}, nil
+}
+
+func (p *fakePaymentProvider) ParseWebhook(signature string, body []byte) (res *payment.WebhookEvent, err error) {
+	return nil, payment.ErrInvalidSignature
+}
+
+func TestCreateTrxIssuesThePaymentOnceTheTrxIsCreated(t *testing.T) {
+	tests := []struct {
+		name                             string
+		createErr, intentErr, paymentErr error
+		wantCode                         int
+		wantIntents                      int
+		wantStatus                       string
+	}{
+		{"trx created", nil, nil, nil, 0, 1, daos.TrxStatusPending},
+		{"trx refused", &repository.InsufficientStokError{NamaProduks: []string{"kaos"}}, nil, nil, fiber.StatusConflict, 0, ""},
+		{"intent refused", nil, errors.New("provider unavailable"), nil, fiber.StatusBadGateway, 1, daos.TrxStatusCancelled},
+		{"payment not inserted", nil, nil, errors.New("connection refused"), fiber.StatusInternalServerError, 1, daos.TrxStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTrxRepository{
+				produks: map[string]*daos.Produk{
+					"1": {Model: gorm.Model{ID: 1}, NamaProduk: "kaos", IdToko: 1, Stok: 5, HargaKonsumen: 50000, Berat: 500, Toko: &daos.Toko{Model: gorm.Model{ID: 1}, IdUser: 2}},
+				},
+				alamats: map[string]*daos.Alamat{"1": {Model: gorm.Model{ID: 1}, IdUser: 1}},
+				users: map[string]*daos.User{
+					"1": {Model: gorm.Model{ID: 1}, IdKota: "3171"},
+					"2": {Model: gorm.Model{ID: 2}, IdKota: "3173"},
+				},
+				createErr:  tt.createErr,
+				paymentErr: tt.paymentErr,
+			}
+			provider := &fakePaymentProvider{repo: repo, err: tt.intentErr}
+			usecase := NewTrxUseCase(repo, payment.NewRegistry(provider), shipping.ProviderInit(), false)
+
+			_, customErr := usecase.CreateTrx(context.Background(), &utils.Principal{UserId: 1}, &dto.TrxCreateReq{
+				MethodBayar: payment.MethodMock,
+				AlamatKirim: 1,
+				DetailTrxes: []*dto.DetailTrxCreateReq{{ProductId: 1, Kuantitas: 1}},
+			})
+			code := 0
+			if customErr != nil {
+				code = customErr.Code
+			}
+			if code != tt.wantCode {
+				t.Fatalf("CreateTrx error = %v, want code %d", customErr, tt.wantCode)
+			}
+
+			// the intent is only issued for a trx already created
+			if len(provider.intents) != tt.wantIntents {
+				t.Fatalf("%d intents issued, want %d", len(provider.intents), tt.wantIntents)
+			}
+			for _, created := range provider.intents {
+				if !created {
+					t.Errorf("intent issued before the trx was created")
+				}
+			}
+
+			trx := repo.trxs["1"]
+			if tt.wantStatus == "" {
+				if trx != nil {
+					t.Errorf("trx created, want none")
+				}
+				return
+			}
+			if trx.Status != tt.wantStatus {
+				t.Errorf("trx status = %s, want %s", trx.Status, tt.wantStatus)
+			}
+
+			// a trx whose payment could not be issued is cancelled by the system, giving back what it reserved
+			if tt.wantStatus == daos.TrxStatusCancelled {
+				if trx.Payment != nil || len(repo.payments) != 0 {
+					t.Errorf("payment attached to the cancelled trx")
+				}
+				if last := repo.histories[len(repo.histories)-1]; last.Peran != daos.TrxActorSystem {
+					t.Errorf("trx cancelled by %s, want the system", last.Peran)
+				}
+				return
+			}
+			if len(repo.payments) != 1 || repo.payments[0].IdTrx != trx.ID || trx.Payment != repo.payments[0] || repo.payments[0].Amount != trx.HargaTotal {
+				t.Errorf("payments = %v, want the payment of the trx", repo.payments)
+			}
+		})
+	}
+}
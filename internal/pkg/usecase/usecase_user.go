@@ -2,25 +2,35 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"tugas_akhir_example/internal/daos"
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/pagination"
 	"tugas_akhir_example/internal/pkg/repository"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type UserUseCase interface {
-	GetMyAlamats(ctx context.Context, token string, filter *dto.AlamatFilter) (res []*dto.AlamatResp, customErr *helper.ErrorStruct)
+	GetMyAlamats(ctx context.Context, principal *utils.Principal, filter *dto.AlamatFilter) (res []*dto.AlamatResp, customErr *helper.ErrorStruct)
 	GetAlamatById(ctx context.Context, id string) (res *dto.AlamatResp, customErr *helper.ErrorStruct)
-	GetMyProfile(ctx context.Context, token string) (res *dto.UserResp, customErr *helper.ErrorStruct)
-	CreateAlamat(ctx context.Context, data *dto.AlamatCreateReq, token string) (res uint, customErr *helper.ErrorStruct)
+	GetMyProfile(ctx context.Context, principal *utils.Principal) (res *dto.UserResp, customErr *helper.ErrorStruct)
+	CreateAlamat(ctx context.Context, data *dto.AlamatCreateReq, principal *utils.Principal) (res uint, customErr *helper.ErrorStruct)
 	UpdateAlamatById(ctx context.Context, id string, data *dto.AlamatUpdateReq) (customErr *helper.ErrorStruct)
-	UpdateProfile(ctx context.Context, id string, data *dto.UserUpdateReq) (customErr *helper.ErrorStruct)
+	UpdateProfile(ctx context.Context, principal *utils.Principal, data *dto.UserUpdateReq) (customErr *helper.ErrorStruct)
 	DeleteAlamatByID(ctx context.Context, id string) (customErr *helper.ErrorStruct)
+	GetMyWishlists(ctx context.Context, principal *utils.Principal, filter *dto.WishlistFilter) (res *dto.AllWishlistResp, customErr *helper.ErrorStruct)
+	AddWishlist(ctx context.Context, principal *utils.Principal, data *dto.WishlistCreateReq) (res uint, customErr *helper.ErrorStruct)
+	RemoveWishlist(ctx context.Context, principal *utils.Principal, produkId string) (customErr *helper.ErrorStruct)
+}
+
+// wishlistSortFields lists the columns the wishlist data can be sorted by
+var wishlistSortFields = pagination.SortFields{
+	"created_at": "created_at",
 }
 
 type UserUseCaseImpl struct {
@@ -35,17 +45,8 @@ func NewUserUseCase(userRepository repository.UserRepository) UserUseCase {
 }
 
 // GetMyAlamats handles the business logic to retrieve alamat data of the current user
-func (alc *UserUseCaseImpl) GetMyAlamats(ctx context.Context, token string, filter *dto.AlamatFilter) (res []*dto.AlamatResp, customErr *helper.ErrorStruct) {
-	claims, err := utils.GetJWTClaims(token)
-	if err != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return nil, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  err,
-		}
-	}
-
-	resRepo, err := alc.userRepository.GetAlamatsByUserId(ctx, claims.UserId, &daos.FilterAlamat{JudulAlamat: filter.JudulAlamat})
+func (alc *UserUseCaseImpl) GetMyAlamats(ctx context.Context, principal *utils.Principal, filter *dto.AlamatFilter) (res []*dto.AlamatResp, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.userRepository.GetAlamatsByUserId(ctx, principal.UserIdString(), &daos.FilterAlamat{JudulAlamat: filter.JudulAlamat})
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -76,17 +77,8 @@ func (alc *UserUseCaseImpl) GetAlamatById(ctx context.Context, id string) (res *
 }
 
 // GetMyProfile handles the business logic to retrieve user data of the current user
-func (alc *UserUseCaseImpl) GetMyProfile(ctx context.Context, token string) (res *dto.UserResp, customErr *helper.ErrorStruct) {
-	claims, err := utils.GetJWTClaims(token)
-	if err != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return nil, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  err,
-		}
-	}
-
-	resRepo, err := alc.userRepository.GetUserById(ctx, claims.UserId)
+func (alc *UserUseCaseImpl) GetMyProfile(ctx context.Context, principal *utils.Principal) (res *dto.UserResp, customErr *helper.ErrorStruct) {
+	resRepo, err := alc.userRepository.GetUserById(ctx, principal.UserIdString())
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 		return nil, &helper.ErrorStruct{
@@ -121,7 +113,7 @@ func (alc *UserUseCaseImpl) GetMyProfile(ctx context.Context, token string) (res
 }
 
 // CreateAlamat handles the business logic to insert the user data
-func (alc *UserUseCaseImpl) CreateAlamat(ctx context.Context, data *dto.AlamatCreateReq, token string) (res uint, customErr *helper.ErrorStruct) {
+func (alc *UserUseCaseImpl) CreateAlamat(ctx context.Context, data *dto.AlamatCreateReq, principal *utils.Principal) (res uint, customErr *helper.ErrorStruct) {
 	if errValidate := helper.Validate.Struct(data); errValidate != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
 		return 0, &helper.ErrorStruct{
@@ -130,26 +122,8 @@ func (alc *UserUseCaseImpl) CreateAlamat(ctx context.Context, data *dto.AlamatCr
 		}
 	}
 
-	claims, err := utils.GetJWTClaims(token)
-	if err != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return 0, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  err,
-		}
-	}
-
-	userId, err := strconv.Atoi(claims.UserId)
-	if err != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return 0, &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  err,
-		}
-	}
-
 	alamatId, err := alc.userRepository.CreateAlamat(ctx, &daos.Alamat{
-		IdUser:       uint(userId),
+		IdUser:       principal.UserId,
 		JudulAlamat:  data.JudulAlamat,
 		NamaPenerima: data.NamaPenerima,
 		Notelp:       data.Notelp,
@@ -186,16 +160,7 @@ func (alc *UserUseCaseImpl) UpdateAlamatById(ctx context.Context, id string, dat
 }
 
 // UpdateAlamatById handles the business logic to update user data of the current user
-func (alc *UserUseCaseImpl) UpdateProfile(ctx context.Context, token string, data *dto.UserUpdateReq) (customErr *helper.ErrorStruct) {
-	claims, err := utils.GetJWTClaims(token)
-	if err != nil {
-		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return &helper.ErrorStruct{
-			Code: fiber.StatusBadRequest,
-			Err:  err,
-		}
-	}
-
+func (alc *UserUseCaseImpl) UpdateProfile(ctx context.Context, principal *utils.Principal, data *dto.UserUpdateReq) (customErr *helper.ErrorStruct) {
 	katasandi, err := utils.HashPassword(data.KataSandi)
 	if err != nil {
 		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
@@ -214,7 +179,7 @@ func (alc *UserUseCaseImpl) UpdateProfile(ctx context.Context, token string, dat
 		}
 	}
 
-	err = alc.userRepository.UpdateUserById(ctx, claims.UserId, &daos.User{
+	err = alc.userRepository.UpdateUserById(ctx, principal.UserIdString(), &daos.User{
 		Nama:         data.Nama,
 		KataSandi:    katasandi,
 		Notelp:       data.Notelp,
@@ -250,3 +215,105 @@ func (alc *UserUseCaseImpl) DeleteAlamatByID(ctx context.Context, id string) (cu
 
 	return nil
 }
+
+// GetMyWishlists handles the business logic to retrieve the wishlist data of the current user along with the current data of the produks
+func (alc *UserUseCaseImpl) GetMyWishlists(ctx context.Context, principal *utils.Principal, filter *dto.WishlistFilter) (res *dto.AllWishlistResp, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	page := pagination.New(filter.Page, filter.Limit)
+	order, err := wishlistSortFields.Clause(filter.Sort, filter.Order, pagination.KeysetClause)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	resRepo, total, err := alc.userRepository.GetWishlistsByUserId(ctx, &daos.FilterWishlist{
+		Limit:  page.Limit,
+		Offset: page.Offset(),
+		IdUser: userId,
+		Order:  order,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	res = &dto.AllWishlistResp{
+		Meta: page.Meta(total),
+		Data: []*dto.WishlistResp{},
+	}
+	for _, v := range resRepo {
+		res.Data = append(res.Data, utils.WishlistToWishlistResp(v))
+	}
+
+	return res, nil
+}
+
+// AddWishlist handles the business logic to add the produk to the wishlist of the current user
+func (alc *UserUseCaseImpl) AddWishlist(ctx context.Context, principal *utils.Principal, data *dto.WishlistCreateReq) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	userId := principal.UserId
+
+	resRepoProduk, err := alc.userRepository.GetProdukById(ctx, data.ProductId)
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("produk tidak ditemukan")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	res, err = alc.userRepository.CreateWishlist(ctx, &daos.Wishlist{
+		IdUser:   userId,
+		IdProduk: resRepoProduk.ID,
+	})
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// RemoveWishlist handles the business logic to remove the produk having the id from the wishlist of the current user
+func (alc *UserUseCaseImpl) RemoveWishlist(ctx context.Context, principal *utils.Principal, produkId string) (customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	if err := alc.userRepository.DeleteWishlist(ctx, userId, produkId); err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("produk tidak ada di wishlist")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
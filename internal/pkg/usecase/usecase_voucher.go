@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/dto"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type VoucherUseCase interface {
+	GetAllVouchers(ctx context.Context, principal *utils.Principal, filter *dto.VoucherFilter) (res *dto.AllVoucherResp, customErr *helper.ErrorStruct)
+	GetVoucherById(ctx context.Context, principal *utils.Principal, id string) (res *dto.VoucherResp, customErr *helper.ErrorStruct)
+	CreateVoucher(ctx context.Context, principal *utils.Principal, data *dto.VoucherCreateReq) (res uint, customErr *helper.ErrorStruct)
+	UpdateVoucherById(ctx context.Context, principal *utils.Principal, id string, data *dto.VoucherUpdateReq) (customErr *helper.ErrorStruct)
+	DeleteVoucherById(ctx context.Context, principal *utils.Principal, id string) (customErr *helper.ErrorStruct)
+}
+
+// voucherManager holds who is managing the vouchers, either an admin managing every voucher or a seller managing the vouchers of the toko
+type voucherManager struct {
+	userId  uint
+	isAdmin bool
+	toko    *daos.Toko
+}
+
+// canManage checks whether the manager is allowed to change the voucher
+func (m *voucherManager) canManage(voucher *daos.Voucher) bool {
+	return m.isAdmin || (m.toko != nil && voucher.IdToko != nil && *voucher.IdToko == m.toko.ID)
+}
+
+// applyVoucher checks the voucher against the detailtrx data at the time, spreads its diskon over the eligible detailtrx data, and returns the total diskon
+func applyVoucher(voucher *daos.Voucher, detailTrxs []*daos.DetailTrx, now time.Time) (res int, err error) {
+	if voucher.Aktif != nil && !*voucher.Aktif {
+		return 0, fmt.Errorf("voucher %s tidak aktif", voucher.Kode)
+	}
+
+	if now.Before(voucher.BerlakuMulai) || !now.Before(voucher.BerlakuSampai.AddDate(0, 0, 1)) {
+		return 0, fmt.Errorf("voucher %s hanya berlaku %s - %s", voucher.Kode, utils.DateToString(voucher.BerlakuMulai), utils.DateToString(voucher.BerlakuSampai))
+	}
+
+	eligibles := []*daos.DetailTrx{}
+	subtotal := 0
+	for _, v := range detailTrxs {
+		if voucher.IdToko != nil && v.IdToko != *voucher.IdToko {
+			continue
+		}
+
+		if voucher.IdCategory != nil && v.LogProduk.IdCategory != *voucher.IdCategory {
+			continue
+		}
+
+		eligibles = append(eligibles, v)
+		subtotal += v.HargaTotal
+	}
+
+	if len(eligibles) == 0 {
+		return 0, fmt.Errorf("voucher %s tidak berlaku untuk produk yang dipesan", voucher.Kode)
+	}
+
+	if subtotal < voucher.MinBelanja {
+		return 0, fmt.Errorf("voucher %s membutuhkan minimal belanja %s", voucher.Kode, utils.FormatRupiah(voucher.MinBelanja))
+	}
+
+	res = voucher.Nilai
+	if voucher.Tipe == daos.VoucherTipePercentage {
+		res = subtotal * voucher.Nilai / 100
+	}
+
+	if voucher.MaxDiskon > 0 && res > voucher.MaxDiskon {
+		res = voucher.MaxDiskon
+	}
+
+	if res > subtotal {
+		res = subtotal
+	}
+
+	remaining := res
+	for i, v := range eligibles {
+		v.Diskon = res * v.HargaTotal / subtotal
+		if i == len(eligibles)-1 {
+			v.Diskon = remaining
+		}
+		remaining -= v.Diskon
+	}
+
+	return res, nil
+}
+
+type VoucherUseCaseImpl struct {
+	voucherRepository repository.VoucherRepository
+}
+
+// NewVoucherUseCase returns the usecase for the voucher group path
+func NewVoucherUseCase(voucherRepository repository.VoucherRepository) VoucherUseCase {
+	return &VoucherUseCaseImpl{
+		voucherRepository: voucherRepository,
+	}
+}
+
+// GetAllVouchers handles the business logic to retrieve all voucher data managed by the current user
+func (alc *VoucherUseCaseImpl) GetAllVouchers(ctx context.Context, principal *utils.Principal, filter *dto.VoucherFilter) (res *dto.AllVoucherResp, customErr *helper.ErrorStruct) {
+	manager, customErr := alc.getVoucherManager(ctx, principal)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if filter.Limit < 1 {
+		filter.Limit = 10
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	filterVoucher := &daos.FilterVoucher{
+		Limit:  filter.Limit,
+		Offset: (filter.Page - 1) * filter.Limit,
+		Kode:   strings.ToUpper(filter.Kode),
+	}
+	if !manager.isAdmin {
+		filterVoucher.IdToko = &manager.toko.ID
+	}
+
+	resRepo, err := alc.voucherRepository.GetAllVouchers(ctx, filterVoucher)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	res = &dto.AllVoucherResp{
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Data:  []*dto.VoucherResp{},
+	}
+	for _, v := range resRepo {
+		res.Data = append(res.Data, utils.VoucherToVoucherResp(v))
+	}
+
+	return res, nil
+}
+
+// GetVoucherById handles the business logic to retrieve voucher data having the id
+func (alc *VoucherUseCaseImpl) GetVoucherById(ctx context.Context, principal *utils.Principal, id string) (res *dto.VoucherResp, customErr *helper.ErrorStruct) {
+	_, resRepo, customErr := alc.getManagedVoucher(ctx, principal, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	return utils.VoucherToVoucherResp(resRepo), nil
+}
+
+// CreateVoucher handles the business logic to insert the voucher data, owned by the toko of a seller or by the platform for an admin
+func (alc *VoucherUseCaseImpl) CreateVoucher(ctx context.Context, principal *utils.Principal, data *dto.VoucherCreateReq) (res uint, customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return 0, &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	manager, customErr := alc.getVoucherManager(ctx, principal)
+	if customErr != nil {
+		return 0, customErr
+	}
+
+	voucher := &daos.Voucher{
+		Kode:         strings.ToUpper(data.Kode),
+		Deskripsi:    data.Deskripsi,
+		Tipe:         data.Tipe,
+		Nilai:        data.Nilai,
+		MinBelanja:   data.MinBelanja,
+		MaxDiskon:    data.MaxDiskon,
+		KuotaGlobal:  data.KuotaGlobal,
+		KuotaPerUser: data.KuotaPerUser,
+		Aktif:        data.Aktif,
+		IdPembuat:    manager.userId,
+	}
+
+	var err error
+	voucher.BerlakuMulai, err = utils.StringToDate(data.BerlakuMulai)
+	if err == nil {
+		voucher.BerlakuSampai, err = utils.StringToDate(data.BerlakuSampai)
+	}
+
+	if err == nil {
+		err = validateVoucher(voucher)
+	}
+
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if !manager.isAdmin {
+		voucher.IdToko = &manager.toko.ID
+	} else if data.IdToko != 0 {
+		voucher.IdToko = &data.IdToko
+	}
+
+	if data.IdCategory != 0 {
+		voucher.IdCategory = &data.IdCategory
+	}
+
+	res, err = alc.voucherRepository.CreateVoucher(ctx, voucher)
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return 0, &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateVoucherById handles the business logic to update voucher data having the id
+func (alc *VoucherUseCaseImpl) UpdateVoucherById(ctx context.Context, principal *utils.Principal, id string, data *dto.VoucherUpdateReq) (customErr *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", errValidate.Error()))
+		return &helper.ErrorStruct{
+			Err:  errValidate,
+			Code: fiber.StatusBadRequest,
+		}
+	}
+
+	_, resRepo, customErr := alc.getManagedVoucher(ctx, principal, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	voucherData := &daos.Voucher{
+		Kode:         strings.ToUpper(data.Kode),
+		Deskripsi:    data.Deskripsi,
+		Tipe:         data.Tipe,
+		Nilai:        data.Nilai,
+		MinBelanja:   data.MinBelanja,
+		MaxDiskon:    data.MaxDiskon,
+		KuotaGlobal:  data.KuotaGlobal,
+		KuotaPerUser: data.KuotaPerUser,
+		Aktif:        data.Aktif,
+	}
+
+	var err error
+	if data.BerlakuMulai != "" {
+		voucherData.BerlakuMulai, err = utils.StringToDate(data.BerlakuMulai)
+	}
+
+	if err == nil && data.BerlakuSampai != "" {
+		voucherData.BerlakuSampai, err = utils.StringToDate(data.BerlakuSampai)
+	}
+
+	if data.IdCategory != 0 {
+		voucherData.IdCategory = &data.IdCategory
+	}
+
+	if err == nil {
+		// validate the voucher as it will be once the update is applied
+		merged := *resRepo
+		if voucherData.Tipe != "" {
+			merged.Tipe = voucherData.Tipe
+		}
+		if voucherData.Nilai != 0 {
+			merged.Nilai = voucherData.Nilai
+		}
+		if !voucherData.BerlakuMulai.IsZero() {
+			merged.BerlakuMulai = voucherData.BerlakuMulai
+		}
+		if !voucherData.BerlakuSampai.IsZero() {
+			merged.BerlakuSampai = voucherData.BerlakuSampai
+		}
+		err = validateVoucher(&merged)
+	}
+
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	if err := alc.voucherRepository.UpdateVoucher(ctx, resRepo, voucherData); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// DeleteVoucherById handles the business logic to delete voucher data having the id
+func (alc *VoucherUseCaseImpl) DeleteVoucherById(ctx context.Context, principal *utils.Principal, id string) (customErr *helper.ErrorStruct) {
+	_, resRepo, customErr := alc.getManagedVoucher(ctx, principal, id)
+	if customErr != nil {
+		return customErr
+	}
+
+	if err := alc.voucherRepository.DeleteVoucher(ctx, resRepo); err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return &helper.ErrorStruct{
+			Code: fiber.StatusBadRequest,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// validateVoucher checks the rules the fields of the voucher have to follow together
+func validateVoucher(voucher *daos.Voucher) error {
+	if voucher.Tipe == daos.VoucherTipePercentage && voucher.Nilai > 100 {
+		return errors.New("nilai voucher percentage tidak boleh lebih dari 100")
+	}
+
+	if voucher.BerlakuSampai.Before(voucher.BerlakuMulai) {
+		return errors.New("berlaku_sampai tidak boleh sebelum berlaku_mulai")
+	}
+
+	return nil
+}
+
+// getVoucherManager returns the current user as a voucher manager, failing for users that can neither manage every voucher nor own a toko
+func (alc *VoucherUseCaseImpl) getVoucherManager(ctx context.Context, principal *utils.Principal) (res *voucherManager, customErr *helper.ErrorStruct) {
+	userId := principal.UserId
+
+	res = &voucherManager{
+		userId:  userId,
+		isAdmin: principal.Can(rbac.PermissionVoucherManageAll),
+	}
+	if res.isAdmin {
+		return res, nil
+	}
+
+	var err error
+	res.toko, err = alc.voucherRepository.GetTokoByUserId(ctx, strconv.Itoa(int(userId)))
+	if err != nil {
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, &helper.ErrorStruct{
+			Code: fiber.StatusForbidden,
+			Err:  errors.New("you are unauthorized"),
+		}
+	}
+
+	return res, nil
+}
+
+// getManagedVoucher returns the voucher having the id if the current user is allowed to manage it
+func (alc *VoucherUseCaseImpl) getManagedVoucher(ctx context.Context, principal *utils.Principal, id string) (manager *voucherManager, res *daos.Voucher, customErr *helper.ErrorStruct) {
+	manager, customErr = alc.getVoucherManager(ctx, principal)
+	if customErr != nil {
+		return nil, nil, customErr
+	}
+
+	res, err := alc.voucherRepository.GetVoucherById(ctx, id)
+	if err == nil && !manager.canManage(res) {
+		err = gorm.ErrRecordNotFound
+	}
+
+	if err != nil {
+		code := fiber.StatusBadRequest
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = fiber.StatusNotFound
+			err = errors.New("no data voucher")
+		}
+
+		helper.Logger(utils.GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		return nil, nil, &helper.ErrorStruct{
+			Code: code,
+			Err:  err,
+		}
+	}
+
+	return manager, res, nil
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"tugas_akhir_example/internal/daos"
+)
+
+func TestApplyVoucher(t *testing.T) {
+	mulai := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	sampai := time.Date(2026, 10, 31, 0, 0, 0, 0, time.Local)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	tidakAktif := false
+	toko, category := uint(12), uint(3)
+
+	// line is a detailtrx of the toko and category costing the harga total
+	type line struct {
+		idToko, idCategory uint
+		hargaTotal         int
+	}
+	tests := []struct {
+		name       string
+		voucher    daos.Voucher
+		lines      []line
+		now        time.Time
+		want       int
+		wantErr    bool
+		wantDiskon []int
+	}{
+		{
+			name:       "fixed",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:      []line{{toko, category, 50000}},
+			want:       10000,
+			wantDiskon: []int{10000},
+		},
+		{
+			name:    "inactive",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, Aktif: &tidakAktif},
+			lines:   []line{{toko, category, 50000}},
+			wantErr: true,
+		},
+		{
+			name:    "before the window",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:   []line{{toko, category, 50000}},
+			now:     mulai.Add(-time.Second),
+			wantErr: true,
+		},
+		{
+			name:       "first moment of the window",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:      []line{{toko, category, 50000}},
+			now:        mulai,
+			want:       10000,
+			wantDiskon: []int{10000},
+		},
+		{
+			name:       "last day of the window",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:      []line{{toko, category, 50000}},
+			now:        sampai.Add(24*time.Hour - time.Second),
+			want:       10000,
+			wantDiskon: []int{10000},
+		},
+		{
+			name:    "after the window",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:   []line{{toko, category, 50000}},
+			now:     sampai.Add(24 * time.Hour),
+			wantErr: true,
+		},
+		{
+			name:       "toko scope",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, IdToko: &toko},
+			lines:      []line{{toko, category, 30000}, {7, category, 50000}},
+			want:       10000,
+			wantDiskon: []int{10000, 0},
+		},
+		{
+			name:    "toko scope without a line of the toko",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, IdToko: &toko},
+			lines:   []line{{7, category, 50000}},
+			wantErr: true,
+		},
+		{
+			name:       "category scope",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, IdCategory: &category},
+			lines:      []line{{toko, 4, 50000}, {toko, category, 30000}},
+			want:       10000,
+			wantDiskon: []int{0, 10000},
+		},
+		{
+			name:    "category scope without a line of the category",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, IdCategory: &category},
+			lines:   []line{{toko, 4, 50000}},
+			wantErr: true,
+		},
+		{
+			name:       "minimum spend reached",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, MinBelanja: 50000},
+			lines:      []line{{toko, category, 20000}, {toko, category, 30000}},
+			want:       10000,
+			wantDiskon: []int{4000, 6000},
+		},
+		{
+			name:    "minimum spend missed",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, MinBelanja: 50000},
+			lines:   []line{{toko, category, 49999}},
+			wantErr: true,
+		},
+		{
+			name:    "minimum spend counts the eligible lines only",
+			voucher: daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000, MinBelanja: 50000, IdToko: &toko},
+			lines:   []line{{toko, category, 20000}, {7, category, 80000}},
+			wantErr: true,
+		},
+		{
+			name:       "percentage",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipePercentage, Nilai: 10},
+			lines:      []line{{toko, category, 50000}},
+			want:       5000,
+			wantDiskon: []int{5000},
+		},
+		{
+			name:       "percentage below the cap",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipePercentage, Nilai: 10, MaxDiskon: 8000},
+			lines:      []line{{toko, category, 50000}},
+			want:       5000,
+			wantDiskon: []int{5000},
+		},
+		{
+			name:       "percentage over the cap",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipePercentage, Nilai: 50, MaxDiskon: 8000},
+			lines:      []line{{toko, category, 50000}},
+			want:       8000,
+			wantDiskon: []int{8000},
+		},
+		{
+			name:       "fixed over the subtotal",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 100000},
+			lines:      []line{{toko, category, 30000}},
+			want:       30000,
+			wantDiskon: []int{30000},
+		},
+		{
+			name:       "spread proportionally",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 9000},
+			lines:      []line{{toko, category, 10000}, {toko, category, 20000}, {toko, category, 60000}},
+			want:       9000,
+			wantDiskon: []int{1000, 2000, 6000},
+		},
+		{
+			name:       "spread with the rounding left on the last line",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipeFixed, Nilai: 10000},
+			lines:      []line{{toko, category, 10000}, {toko, category, 10000}, {toko, category, 10000}},
+			want:       10000,
+			wantDiskon: []int{3333, 3333, 3334},
+		},
+		{
+			name:       "spread of a capped percentage",
+			voucher:    daos.Voucher{Tipe: daos.VoucherTipePercentage, Nilai: 20, MaxDiskon: 7000, IdToko: &toko},
+			lines:      []line{{toko, category, 12345}, {7, category, 99999}, {toko, category, 23456}, {toko, 4, 11111}},
+			want:       7000,
+			wantDiskon: []int{1842, 0, 3500, 1658},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher := tt.voucher
+			voucher.Kode = "HEMAT"
+			voucher.BerlakuMulai, voucher.BerlakuSampai = mulai, sampai
+
+			detailTrxs := []*daos.DetailTrx{}
+			for _, v := range tt.lines {
+				detailTrxs = append(detailTrxs, &daos.DetailTrx{
+					IdToko:     v.idToko,
+					HargaTotal: v.hargaTotal,
+					LogProduk:  &daos.LogProduk{IdToko: v.idToko, IdCategory: v.idCategory},
+				})
+			}
+
+			at := now
+			if !tt.now.IsZero() {
+				at = tt.now
+			}
+
+			got, err := applyVoucher(&voucher, detailTrxs, at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyVoucher error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("applyVoucher = %d, want %d", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// the diskon of the lines adds up exactly to the diskon of the trx
+			sum := 0
+			for i, v := range detailTrxs {
+				if v.Diskon != tt.wantDiskon[i] {
+					t.Errorf("line %d diskon = %d, want %d", i, v.Diskon, tt.wantDiskon[i])
+				}
+				sum += v.Diskon
+			}
+			if sum != got {
+				t.Errorf("diskon of the lines = %d, want %d", sum, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -15,10 +16,31 @@ import (
 // AuthRoute routes the auth group path
 func AuthRoute(r fiber.Router, containerConf *container.Container) {
 	repo := repository.NewAuthRepository(containerConf.Mysqldb)
-	usecase := usecase.NewAuthUseCase(repo, containerConf.Apps.SecretJwt)
+	userSessionRepo := repository.NewUserSessionRepository(containerConf.Mysqldb)
+	userOtpRepo := repository.NewUserOtpRepository(containerConf.Mysqldb)
+	otpUsecase := usecase.NewOtpUseCase(repo, userOtpRepo, userSessionRepo, containerConf.Notifier, containerConf.Apps.SecretJwt,
+		containerConf.Apps.OtpTtl, containerConf.Apps.OtpResendInterval, containerConf.Apps.OtpMaxAttempts)
+	otpController := controller.NewOtpController(otpUsecase)
+	usecase := usecase.NewAuthUseCase(repo, userSessionRepo, containerConf.Apps.SecretJwt, containerConf.Apps.AccessTokenTtl, containerConf.Apps.RefreshTokenTtl,
+		containerConf.Limiter)
 	controller := controller.NewAuthController(usecase)
 
+	limiter := containerConf.Limiter
+	loginLimit := utils.RateLimitMiddleware(limiter, "login", limiter.Rules.Login)
+	registerLimit := utils.RateLimitMiddleware(limiter, "register", limiter.Rules.Register)
+	// the password reset paths are limited like the login, on their own windows
+	passwordLimit := utils.RateLimitMiddleware(limiter, "password", limiter.Rules.Login)
+
 	authAPI := r.Group("/auth")
-	authAPI.Post("register", controller.RegisterUsers)
-	authAPI.Post("login", controller.LoginUsers)
+	authAPI.Post("register", utils.Public(), registerLimit, controller.RegisterUsers)
+	authAPI.Post("login", utils.Public(), loginLimit, controller.LoginUsers)
+	authAPI.Post("refresh", utils.Public(), controller.RefreshToken)
+	authAPI.Post("logout", utils.AuthMiddleware(), controller.LogoutUsers)
+
+	authAPI.Post("verify-phone/request", utils.AuthMiddleware(), otpController.RequestNotelpVerification)
+	authAPI.Post("verify-phone", utils.AuthMiddleware(), otpController.VerifyNotelp)
+	authAPI.Post("verify-email/request", utils.AuthMiddleware(), otpController.RequestEmailVerification)
+	authAPI.Post("verify-email", utils.AuthMiddleware(), otpController.VerifyEmail)
+	authAPI.Post("forgot-password", utils.Public(), passwordLimit, otpController.ForgotPassword)
+	authAPI.Post("reset-password", utils.Public(), passwordLimit, otpController.ResetPassword)
 }
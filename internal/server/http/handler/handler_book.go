@@ -2,6 +2,8 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -19,9 +21,9 @@ func BookRoute(r fiber.Router, containerConf *container.Container) {
 	controller := bookcontroller.NewBookController(usecase)
 
 	bookAPI := r.Group("/book")
-	bookAPI.Get("", controller.GetAllBook)
-	bookAPI.Get("/:id_book", controller.GetBookByID)
-	bookAPI.Post("", controller.CreateBook)
-	bookAPI.Put("/:id_book", controller.UpdateBookByID)
-	bookAPI.Delete("/:id_book", controller.DeleteBookByID)
+	bookAPI.Get("", utils.Public(), controller.GetAllBook)
+	bookAPI.Get("/:id_book", utils.Public(), controller.GetBookByID)
+	bookAPI.Post("", utils.RequirePermission(rbac.PermissionBookManage), controller.CreateBook)
+	bookAPI.Put("/:id_book", utils.RequirePermission(rbac.PermissionBookManage), controller.UpdateBookByID)
+	bookAPI.Delete("/:id_book", utils.RequirePermission(rbac.PermissionBookManage), controller.DeleteBookByID)
 }
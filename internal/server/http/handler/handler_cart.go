@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tugas_akhir_example/internal/pkg/controller"
+
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"tugas_akhir_example/internal/pkg/usecase"
+)
+
+// CartRoute routes the cart group path
+func CartRoute(r fiber.Router, containerConf *container.Container) {
+	trxRepo := repository.NewTrxRepository(containerConf.Mysqldb)
+	trxUsecase := usecase.NewTrxUseCase(trxRepo, containerConf.Payments, containerConf.Shipping, containerConf.Apps.RequireVerifiedCheckout)
+
+	repo := repository.NewCartRepository(containerConf.Mysqldb)
+	usecase := usecase.NewCartUseCase(repo, trxUsecase)
+	controller := controller.NewCartController(usecase)
+
+	idempotencyRepo := repository.NewIdempotencyRepository(containerConf.Mysqldb)
+	idempotencyMiddleware := utils.IdempotencyMiddleware(idempotencyRepo, containerConf.Apps.IdempotencyTtl)
+
+	cartAPI := r.Group("/cart")
+	cartAPI.Get("", utils.RequirePermission(rbac.PermissionCartManage), controller.GetMyCart)
+	cartAPI.Post("items", utils.RequirePermission(rbac.PermissionCartManage), controller.AddCartItem)
+	cartAPI.Put("items/:id", utils.RequirePermission(rbac.PermissionCartManage), controller.UpdateCartItem)
+	cartAPI.Delete("items/:id", utils.RequirePermission(rbac.PermissionCartManage), controller.DeleteCartItem)
+	cartAPI.Post("checkout", utils.RequirePermission(rbac.PermissionTrxBuy), idempotencyMiddleware, controller.CheckoutCart)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,9 +21,9 @@ func CategoryRoute(r fiber.Router, containerConf *container.Container) {
 	controller := controller.NewCategoryController(usecase)
 
 	categoryAPI := r.Group("/category")
-	categoryAPI.Get("", controller.GetAllCategories)
-	categoryAPI.Get(":id", controller.GetCategoryById)
-	categoryAPI.Post("", utils.CategoryAuthMiddleware(repo), controller.CreateCategory)
-	categoryAPI.Put(":id", utils.CategoryAuthMiddleware(repo), controller.UpdateCategoryById)
-	categoryAPI.Delete(":id", utils.CategoryAuthMiddleware(repo), controller.DeleteCategoryById)
+	categoryAPI.Get("", utils.Public(), controller.GetAllCategories)
+	categoryAPI.Get(":id", utils.Public(), controller.GetCategoryById)
+	categoryAPI.Post("", utils.RequirePermission(rbac.PermissionCategoryManage), controller.CreateCategory)
+	categoryAPI.Put(":id", utils.RequirePermission(rbac.PermissionCategoryManage), controller.UpdateCategoryById)
+	categoryAPI.Delete(":id", utils.RequirePermission(rbac.PermissionCategoryManage), controller.DeleteCategoryById)
 }
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tugas_akhir_example/internal/pkg/controller"
+
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"tugas_akhir_example/internal/pkg/usecase"
+)
+
+// PaymentRoute routes the payment group path
+func PaymentRoute(r fiber.Router, containerConf *container.Container) {
+	repo := repository.NewPaymentRepository(containerConf.Mysqldb)
+	usecase := usecase.NewPaymentUseCase(repo, containerConf.Payments)
+	controller := controller.NewPaymentController(usecase)
+
+	// the webhooks are authenticated by the signature of their provider
+	paymentAPI := r.Group("/payment")
+	paymentAPI.Post("webhook/:provider", utils.Public(), controller.HandleWebhook)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,13 +17,25 @@ import (
 // ProdukRoute routes the produk group path
 func ProdukRoute(r fiber.Router, containerConf *container.Container) {
 	repo := repository.NewProdukRepository(containerConf.Mysqldb)
-	usecase := usecase.NewProdukUseCase(repo)
+	usecase := usecase.NewProdukUseCase(repo, containerConf.Searcher, containerConf.Storage)
 	controller := controller.NewProdukController(usecase)
 
+	idempotencyRepo := repository.NewIdempotencyRepository(containerConf.Mysqldb)
+	idempotencyMiddleware := utils.IdempotencyMiddleware(idempotencyRepo, containerConf.Apps.IdempotencyTtl)
+	produkManage := utils.RequirePermission(rbac.PermissionProdukManage)
+
 	produkAPI := r.Group("/product")
-	produkAPI.Get("", controller.GetAllProduks)
-	produkAPI.Get(":id", controller.GetProdukById)
-	produkAPI.Post("", controller.CreateProduk)
-	produkAPI.Put(":id", utils.ProdukAuthMiddleware(repo), controller.UpdateProdukById)
-	produkAPI.Delete(":id", utils.ProdukAuthMiddleware(repo), controller.DeleteProdukById)
+	produkAPI.Get("", utils.Public(), controller.GetAllProduks)
+	produkAPI.Get("search", utils.Public(), controller.SearchProduks)
+	produkAPI.Get("slug/:slug", utils.Public(), controller.GetProdukBySlug)
+	produkAPI.Get(":id", utils.Public(), controller.GetProdukById)
+	produkAPI.Post("", produkManage, idempotencyMiddleware, controller.CreateProduk)
+	produkAPI.Put(":id", produkManage, utils.ProdukAuthMiddleware(repo), controller.UpdateProdukById)
+	produkAPI.Delete(":id", produkManage, utils.ProdukAuthMiddleware(repo), controller.DeleteProdukById)
+
+	produkAPI.Get(":id/variants", utils.Public(), controller.GetProdukVariants)
+	produkAPI.Get(":id/variants/:id_variant", utils.Public(), controller.GetProdukVariantById)
+	produkAPI.Post(":id/variants", produkManage, utils.ProdukAuthMiddleware(repo), controller.CreateProdukVariant)
+	produkAPI.Put(":id/variants/:id_variant", produkManage, utils.ProdukAuthMiddleware(repo), controller.UpdateProdukVariantById)
+	produkAPI.Delete(":id/variants/:id_variant", produkManage, utils.ProdukAuthMiddleware(repo), controller.DeleteProdukVariantById)
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -19,8 +20,8 @@ func ProvinceCityRoute(r fiber.Router, containerConf *container.Container) {
 	controller := controller.NewProvinceCityController(usecase)
 
 	provinceCityAPI := r.Group("/provcity")
-	provinceCityAPI.Get("listprovincies", controller.GetAllProvinces)
-	provinceCityAPI.Get("listcities/:prov_id", controller.GetAllCities)
-	provinceCityAPI.Get("detailprovince/:prov_id", controller.GetProvinceById)
-	provinceCityAPI.Get("detailcity/:city_id", controller.GetCityById)
+	provinceCityAPI.Get("listprovincies", utils.Public(), controller.GetAllProvinces)
+	provinceCityAPI.Get("listcities/:prov_id", utils.Public(), controller.GetAllCities)
+	provinceCityAPI.Get("detailprovince/:prov_id", utils.Public(), controller.GetProvinceById)
+	provinceCityAPI.Get("detailcity/:city_id", utils.Public(), controller.GetCityById)
 }
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tugas_akhir_example/internal/pkg/controller"
+
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"tugas_akhir_example/internal/pkg/usecase"
+)
+
+// ReviewRoute routes the review group path along with the review list of each produk
+func ReviewRoute(r fiber.Router, containerConf *container.Container) {
+	repo := repository.NewReviewRepository(containerConf.Mysqldb)
+	usecase := usecase.NewReviewUseCase(repo, containerConf.Storage)
+	controller := controller.NewReviewController(usecase)
+
+	r.Get("/product/:id/reviews", utils.Public(), controller.GetProdukReviews)
+
+	reviewAPI := r.Group("/review")
+	reviewAPI.Get(":id", utils.Public(), controller.GetReviewById)
+	reviewAPI.Post("", utils.RequirePermission(rbac.PermissionReviewCreate), controller.CreateReview)
+	reviewAPI.Put(":id/reply", utils.RequirePermission(rbac.PermissionReviewReply), controller.ReplyReview)
+}
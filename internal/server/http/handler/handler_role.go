@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tugas_akhir_example/internal/pkg/controller"
+
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"tugas_akhir_example/internal/pkg/usecase"
+)
+
+// RoleRoute routes the role group path, letting the admins manage the roles of the users
+func RoleRoute(r fiber.Router, containerConf *container.Container) {
+	repo := repository.NewUserRoleRepository(containerConf.Mysqldb)
+	usecase := usecase.NewRoleUseCase(repo)
+	controller := controller.NewRoleController(usecase)
+
+	roleManage := utils.RequirePermission(rbac.PermissionRoleManage)
+
+	adminAPI := r.Group("/admin")
+	adminAPI.Get("roles", roleManage, controller.GetAllRoles)
+	adminAPI.Get("users/:id_user/roles", roleManage, controller.GetUserRoles)
+	adminAPI.Post("users/:id_user/roles", roleManage, controller.GrantUserRole)
+	adminAPI.Delete("users/:id_user/roles/:role", roleManage, controller.RevokeUserRole)
+}
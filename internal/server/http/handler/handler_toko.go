@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,12 +17,16 @@ import (
 // TokoRoute routes the toko group path
 func TokoRoute(r fiber.Router, containerConf *container.Container) {
 	repo := repository.NewTokoRepository(containerConf.Mysqldb)
-	usecase := usecase.NewTokoUseCase(repo, containerConf.Apps.SecretJwt)
+	usecase := usecase.NewTokoUseCase(repo, containerConf.Storage, containerConf.Apps.SecretJwt)
 	controller := controller.NewTokoController(usecase)
 
 	tokoAPI := r.Group("/toko")
-	tokoAPI.Get("", controller.GetAllToko)
-	tokoAPI.Get("my", controller.GetMyToko)
-	tokoAPI.Get(":id_toko", controller.GetTokoById)
-	tokoAPI.Put(":id_toko", utils.TokoAuthMiddleware(repo), controller.UpdateTokoByID)
+	tokoAPI.Get("", utils.Public(), controller.GetAllToko)
+	tokoAPI.Get("my", utils.RequirePermission(rbac.PermissionTokoManage), controller.GetMyToko)
+	tokoAPI.Get("my/orders", utils.RequirePermission(rbac.PermissionTokoManage), controller.GetMyOrders)
+	tokoAPI.Get("slug/:slug", utils.Public(), controller.GetTokoBySlug)
+	tokoAPI.Get(":id_toko", utils.Public(), controller.GetTokoById)
+	tokoAPI.Put(":id_toko", utils.RequirePermission(rbac.PermissionTokoManage), utils.TokoAuthMiddleware(repo), controller.UpdateTokoByID)
+	tokoAPI.Post(":id_toko/follow", utils.RequirePermission(rbac.PermissionTokoFollow), controller.FollowToko)
+	tokoAPI.Delete(":id_toko/follow", utils.RequirePermission(rbac.PermissionTokoFollow), controller.UnfollowToko)
 }
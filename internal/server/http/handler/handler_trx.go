@@ -2,6 +2,8 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -15,11 +17,22 @@ import (
 // TrxRoute routes the trx group path
 func TrxRoute(r fiber.Router, containerConf *container.Container) {
 	repo := repository.NewTrxRepository(containerConf.Mysqldb)
-	usecase := usecase.NewTrxUseCase(repo)
+	usecase := usecase.NewTrxUseCase(repo, containerConf.Payments, containerConf.Shipping, containerConf.Apps.RequireVerifiedCheckout)
 	controller := controller.NewTrxController(usecase)
 
+	idempotencyRepo := repository.NewIdempotencyRepository(containerConf.Mysqldb)
+	idempotencyMiddleware := utils.IdempotencyMiddleware(idempotencyRepo, containerConf.Apps.IdempotencyTtl)
+
 	trxAPI := r.Group("/trx")
-	trxAPI.Get("", controller.GetAllTrxs)
-	trxAPI.Get(":id", controller.GetTrxById)
-	trxAPI.Post("", controller.CreateTrx)
+	trxAPI.Get("", utils.RequirePermission(rbac.PermissionTrxRead), controller.GetAllTrxs)
+	trxAPI.Get("invoice", utils.RequirePermission(rbac.PermissionTrxRead), controller.GetTrxByKodeInvoice)
+	trxAPI.Get(":id", utils.RequirePermission(rbac.PermissionTrxRead), controller.GetTrxById)
+	trxAPI.Post("", utils.RequirePermission(rbac.PermissionTrxBuy), idempotencyMiddleware, controller.CreateTrx)
+	trxAPI.Post("shipping/quote", utils.RequirePermission(rbac.PermissionTrxBuy), controller.QuoteShipping)
+	trxAPI.Get(":id/invoice.pdf", utils.RequirePermission(rbac.PermissionTrxRead), controller.GetTrxInvoicePDF)
+	trxAPI.Get(":id/packing-slip.pdf", utils.RequirePermission(rbac.PermissionTrxSell), controller.GetTrxPackingSlipPDF)
+	trxAPI.Get(":id/status", utils.RequirePermission(rbac.PermissionTrxRead), controller.GetTrxStatusHistories)
+	trxAPI.Put(":id/status/buyer", utils.RequirePermission(rbac.PermissionTrxBuy), controller.UpdateTrxStatusByBuyer)
+	trxAPI.Put(":id/status/seller", utils.RequirePermission(rbac.PermissionTrxSell), controller.UpdateTrxStatusBySeller)
+	trxAPI.Put(":id/status/admin", utils.RequirePermission(rbac.PermissionTrxModerate), controller.UpdateTrxStatusByAdmin)
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
 	"tugas_akhir_example/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,12 +20,18 @@ func UserRoute(r fiber.Router, containerConf *container.Container) {
 	usecase := usecase.NewUserUseCase(repo)
 	controller := controller.NewUserController(usecase)
 
+	idempotencyRepo := repository.NewIdempotencyRepository(containerConf.Mysqldb)
+	idempotencyMiddleware := utils.IdempotencyMiddleware(idempotencyRepo, containerConf.Apps.IdempotencyTtl)
+
 	userAPI := r.Group("/user")
-	userAPI.Get("", controller.GetMyProfile)
-	userAPI.Put("", controller.UpdateProfile)
-	userAPI.Get("alamat", controller.GetMyAlamats)
-	userAPI.Get("alamat/:id", utils.AlamatAuthMiddleware(repo), controller.GetAlamatById)
-	userAPI.Post("alamat", controller.CreateAlamat)
-	userAPI.Put("alamat/:id", utils.AlamatAuthMiddleware(repo), controller.UpdateAlamatById)
-	userAPI.Delete("alamat/:id", utils.AlamatAuthMiddleware(repo), controller.DeleteAlamatById)
+	userAPI.Get("", utils.RequirePermission(rbac.PermissionProfileManage), controller.GetMyProfile)
+	userAPI.Put("", utils.RequirePermission(rbac.PermissionProfileManage), controller.UpdateProfile)
+	userAPI.Get("alamat", utils.RequirePermission(rbac.PermissionAlamatManage), controller.GetMyAlamats)
+	userAPI.Get("alamat/:id", utils.RequirePermission(rbac.PermissionAlamatManage), utils.AlamatAuthMiddleware(repo), controller.GetAlamatById)
+	userAPI.Post("alamat", utils.RequirePermission(rbac.PermissionAlamatManage), idempotencyMiddleware, controller.CreateAlamat)
+	userAPI.Put("alamat/:id", utils.RequirePermission(rbac.PermissionAlamatManage), utils.AlamatAuthMiddleware(repo), controller.UpdateAlamatById)
+	userAPI.Delete("alamat/:id", utils.RequirePermission(rbac.PermissionAlamatManage), utils.AlamatAuthMiddleware(repo), controller.DeleteAlamatById)
+	userAPI.Get("wishlist", utils.RequirePermission(rbac.PermissionWishlistManage), controller.GetMyWishlists)
+	userAPI.Post("wishlist", utils.RequirePermission(rbac.PermissionWishlistManage), controller.AddWishlist)
+	userAPI.Delete("wishlist/:id_produk", utils.RequirePermission(rbac.PermissionWishlistManage), controller.RemoveWishlist)
 }
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"tugas_akhir_example/internal/infrastructure/container"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tugas_akhir_example/internal/pkg/controller"
+
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"tugas_akhir_example/internal/pkg/usecase"
+)
+
+// VoucherRoute routes the voucher group path
+func VoucherRoute(r fiber.Router, containerConf *container.Container) {
+	repo := repository.NewVoucherRepository(containerConf.Mysqldb)
+	usecase := usecase.NewVoucherUseCase(repo)
+	controller := controller.NewVoucherController(usecase)
+
+	voucherAPI := r.Group("/voucher")
+	voucherAPI.Get("", utils.RequirePermission(rbac.PermissionVoucherManage), controller.GetAllVouchers)
+	voucherAPI.Get(":id", utils.RequirePermission(rbac.PermissionVoucherManage), controller.GetVoucherById)
+	voucherAPI.Post("", utils.RequirePermission(rbac.PermissionVoucherManage), controller.CreateVoucher)
+	voucherAPI.Put(":id", utils.RequirePermission(rbac.PermissionVoucherManage), controller.UpdateVoucherById)
+	voucherAPI.Delete(":id", utils.RequirePermission(rbac.PermissionVoucherManage), controller.DeleteVoucherById)
+}
@@ -1,6 +1,11 @@
 package http
 
 import (
+	"fmt"
+	"strings"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/utils"
+
 	route "tugas_akhir_example/internal/server/http/handler"
 
 	"tugas_akhir_example/internal/infrastructure/container"
@@ -20,6 +25,16 @@ func HTTPRouteInit(r *fiber.App, containerConf *container.Container) {
 	route.UserRoute(api, containerConf)
 	route.CategoryRoute(api, containerConf)
 	route.TrxRoute(api, containerConf)
+	route.CartRoute(api, containerConf)
+	route.PaymentRoute(api, containerConf)
+	route.VoucherRoute(api, containerConf)
+	route.ReviewRoute(api, containerConf)
+	route.RoleRoute(api, containerConf)
+
+	// every route must state who may call it, refusing to start rather than serving a route left open by mistake
+	if routes := utils.RoutesWithoutPolicy(r, "/api"); len(routes) > 0 {
+		helper.Logger("httproute.go", helper.LoggerLevelFatal, fmt.Sprintf("routes without a policy : %s", strings.Join(routes, ", ")))
+	}
 
 	r.Static("/static", "./static")
 }
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/rbac"
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// LegacyTokenHeader is the header the jwt token used to be sent on, still accepted when the Authorization header is missing
+	LegacyTokenHeader  = "token"
+	principalLocalsKey = "principal"
+)
+
+var (
+	ErrTokenMissing = errors.New("token tidak ditemukan")
+
+	userRoleRepository repository.UserRoleRepository
+)
+
+// SetUserRoleRepository sets the repository the roles of the authenticated users are read from
+func SetUserRoleRepository(repo repository.UserRoleRepository) {
+	userRoleRepository = repo
+}
+
+// Principal is the user authenticated by the jwt token of the request
+type Principal struct {
+	UserId    uint
+	SessionId string
+	Roles     []string
+}
+
+// Can reports whether one of the roles of the principal grants the permission
+func (p *Principal) Can(permission rbac.Permission) bool {
+	return rbac.HasPermission(p.Roles, permission)
+}
+
+// UserIdString returns the userid of the principal in the string format
+func (p *Principal) UserIdString() string {
+	return strconv.FormatUint(uint64(p.UserId), 10)
+}
+
+// GetRequestToken returns the jwt token of the request, read from the Authorization: Bearer header or else from the legacy token header
+func GetRequestToken(ctx *fiber.Ctx) string {
+	if authorization := ctx.Get(fiber.HeaderAuthorization); authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+
+		return strings.TrimSpace(token)
+	}
+
+	return ctx.Get(LegacyTokenHeader)
+}
+
+// GetPrincipal returns the principal set on the request by the AuthMiddleware, nil when the request has not been authenticated
+func GetPrincipal(ctx *fiber.Ctx) *Principal {
+	principal, _ := ctx.Locals(principalLocalsKey).(*Principal)
+	return principal
+}
+
+// Public lets the anonymous requests through, marking the route as deliberately open
+func Public() fiber.Handler {
+	return policyHandler(false, "")
+}
+
+// AuthMiddleware authenticates the request by its jwt token and sets its principal on the ctx locals,
+// refusing the request with 401 when the token is missing, invalid, expired or of a revoked session,
+// and with 500 when its session or roles could not be read
+func AuthMiddleware() fiber.Handler {
+	return policyHandler(true, "")
+}
+
+// RequirePermission authenticates the request like the AuthMiddleware, then refuses it with 403 unless a role of the principal grants the permission
+func RequirePermission(permission rbac.Permission) fiber.Handler {
+	return policyHandler(true, permission)
+}
+
+// policyHandler returns the handler enforcing the policy of a route. Every policy is built by this function
+// so RoutesWithoutPolicy recognizes them
+func policyHandler(authenticated bool, permission rbac.Permission) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !authenticated {
+			return ctx.Next()
+		}
+
+		principal := GetPrincipal(ctx)
+		if principal == nil {
+			var err error
+			principal, err = authenticate(ctx.Context(), GetRequestToken(ctx))
+			if errors.Is(err, ErrAuthUnavailable) {
+				return authUnavailableResponse(ctx, err)
+			}
+			if err != nil {
+				return unauthorizedResponse(ctx, err)
+			}
+			ctx.Locals(principalLocalsKey, principal)
+		}
+
+		if permission != "" && !principal.Can(permission) {
+			return forbiddenResponse(ctx)
+		}
+		return ctx.Next()
+	}
+}
+
+// authenticate returns the principal of the jwt token along with its current roles. The errors of the repositories
+// the token is checked against wrap ErrAuthUnavailable
+func authenticate(ctx context.Context, token string) (res *Principal, err error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	claims, err := GetJWTClaims(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := strconv.ParseUint(claims.UserId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &Principal{
+		UserId:    uint(userId),
+		SessionId: claims.SessionId,
+	}
+	if userRoleRepository != nil {
+		res.Roles, err = userRoleRepository.GetRolesByUserId(ctx, res.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAuthUnavailable, err.Error())
+		}
+	}
+
+	return res, nil
+}
+
+// policyHandlerPointer is the code pointer shared by the handlers returned by policyHandler
+var policyHandlerPointer = reflect.ValueOf(policyHandler(false, "")).Pointer()
+
+// RoutesWithoutPolicy returns the routes under the prefix whose first handler is not a policy, i.e. neither Public,
+// AuthMiddleware nor RequirePermission, so a route cannot be added without deciding who may call it
+func RoutesWithoutPolicy(app *fiber.App, prefix string) (res []string) {
+	for _, route := range app.GetRoutes(true) {
+		if !strings.HasPrefix(route.Path, prefix) || route.Method == fiber.MethodHead {
+			continue
+		}
+
+		if len(route.Handlers) == 0 || reflect.ValueOf(route.Handlers[0]).Pointer() != policyHandlerPointer {
+			res = append(res, fmt.Sprintf("%s %s", route.Method, route.Path))
+		}
+	}
+
+	return res
+}
+
+// unauthorizedResponse refuses the request which could not be authenticated
+func unauthorizedResponse(ctx *fiber.Ctx, err error) error {
+	helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusUnauthorized,
+		Errors:     []string{err.Error()},
+	})
+}
+
+// authUnavailableResponse refuses the request whose token could not be checked, without telling the client it is invalid
+func authUnavailableResponse(ctx *fiber.Ctx, err error) error {
+	helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusInternalServerError,
+		Errors:     []string{ErrAuthUnavailable.Error()},
+	})
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tugas_akhir_example/internal/pkg/repository"
+	"tugas_akhir_example/internal/pkg/testfixture"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeUserRoleRepository answers GetRolesByUserId only
+type fakeUserRoleRepository struct {
+	repository.UserRoleRepository
+	err error
+}
+
+func (f *fakeUserRoleRepository) GetRolesByUserId(ctx context.Context, idUser uint) (res []string, err error) {
+	return []string{"user"}, f.err
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	SetJWTSecretKey("secret")
+	defer SetJWTSecretKey("")
+	defer SetJWTSessionRepository(nil)
+	defer SetUserRoleRepository(nil)
+
+	token, err := GenerateNewJWT(&Claims{
+		UserId:           "1",
+		SessionId:        "family",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("GenerateNewJWT error = %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/", AuthMiddleware(), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	expiredToken, err := GenerateNewJWT(&Claims{
+		UserId:           "1",
+		SessionId:        "family",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("GenerateNewJWT error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		sessions      *testfixture.UserSessions
+		roles         *fakeUserRoleRepository
+		want          int
+	}{
+		{"active session", "Bearer " + token, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusOK},
+		{"lowercase scheme", "bearer " + token, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusOK},
+		{"missing header", "", testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"other scheme", "Basic " + token, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"scheme without token", "Bearer", testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"blank token", "Bearer   ", testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"token without scheme", token, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"invalid token", "Bearer " + token + "x", testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"expired token", "Bearer " + expiredToken, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"revoked session", "Bearer " + token, &testfixture.UserSessions{}, &fakeUserRoleRepository{}, fiber.StatusUnauthorized},
+		{"session repository error", "Bearer " + token, &testfixture.UserSessions{Err: errors.New("connection refused")}, &fakeUserRoleRepository{}, fiber.StatusInternalServerError},
+		{"role repository error", "Bearer " + token, testfixture.ActiveSession(1, "family"), &fakeUserRoleRepository{err: errors.New("connection refused")}, fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetJWTSessionRepository(tt.sessions)
+			SetUserRoleRepository(tt.roles)
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test error = %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+
+			// the session is checked within the ctx of the request rather than a detached one
+			if tt.sessions.Ctx == context.Background() {
+				t.Errorf("session checked with context.Background()")
+			}
+		})
+	}
+}
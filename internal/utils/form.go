@@ -1,10 +1,18 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
+	"path/filepath"
+	"strings"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/storage"
+	"tugas_akhir_example/internal/pkg/imaging"
 )
 
 // GetMultiFormFirstValue returns the first string associated with the given key from the form data
@@ -25,25 +33,104 @@ func GetMultiFormFirstFile(form *multipart.Form, key string) *multipart.FileHead
 	return val[0]
 }
 
-// SaveMultiFormImage saves the image contained in the fileheader
-func SaveMultiFormImage(fileHeader *multipart.FileHeader, dstPath string, maxSize int64, allowedFormats map[string]struct{}) error {
+// SaveMultiFormImage saves the image contained in the fileheader re-encoded in its own size, stripped of its metadata, to the storage
+// under the key and returns its url. The extension of the key is replaced by the one of the re-encoded image
+func SaveMultiFormImage(ctx context.Context, blobStorage storage.BlobStorage, fileHeader *multipart.FileHeader, key string, maxSize int64, allowedFormats map[string]struct{}) (url string, err error) {
+	buff, err := readMultiFormImage(fileHeader, maxSize, allowedFormats)
+	if err != nil {
+		return "", err
+	}
+
+	buff, ext, err := imaging.Sanitize(buff)
+	if err != nil {
+		return "", err
+	}
+
+	key = strings.TrimSuffix(key, filepath.Ext(key)) + "." + ext
+	return blobStorage.Put(ctx, key, buff, http.DetectContentType(buff))
+}
+
+// SaveMultiFormImageSizes saves the image contained in the fileheader re-encoded to each of the standard sizes, stripped of its metadata,
+// to the storage. The keys are named after the key prefix and the size, e.g. images/produk/1700000000_thumb.jpg
+func SaveMultiFormImageSizes(ctx context.Context, blobStorage storage.BlobStorage, fileHeader *multipart.FileHeader, keyPrefix string, maxSize int64, allowedFormats map[string]struct{}) (res map[string]*daos.FotoSize, err error) {
+	buff, err := readMultiFormImage(fileHeader, maxSize, allowedFormats)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := imaging.Process(buff)
+	if err != nil {
+		return nil, err
+	}
+
+	savedKeys := []string{}
+	save := func(key string, data []byte) (string, error) {
+		url, err := blobStorage.Put(ctx, key, data, http.DetectContentType(data))
+		if err != nil {
+			return "", err
+		}
+		savedKeys = append(savedKeys, key)
+		return url, nil
+	}
+
+	res = map[string]*daos.FotoSize{}
+	for _, v := range variants {
+		size := &daos.FotoSize{
+			Width:  v.Width,
+			Height: v.Height,
+		}
+
+		size.Url, err = save(fmt.Sprintf("%s_%s.%s", keyPrefix, v.Size, v.Ext), v.Data)
+		if err != nil {
+			break
+		}
+
+		res[v.Size] = size
+	}
+
+	if err != nil {
+		for _, v := range savedKeys {
+			blobStorage.Delete(ctx, v)
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteStoredImages deletes the images served on the urls from the storage, skipping the urls not served by it
+func DeleteStoredImages(ctx context.Context, blobStorage storage.BlobStorage, urls ...string) {
+	for _, url := range urls {
+		key, ok := blobStorage.Key(url)
+		if !ok {
+			continue
+		}
+
+		if err := blobStorage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		}
+	}
+}
+
+// readMultiFormImage returns the content of the image contained in the fileheader, checking its size and format
+func readMultiFormImage(fileHeader *multipart.FileHeader, maxSize int64, allowedFormats map[string]struct{}) ([]byte, error) {
 	if fileHeader.Size == 0 || (maxSize > 0 && fileHeader.Size > maxSize) {
-		return fmt.Errorf("image size violation. max size %d", maxSize)
+		return nil, fmt.Errorf("image size violation. max size %d", maxSize)
 	}
 
 	file, err := fileHeader.Open()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer file.Close()
 
 	buff := make([]byte, fileHeader.Size)
-	if _, err = file.Read(buff); err != nil {
-		return err
+	if _, err = io.ReadFull(file, buff); err != nil {
+		return nil, err
 	}
 
-	filetype := http.DetectContentType(buff[:512])
+	filetype := http.DetectContentType(buff)
 	if _, ok := allowedFormats[filetype]; !ok {
 		allowedFormatString := ""
 		i := 0
@@ -57,19 +144,8 @@ func SaveMultiFormImage(fileHeader *multipart.FileHeader, dstPath string, maxSiz
 			allowedFormatString += k
 			i++
 		}
-		return fmt.Errorf("only %s formats are allowed", allowedFormatString)
-	}
-
-	savedImageFile, err := os.Create(dstPath)
-	if err != nil {
-		return err
-	}
-	defer savedImageFile.Close()
-
-	_, err = savedImageFile.Write(buff)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("only %s formats are allowed", allowedFormatString)
 	}
 
-	return nil
+	return buff, nil
 }
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/pkg/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyDefaultTtl     = 24 * time.Hour
+)
+
+// IdempotencyMiddleware replays the stored response of a successful request retried with the same Idempotency-Key header instead of running the handler again
+func IdempotencyMiddleware(idempotencyRepository repository.IdempotencyRepository, ttl time.Duration) fiber.Handler {
+	if ttl <= 0 {
+		ttl = idempotencyDefaultTtl
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "Error : idempotency key too long")
+			return helper.ResponseWithJSON(&helper.JSONRespArgs{
+				Ctx:        ctx,
+				StatusCode: fiber.StatusBadRequest,
+				Errors:     []string{fmt.Sprintf("%s must not exceed %d characters", IdempotencyKeyHeader, idempotencyKeyMaxLength)},
+			})
+		}
+
+		// the responses are cached per user, the middleware must be used after the AuthMiddleware
+		principal := GetPrincipal(ctx)
+		if principal == nil {
+			return ctx.Next()
+		}
+
+		requestHash, err := idempotencyRequestHash(ctx)
+		if err != nil {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return helper.ResponseWithJSON(&helper.JSONRespArgs{
+				Ctx:        ctx,
+				StatusCode: fiber.StatusBadRequest,
+				Errors:     []string{err.Error()},
+			})
+		}
+
+		scope := sha256.Sum256([]byte(strings.Join([]string{principal.UserIdString(), ctx.Method(), ctx.Path(), key}, "\n")))
+		resRepo, err := idempotencyRepository.GetIdempotencyKeyByScope(ctx.Context(), hex.EncodeToString(scope[:]))
+		if err == nil && time.Now().After(resRepo.ExpiredAt) {
+			err = idempotencyRepository.DeleteIdempotencyKey(ctx.Context(), resRepo)
+			if err == nil {
+				err = gorm.ErrRecordNotFound
+			}
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return helper.ResponseWithJSON(&helper.JSONRespArgs{
+				Ctx:        ctx,
+				StatusCode: fiber.StatusInternalServerError,
+				Errors:     []string{err.Error()},
+			})
+		}
+
+		if err == nil {
+			return replayIdempotencyKey(ctx, resRepo, requestHash)
+		}
+
+		record := &daos.IdempotencyKey{
+			Scope:       hex.EncodeToString(scope[:]),
+			RequestHash: requestHash,
+			ExpiredAt:   time.Now().Add(ttl),
+		}
+		if _, err := idempotencyRepository.CreateIdempotencyKey(ctx.Context(), record); err != nil {
+			// another request holding the same key has just been started
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return helper.ResponseWithJSON(&helper.JSONRespArgs{
+				Ctx:        ctx,
+				StatusCode: fiber.StatusConflict,
+				Errors:     []string{"a request with the same Idempotency-Key is still being processed"},
+			})
+		}
+
+		if err := ctx.Next(); err != nil {
+			releaseIdempotencyKey(ctx, idempotencyRepository, record)
+			return err
+		}
+
+		// only the successes are replayed, the key of a failed request is released so the client can retry it,
+		// as the failures of this app, a 400 included, may be caused by a state that no longer holds on the retry
+		statusCode := ctx.Response().StatusCode()
+		if statusCode < fiber.StatusOK || statusCode >= fiber.StatusMultipleChoices {
+			releaseIdempotencyKey(ctx, idempotencyRepository, record)
+			return nil
+		}
+
+		err = idempotencyRepository.UpdateIdempotencyKey(ctx.Context(), record, &daos.IdempotencyKey{
+			Completed:    true,
+			StatusCode:   statusCode,
+			ContentType:  string(ctx.Response().Header.ContentType()),
+			ResponseBody: string(ctx.Response().Body()),
+		})
+		if err != nil {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+		}
+
+		return nil
+	}
+}
+
+// replayIdempotencyKey writes the stored response of the idempotency key, rejecting a payload different from the original one
+func replayIdempotencyKey(ctx *fiber.Ctx, data *daos.IdempotencyKey, requestHash string) error {
+	if data.RequestHash != requestHash {
+		helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "Error : idempotency key reused with a different payload")
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusUnprocessableEntity,
+			Errors:     []string{"Idempotency-Key has already been used with a different payload"},
+		})
+	}
+
+	if !data.Completed {
+		helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "Error : idempotency key still in progress")
+		return helper.ResponseWithJSON(&helper.JSONRespArgs{
+			Ctx:        ctx,
+			StatusCode: fiber.StatusConflict,
+			Errors:     []string{"a request with the same Idempotency-Key is still being processed"},
+		})
+	}
+
+	ctx.Set(IdempotencyReplayedHeader, "true")
+	if data.ContentType != "" {
+		ctx.Set(fiber.HeaderContentType, data.ContentType)
+	}
+
+	return ctx.Status(data.StatusCode).SendString(data.ResponseBody)
+}
+
+// releaseIdempotencyKey deletes the idempotency key of a request that did not complete so it can be retried
+func releaseIdempotencyKey(ctx *fiber.Ctx, idempotencyRepository repository.IdempotencyRepository, data *daos.IdempotencyKey) {
+	if err := idempotencyRepository.DeleteIdempotencyKey(ctx.Context(), data); err != nil {
+		helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+	}
+}
+
+// idempotencyRequestHash returns the hash of the request payload, hashing multipart forms field by field since their boundary changes on every retry
+func idempotencyRequestHash(ctx *fiber.Ctx) (string, error) {
+	hash := sha256.New()
+	if !strings.HasPrefix(string(ctx.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		hash.Write(ctx.Body())
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+
+	fields := []string{}
+	for k := range form.Value {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for _, k := range fields {
+		fmt.Fprintf(hash, "%s=%q\n", k, form.Value[k])
+	}
+
+	fields = []string{}
+	for k := range form.File {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for _, k := range fields {
+		for _, v := range form.File[k] {
+			file, err := v.Open()
+			if err != nil {
+				return "", err
+			}
+
+			fmt.Fprintf(hash, "%s=%s:", k, v.Filename)
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tugas_akhir_example/internal/daos"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeIdempotencyRepository holds the idempotency keys by scope
+type fakeIdempotencyRepository struct {
+	keys map[string]*daos.IdempotencyKey
+}
+
+func (f *fakeIdempotencyRepository) GetIdempotencyKeyByScope(ctx context.Context, scope string) (res *daos.IdempotencyKey, err error) {
+	if res, ok := f.keys[scope]; ok {
+		return res, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (res uint, err error) {
+	if _, ok := f.keys[data.Scope]; ok {
+		return 0, errors.New("duplicate entry")
+	}
+	f.keys[data.Scope] = data
+	return 1, nil
+}
+
+func (f *fakeIdempotencyRepository) UpdateIdempotencyKey(ctx context.Context, prevData *daos.IdempotencyKey, data *daos.IdempotencyKey) (err error) {
+	prevData.Completed = data.Completed
+	prevData.StatusCode = data.StatusCode
+	prevData.ContentType = data.ContentType
+	prevData.ResponseBody = data.ResponseBody
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, data *daos.IdempotencyKey) (err error) {
+	delete(f.keys, data.Scope)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantReplay bool
+	}{
+		{"created", fiber.StatusCreated, true},
+		{"ok", fiber.StatusOK, true},
+		{"bad request", fiber.StatusBadRequest, false},
+		{"conflict", fiber.StatusConflict, false},
+		{"internal server error", fiber.StatusInternalServerError, false},
+		{"bad gateway", fiber.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdempotencyRepository{keys: map[string]*daos.IdempotencyKey{}}
+			calls := 0
+
+			app := fiber.New()
+			app.Post("/", func(ctx *fiber.Ctx) error {
+				ctx.Locals(principalLocalsKey, &Principal{UserId: 1})
+				return ctx.Next()
+			}, IdempotencyMiddleware(repo, time.Hour), func(ctx *fiber.Ctx) error {
+				calls++
+				return ctx.Status(tt.status).SendString("response")
+			})
+
+			type response struct {
+				status         int
+				replayed, body string
+			}
+			send := func() response {
+				req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(`{"kuantitas":1}`))
+				req.Header.Set(IdempotencyKeyHeader, "key")
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatalf("app.Test error = %v", err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				return response{resp.StatusCode, resp.Header.Get(IdempotencyReplayedHeader), string(body)}
+			}
+
+			first := send()
+			retry := send()
+
+			// the success is replayed without running the handler again, while a failed request runs again on its retry
+			wantCalls := 2
+			if tt.wantReplay {
+				wantCalls = 1
+			}
+			if calls != wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, wantCalls)
+			}
+			if retry.status != first.status || retry.body != first.body {
+				t.Errorf("retry = %d %s, want %d %s", retry.status, retry.body, first.status, first.body)
+			}
+			if (retry.replayed == "true") != tt.wantReplay {
+				t.Errorf("retry replayed = %q, want %v", retry.replayed, tt.wantReplay)
+			}
+			if len(repo.keys) != 0 && !tt.wantReplay {
+				t.Errorf("key of the failed request kept")
+			}
+		})
+	}
+}
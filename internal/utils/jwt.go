@@ -1,8 +1,15 @@
 package utils
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"strconv"
+	"fmt"
+
+	"tugas_akhir_example/internal/pkg/repository"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -10,17 +17,31 @@ import (
 // @TODO : make function create jwt token and validate
 
 type Claims struct {
-	UserId string `json:"user_id"`
+	UserId    string `json:"user_id"`
+	SessionId string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-var jwtSecretKey = ""
+var (
+	jwtSecretKey          = ""
+	userSessionRepository repository.UserSessionRepository
+
+	ErrSessionRevoked = errors.New("sesi telah berakhir, silakan login kembali")
+	// ErrAuthUnavailable wraps the errors of the repositories the token is checked against, the token being neither valid nor invalid
+	ErrAuthUnavailable = errors.New("autentikasi sedang tidak dapat diproses, silakan coba lagi")
+)
 
 // SetJWTSecretKey sets the jwt secret key
 func SetJWTSecretKey(key string) {
 	jwtSecretKey = key
 }
 
+// SetJWTSessionRepository sets the repository the session of the jwt tokens is checked against,
+// so the tokens of a revoked session are refused before they expire
+func SetJWTSessionRepository(repo repository.UserSessionRepository) {
+	userSessionRepository = repo
+}
+
 // GenerateNewJWT generates a JWT token with the given claims
 func GenerateNewJWT(claims *Claims) (signedToken string, err error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, *claims)
@@ -31,32 +52,8 @@ func GenerateNewJWT(claims *Claims) (signedToken string, err error) {
 	return signedToken, nil
 }
 
-// GetJWTUserId returns the userid contained in the JWT token
-func GetJWTUserId(tokenString string) (res uint, err error) {
-	claims, err := GetJWTClaims(tokenString)
-	if err != nil {
-		return res, err
-	}
-
-	userId, err := strconv.Atoi(claims.UserId)
-	if err != nil {
-		return res, err
-	}
-	return uint(userId), nil
-}
-
-// GetJWTUserIdString returns the userid contained in the JWT token in the string format
-func GetJWTUserIdString(tokenString string) (res string, err error) {
-	claims, err := GetJWTClaims(tokenString)
-	if err != nil {
-		return res, err
-	}
-
-	return claims.UserId, nil
-}
-
-// GetJWTClaims returns the claims contained in the jwt token
-func GetJWTClaims(tokenString string) (claims *Claims, err error) {
+// GetJWTClaims returns the claims contained in the jwt token, checking its session is still active within the ctx of the request
+func GetJWTClaims(ctx context.Context, tokenString string) (claims *Claims, err error) {
 	claims = &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(jwtToken *jwt.Token) (interface{}, error) {
 		return []byte(jwtSecretKey), nil
@@ -69,5 +66,47 @@ func GetJWTClaims(tokenString string) (claims *Claims, err error) {
 	if !token.Valid {
 		return nil, errors.New("you're Unauthorized")
 	}
+
+	if userSessionRepository != nil {
+		if claims.SessionId == "" {
+			return nil, ErrSessionRevoked
+		}
+
+		active, err := userSessionRepository.IsUserSessionFamilyActive(ctx, claims.SessionId)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAuthUnavailable, err.Error())
+		}
+
+		if !active {
+			return nil, ErrSessionRevoked
+		}
+	}
 	return claims, nil
 }
+
+// GenerateRefreshToken generates a random refresh token along with the hash it is stored as
+func GenerateRefreshToken() (token string, hash string, err error) {
+	buff := make([]byte, 32)
+	if _, err := rand.Read(buff); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buff)
+	return token, HashRefreshToken(token), nil
+}
+
+// GenerateSessionId generates the random id of a new session
+func GenerateSessionId() (res string, err error) {
+	buff := make([]byte, 16)
+	if _, err := rand.Read(buff); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buff), nil
+}
+
+// HashRefreshToken returns the hash the refresh token is stored as
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
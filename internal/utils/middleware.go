@@ -1,123 +1,64 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
 
 	"tugas_akhir_example/internal/helper"
 	"tugas_akhir_example/internal/pkg/repository"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-// @TODO : make middleware like Auth
+var ErrForbidden = errors.New("you are unauthorized")
 
-// TokoAuthMiddleware auths the user by comparing the userid contained in the jwt token and the userid of the toko data
+// TokoAuthMiddleware auths the user by comparing the userid of the principal and the userid of the toko data
 func TokoAuthMiddleware(tokoRepository repository.TokoRepository) fiber.Handler {
-	return func(ctx *fiber.Ctx) error {
-		claims, err := GetJWTClaims(ctx.Get("token"))
-		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
-		}
-
-		tokoId := ctx.Params("id_toko")
-		if tokoId == "" {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "id_toko params required ")
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"Bad request"},
-			})
-		}
-
-		resRepo, err := tokoRepository.GetTokoById(ctx.Context(), tokoId)
+	return ownerAuthMiddleware("id_toko", func(ctx context.Context, id string) (uint, error) {
+		resRepo, err := tokoRepository.GetTokoById(ctx, id)
 		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
+			return 0, err
 		}
-
-		if strconv.Itoa(int(resRepo.IdUser)) != claims.UserId {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", "unauthorized"))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"You are unauthorized"},
-			})
-		}
-		return ctx.Next()
-	}
+		return resRepo.IdUser, nil
+	})
 }
 
-// ProdukAuthMiddleware auths the user by comparing the userid contained in the jwt token and the userid of the toko data having the produk
+// ProdukAuthMiddleware auths the user by comparing the userid of the principal and the userid of the toko data having the produk
 func ProdukAuthMiddleware(produkRepository repository.ProdukRepository) fiber.Handler {
-	return func(ctx *fiber.Ctx) error {
-		claims, err := GetJWTClaims(ctx.Get("token"))
+	return ownerAuthMiddleware("id", func(ctx context.Context, id string) (uint, error) {
+		resRepo, err := produkRepository.GetProdukById(ctx, id)
 		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
-		}
-
-		produkId := ctx.Params("id")
-		if produkId == "" {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "id params required ")
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"Bad request"},
-			})
+			return 0, err
 		}
+		return resRepo.Toko.IdUser, nil
+	})
+}
 
-		resRepo, err := produkRepository.GetProdukById(ctx.Context(), produkId)
+// AlamatAuthMiddleware auths the user by comparing the userid of the principal and the userid of the alamat data
+func AlamatAuthMiddleware(userRepository repository.UserRepository) fiber.Handler {
+	return ownerAuthMiddleware("id", func(ctx context.Context, id string) (uint, error) {
+		resRepo, err := userRepository.GetAlamatById(ctx, id)
 		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
+			return 0, err
 		}
-
-		if strconv.Itoa(int(resRepo.Toko.IdUser)) != claims.UserId {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", "unauthorized"))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"You are unauthorized"},
-			})
-		}
-		return ctx.Next()
-	}
+		return resRepo.IdUser, nil
+	})
 }
 
-// AlamatAuthMiddleware auths the user by comparing the userid contained in the jwt token and the userid of the alamat data
-func AlamatAuthMiddleware(userRepository repository.UserRepository) fiber.Handler {
+// ownerAuthMiddleware auths the principal by comparing its userid and the userid owning the data having the id of the param,
+// returned by getOwner. It must be used after the AuthMiddleware
+func ownerAuthMiddleware(param string, getOwner func(ctx context.Context, id string) (uint, error)) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		claims, err := GetJWTClaims(ctx.Get("token"))
-		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
+		principal := GetPrincipal(ctx)
+		if principal == nil {
+			return unauthorizedResponse(ctx, ErrTokenMissing)
 		}
 
-		alamatId := ctx.Params("id")
-		if alamatId == "" {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, "id params required ")
+		id := ctx.Params(param)
+		if id == "" {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("%s params required ", param))
 			return helper.ResponseWithJSON(&helper.JSONRespArgs{
 				Ctx:        ctx,
 				StatusCode: fiber.StatusBadRequest,
@@ -125,59 +66,34 @@ func AlamatAuthMiddleware(userRepository repository.UserRepository) fiber.Handle
 			})
 		}
 
-		resRepo, err := userRepository.GetAlamatById(ctx.Context(), alamatId)
+		idUser, err := getOwner(ctx.Context(), id)
 		if err != nil {
+			code := fiber.StatusBadRequest
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				code = fiber.StatusNotFound
+			}
+
 			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
 			return helper.ResponseWithJSON(&helper.JSONRespArgs{
 				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
+				StatusCode: code,
 				Errors:     []string{err.Error()},
 			})
 		}
 
-		if strconv.Itoa(int(resRepo.IdUser)) != claims.UserId {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", "unauthorized"))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"You are unauthorized"},
-			})
+		if idUser != principal.UserId {
+			return forbiddenResponse(ctx)
 		}
 		return ctx.Next()
 	}
 }
 
-// CategoryAuthMiddleware auths the user by checking whether the user specified in the jwt token is an admin
-func CategoryAuthMiddleware(categoryRepository repository.CategoryRepository) fiber.Handler {
-	return func(ctx *fiber.Ctx) error {
-		claims, err := GetJWTClaims(ctx.Get("token"))
-		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
-		}
-
-		resRepo, err := categoryRepository.GetUserById(ctx.Context(), claims.UserId)
-		if err != nil {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{err.Error()},
-			})
-		}
-
-		if !resRepo.IsAdmin {
-			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", "unauthorized"))
-			return helper.ResponseWithJSON(&helper.JSONRespArgs{
-				Ctx:        ctx,
-				StatusCode: fiber.StatusBadRequest,
-				Errors:     []string{"You are unauthorized"},
-			})
-		}
-		return ctx.Next()
-	}
+// forbiddenResponse refuses the request of the principal not allowed to access the resource
+func forbiddenResponse(ctx *fiber.Ctx) error {
+	helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", ErrForbidden.Error()))
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusForbidden,
+		Errors:     []string{ErrForbidden.Error()},
+	})
 }
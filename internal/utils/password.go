@@ -1,9 +1,16 @@
 package utils
 
 import (
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
 // @TODO : make function hash password
 
 // UnsafeHashPassword hashes the password without checking for an error
@@ -25,3 +32,13 @@ func HashPassword(pw string) (string, error) {
 func ValidatePassword(hash, plain string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
 }
+
+// ValidateDummyPassword checks the password against a throwaway hash of the same cost, so refusing the login of an unknown notelp
+// takes as long as refusing a wrong password and the response time does not reveal which notelps are registered
+func ValidateDummyPassword(plain string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), 12)
+	})
+
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(plain))
+}
@@ -14,5 +14,6 @@ func GetFunctionPath() string {
 	return ""
 }
 
-const ProdukImagesPath = "./static/images/produk/"
-const TokoImagesPath = "./static/images/toko/"
+const ProdukImagesKey = "images/produk/"
+const TokoImagesKey = "images/toko/"
+const ReviewImagesKey = "images/review/"
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"tugas_akhir_example/internal/daos"
+	"tugas_akhir_example/internal/pkg/money"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfFontFamily  = "Helvetica"
+	pdfLineHeight  = 6.0
+	pdfPageWidth   = 190.0
+	pdfTableHeight = 7.0
+)
+
+// FormatRupiah formats the amount into the rupiah notation, e.g. Rp75.000
+func FormatRupiah(amount int) string {
+	return money.Rupiah(amount).String()
+}
+
+// logProdukLabel returns the nama of the logproduk followed by the opsi of the ordered variant, e.g. Kaos (ukuran: L, warna: Merah)
+func logProdukLabel(data *daos.LogProduk) string {
+	if len(data.Opsi) == 0 {
+		return data.NamaProduk
+	}
+
+	axes := []string{}
+	for axis := range data.Opsi {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+
+	opsi := []string{}
+	for _, axis := range axes {
+		opsi = append(opsi, fmt.Sprintf("%s: %s", axis, data.Opsi[axis]))
+	}
+
+	return fmt.Sprintf("%s (%s)", data.NamaProduk, strings.Join(opsi, ", "))
+}
+
+// TrxToInvoicePDF renders the invoice of the trx listing the detailtrx and pengiriman data as a pdf document
+func TrxToInvoicePDF(data *daos.Trx, detailTrxs []*daos.DetailTrx, pengirimans []*daos.TrxPengiriman) (res []byte, err error) {
+	pdf, tr := newPDF()
+
+	pdf.SetFont(pdfFontFamily, "B", 18)
+	pdf.CellFormat(pdfPageWidth/2, 10, "INVOICE", "", 0, "L", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "B", 11)
+	pdf.CellFormat(pdfPageWidth/2, 10, tr(data.KodeInvoice), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(pdfFontFamily, "", 10)
+	writePDFField(pdf, tr, "Tanggal", DateToString(data.CreatedAt))
+	writePDFField(pdf, tr, "Status", data.Status)
+	writePDFField(pdf, tr, "Metode Bayar", data.MethodBayar)
+	if data.Payment != nil {
+		writePDFField(pdf, tr, "Referensi Bayar", fmt.Sprintf("%s (%s)", data.Payment.Reference, data.Payment.Status))
+	}
+	if data.User != nil {
+		writePDFField(pdf, tr, "Pembeli", data.User.Nama)
+	}
+	pdf.Ln(2)
+	writePDFAlamat(pdf, tr, data.Alamat)
+
+	widths := []float64{10, 70, 40, 25, 15, 30}
+	aligns := []string{"C", "L", "L", "R", "R", "R"}
+	writePDFTableRow(pdf, tr, widths, aligns, []string{"No", "Produk", "Toko", "Harga", "Qty", "Subtotal"}, true)
+
+	total := 0
+	for i, v := range detailTrxs {
+		namaToko := ""
+		if v.LogProduk.Toko != nil {
+			namaToko = v.LogProduk.Toko.NamaToko
+		}
+
+		total += v.HargaTotal
+		writePDFTableRow(pdf, tr, widths, aligns, []string{
+			strconv.Itoa(i + 1),
+			logProdukLabel(v.LogProduk),
+			namaToko,
+			v.LogProduk.HargaKonsumen.String(),
+			strconv.Itoa(v.Kuantitas),
+			FormatRupiah(v.HargaTotal),
+		}, false)
+	}
+
+	for _, v := range pengirimans {
+		namaToko := ""
+		if v.Toko != nil {
+			namaToko = v.Toko.NamaToko
+		}
+
+		total += v.Ongkir
+		pdf.SetFont(pdfFontFamily, "", 10)
+		pdf.CellFormat(pdfPageWidth-widths[len(widths)-1], pdfTableHeight, tr(fmt.Sprintf("Ongkir %s %s %s (%d gr)", namaToko, v.Kurir, v.Layanan, v.Berat)), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[len(widths)-1], pdfTableHeight, FormatRupiah(v.Ongkir), "1", 1, "R", false, 0, "")
+	}
+
+	diskon := 0
+	for _, v := range detailTrxs {
+		diskon += v.Diskon
+	}
+
+	if diskon > 0 {
+		label := "Diskon"
+		if data.Voucher != nil {
+			label = fmt.Sprintf("Diskon voucher %s", data.Voucher.Kode)
+		}
+
+		total -= diskon
+		pdf.SetFont(pdfFontFamily, "", 10)
+		pdf.CellFormat(pdfPageWidth-widths[len(widths)-1], pdfTableHeight, tr(label), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[len(widths)-1], pdfTableHeight, FormatRupiah(-diskon), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont(pdfFontFamily, "B", 10)
+	pdf.CellFormat(pdfPageWidth-widths[len(widths)-1], pdfTableHeight, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[len(widths)-1], pdfTableHeight, FormatRupiah(total), "1", 1, "R", false, 0, "")
+
+	return outputPDF(pdf)
+}
+
+// TrxToPackingSlipPDF renders the packing slip of the trx listing the detailtrx data of the toko as a pdf document
+func TrxToPackingSlipPDF(data *daos.Trx, toko *daos.Toko, detailTrxs []*daos.DetailTrx) (res []byte, err error) {
+	pdf, tr := newPDF()
+
+	pdf.SetFont(pdfFontFamily, "B", 18)
+	pdf.CellFormat(pdfPageWidth/2, 10, "PACKING SLIP", "", 0, "L", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "B", 11)
+	pdf.CellFormat(pdfPageWidth/2, 10, tr(data.KodeInvoice), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(pdfFontFamily, "", 10)
+	writePDFField(pdf, tr, "Tanggal", DateToString(data.CreatedAt))
+	writePDFField(pdf, tr, "Toko", toko.NamaToko)
+	writePDFField(pdf, tr, "Metode Bayar", data.MethodBayar)
+	pdf.Ln(2)
+	writePDFAlamat(pdf, tr, data.Alamat)
+
+	widths := []float64{10, 150, 30}
+	aligns := []string{"C", "L", "R"}
+	writePDFTableRow(pdf, tr, widths, aligns, []string{"No", "Produk", "Qty"}, true)
+
+	totalKuantitas := 0
+	for i, v := range detailTrxs {
+		totalKuantitas += v.Kuantitas
+		writePDFTableRow(pdf, tr, widths, aligns, []string{
+			strconv.Itoa(i + 1),
+			logProdukLabel(v.LogProduk),
+			strconv.Itoa(v.Kuantitas),
+		}, false)
+	}
+
+	pdf.SetFont(pdfFontFamily, "B", 10)
+	pdf.CellFormat(pdfPageWidth-widths[len(widths)-1], pdfTableHeight, "Total Item", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[len(widths)-1], pdfTableHeight, strconv.Itoa(totalKuantitas), "1", 1, "R", false, 0, "")
+
+	return outputPDF(pdf)
+}
+
+// newPDF returns an a4 pdf document with one page added and the translator of utf-8 text into its core font encoding
+func newPDF() (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.AddPage()
+
+	return pdf, pdf.UnicodeTranslatorFromDescriptor("")
+}
+
+// outputPDF returns the bytes of the pdf document
+func outputPDF(pdf *fpdf.Fpdf) (res []byte, err error) {
+	buff := &bytes.Buffer{}
+	if err := pdf.Output(buff); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// writePDFField writes a label and value line
+func writePDFField(pdf *fpdf.Fpdf, tr func(string) string, label, value string) {
+	pdf.CellFormat(35, pdfLineHeight, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(pdfPageWidth-35, pdfLineHeight, tr(": "+value), "", 1, "L", false, 0, "")
+}
+
+// writePDFAlamat writes the shipping address block
+func writePDFAlamat(pdf *fpdf.Fpdf, tr func(string) string, alamat *daos.Alamat) {
+	if alamat == nil {
+		return
+	}
+
+	pdf.SetFont(pdfFontFamily, "B", 10)
+	pdf.CellFormat(pdfPageWidth, pdfLineHeight, "Dikirim ke", "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	pdf.CellFormat(pdfPageWidth, pdfLineHeight, tr(fmt.Sprintf("%s (%s)", alamat.NamaPenerima, alamat.Notelp)), "", 1, "L", false, 0, "")
+	pdf.MultiCell(pdfPageWidth, pdfLineHeight, tr(alamat.DetailAlamat), "", "L", false)
+	pdf.Ln(4)
+}
+
+// writePDFTableRow writes one row of a table, truncating cells that do not fit their column
+func writePDFTableRow(pdf *fpdf.Fpdf, tr func(string) string, widths []float64, aligns []string, cells []string, header bool) {
+	style := ""
+	if header {
+		style = "B"
+		pdf.SetFillColor(230, 230, 230)
+	}
+	pdf.SetFont(pdfFontFamily, style, 10)
+
+	for i, v := range cells {
+		text := tr(v)
+		for len(text) > 0 && pdf.GetStringWidth(text) > widths[i]-2 {
+			text = text[:len(text)-1]
+		}
+
+		ln := 0
+		if i == len(cells)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(widths[i], pdfTableHeight, text, "1", ln, aligns[i], header, 0, "")
+	}
+}
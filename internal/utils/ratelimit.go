@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrTooManyRequests = errors.New("terlalu banyak permintaan, silakan coba lagi nanti")
+
+// RateLimitMiddleware refuses with 429 the requests of an ip beyond the rule within its sliding window. The name separates
+// the windows of the routes sharing a limiter. A failing store lets the requests through, so an outage of the store does not take the routes down
+func RateLimitMiddleware(limiter *ratelimit.Limiter, name string, rule ratelimit.Rule) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		res, err := limiter.Allow(ctx.Context(), fmt.Sprintf("%s:ip:%s", name, ctx.IP()), rule)
+		if err != nil {
+			helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
+			return ctx.Next()
+		}
+
+		if !res.Allowed {
+			return tooManyRequestsResponse(ctx, res.RetryAfter)
+		}
+		return ctx.Next()
+	}
+}
+
+// tooManyRequestsResponse refuses the request with 429, telling the client when to retry
+func tooManyRequestsResponse(ctx *fiber.Ctx, retryAfter time.Duration) error {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return helper.ResponseWithJSON(&helper.JSONRespArgs{
+		Ctx:        ctx,
+		StatusCode: fiber.StatusTooManyRequests,
+		Errors:     []string{ErrTooManyRequests.Error()},
+	})
+}
@@ -2,42 +2,27 @@ package utils
 
 import (
 	"fmt"
-	"strconv"
+	"math"
 	"tugas_akhir_example/internal/daos"
-	"tugas_akhir_example/internal/helper"
+	"tugas_akhir_example/internal/infrastructure/search"
 	"tugas_akhir_example/internal/pkg/dto"
 )
 
 // ProdukToProdukResp parses the produk database data into produk respond data
 func ProdukToProdukResp(data *daos.Produk) (res *dto.ProdukResp, err error) {
-	hargaKonsumen, err := strconv.Atoi(data.HargaKonsumen)
-	if err != nil {
-		helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return nil, err
-	}
-
-	hargaReseller, err := strconv.Atoi(data.HargaReseller)
-	if err != nil {
-		helper.Logger(GetFunctionPath(), helper.LoggerLevelError, fmt.Sprintf("Error : %s", err.Error()))
-		return nil, err
-	}
-
 	photos := []dto.FotoProdukResp{}
 	for _, fotoProduk := range data.FotoProduks {
-		photos = append(photos, dto.FotoProdukResp{
-			ID:       fotoProduk.ID,
-			ProdukId: fotoProduk.IdProduk,
-			Url:      fotoProduk.Url,
-		})
+		photos = append(photos, *FotoProdukToFotoProdukResp(fotoProduk))
 	}
 
 	res = &dto.ProdukResp{
 		Id:            data.ID,
 		NamaProduk:    data.NamaProduk,
 		Slug:          data.Slug,
-		HargaKonsumen: hargaKonsumen,
-		HargaReseller: hargaReseller,
+		HargaKonsumen: data.HargaKonsumen,
+		HargaReseller: data.HargaReseller,
 		Stok:          data.Stok,
+		Berat:         data.Berat,
 		Deskripsi:     data.Deskripsi,
 		Toko: dto.TokoResp{
 			ID:       data.Toko.ID,
@@ -48,12 +33,86 @@ func ProdukToProdukResp(data *daos.Produk) (res *dto.ProdukResp, err error) {
 			ID:           data.Category.ID,
 			NamaCategory: data.Category.NamaCategory,
 		},
-		Photo: photos,
+		Photo:    photos,
+		Variants: []*dto.ProdukVariantResp{},
+	}
+
+	for _, v := range data.Variants {
+		res.Variants = append(res.Variants, ProdukVariantToProdukVariantResp(v, data))
+	}
+
+	if data.RatingCounts != nil {
+		res.Rating = RatingCountsToRatingResp(data.RatingCounts)
+	}
+
+	return res, nil
+}
+
+// SearchResultToProdukSearchResp parses the search result and the produk database data of its hits into produk search respond data
+func SearchResultToProdukSearchResp(result *search.Result, data []*daos.Produk) (res *dto.ProdukSearchResp, err error) {
+	res = &dto.ProdukSearchResp{
+		Total:   result.Total,
+		Keyword: result.Keyword,
+		Data:    []*dto.ProdukResp{},
+		Facets: &dto.ProdukSearchFacets{
+			Category: []*dto.SearchFacetResp{},
+			Toko:     []*dto.SearchFacetResp{},
+			Harga:    []*dto.SearchHargaFacetResp{},
+		},
+	}
+
+	for _, v := range data {
+		produkResp, err := ProdukToProdukResp(v)
+		if err != nil {
+			return nil, err
+		}
+		res.Data = append(res.Data, produkResp)
+	}
+
+	for _, v := range result.Categories {
+		res.Facets.Category = append(res.Facets.Category, &dto.SearchFacetResp{Id: v.Id, Nama: v.Nama, Count: v.Count})
+	}
+
+	for _, v := range result.Tokos {
+		res.Facets.Toko = append(res.Facets.Toko, &dto.SearchFacetResp{Id: v.Id, Nama: v.Nama, Count: v.Count})
+	}
+
+	for _, v := range result.HargaBuckets {
+		hargaResp := &dto.SearchHargaFacetResp{Min: v.Min, Count: v.Count}
+		if v.Max > 0 {
+			max := v.Max
+			hargaResp.Max = &max
+		}
+		res.Facets.Harga = append(res.Facets.Harga, hargaResp)
 	}
 
 	return res, nil
 }
 
+// ProdukVariantToProdukVariantResp parses the produkvariant database data of the produk into produkvariant respond data
+func ProdukVariantToProdukVariantResp(data *daos.ProdukVariant, produk *daos.Produk) (res *dto.ProdukVariantResp) {
+	res = &dto.ProdukVariantResp{
+		Id:            data.ID,
+		ProdukId:      data.IdProduk,
+		Sku:           data.Sku,
+		Opsi:          data.Opsi,
+		HargaReseller: data.HargaResellerOf(produk),
+		HargaKonsumen: data.HargaKonsumenOf(produk),
+		Stok:          data.Stok,
+		Photos:        []*dto.FotoProdukVariantResp{},
+	}
+
+	for _, v := range data.FotoProdukVariants {
+		res.Photos = append(res.Photos, &dto.FotoProdukVariantResp{
+			ID:        v.ID,
+			VariantId: v.IdProdukVariant,
+			Url:       v.Url,
+		})
+	}
+
+	return res
+}
+
 func ProdukArrayToAllProdukResp(data []*daos.Produk) (res *dto.AllProdukResp, err error) {
 	res = &dto.AllProdukResp{
 		Data: []*dto.ProdukResp{},
@@ -88,14 +147,18 @@ func TrxToTrxResp(data *daos.Trx) (res *dto.TrxResp, err error) {
 			},
 			Kuantitas:  v.Kuantitas,
 			HargaTotal: v.HargaTotal,
+			Diskon:     v.Diskon,
 		})
 	}
 
 	res = &dto.TrxResp{
 		Id:          data.ID,
 		HargaTotal:  data.HargaTotal,
+		OngkirTotal: data.OngkirTotal,
+		Diskon:      data.Diskon,
 		KodeInvoice: data.KodeInvoice,
 		MethodBayar: data.MethodBayar,
+		Status:      data.Status,
 		AlamatKirim: &dto.AlamatResp{
 			Id:           data.Alamat.ID,
 			JudulAlamat:  data.Alamat.JudulAlamat,
@@ -105,9 +168,56 @@ func TrxToTrxResp(data *daos.Trx) (res *dto.TrxResp, err error) {
 		},
 		DetailTrxes: detailTrxResps,
 	}
+
+	if data.Payment != nil {
+		res.Payment = PaymentToPaymentResp(data.Payment)
+	}
+
+	if data.Voucher != nil {
+		res.KodeVoucher = data.Voucher.Kode
+	}
+
+	res.Pengirimans = []*dto.TrxPengirimanResp{}
+	for _, v := range data.Pengirimans {
+		res.Pengirimans = append(res.Pengirimans, TrxPengirimanToTrxPengirimanResp(v))
+	}
 	return res, nil
 }
 
+// TrxPengirimanToTrxPengirimanResp parses the trx pengiriman database data into trx pengiriman respond data
+func TrxPengirimanToTrxPengirimanResp(data *daos.TrxPengiriman) (res *dto.TrxPengirimanResp) {
+	res = &dto.TrxPengirimanResp{
+		Kurir:   data.Kurir,
+		Layanan: data.Layanan,
+		Berat:   data.Berat,
+		Ongkir:  data.Ongkir,
+		Etd:     data.Etd,
+		Status:  data.Status,
+	}
+
+	if data.Toko != nil {
+		res.Toko = &dto.TokoResp{
+			ID:       data.Toko.ID,
+			NamaToko: data.Toko.NamaToko,
+			UrlFoto:  data.Toko.UrlFoto,
+		}
+	}
+	return res
+}
+
+// PaymentToPaymentResp parses the payment database data into payment respond data
+func PaymentToPaymentResp(data *daos.Payment) (res *dto.PaymentResp) {
+	return &dto.PaymentResp{
+		Provider:  data.Provider,
+		Reference: data.Reference,
+		Amount:    data.Amount,
+		Status:    data.Status,
+		Instruksi: data.Instruksi,
+		ExpiredAt: data.ExpiredAt,
+		PaidAt:    data.PaidAt,
+	}
+}
+
 // TrxArrayToAllTrxResp parses the trx database data into alltrx respond data
 func TrxArrayToAllTrxResp(data []*daos.Trx) (res *dto.AllTrxResp, err error) {
 	res = &dto.AllTrxResp{
@@ -125,33 +235,44 @@ func TrxArrayToAllTrxResp(data []*daos.Trx) (res *dto.AllTrxResp, err error) {
 	return res, nil
 }
 
-// LogProdukToLogProdukResp parses the logproduk database data into logproduk respond data
-func LogProdukToLogProdukResp(data *daos.LogProduk) (res *dto.LogProdukResp, err error) {
-	hargaReseller, err := strconv.Atoi(data.HargaReseller)
-	if err != nil {
-		return nil, err
+// FotoProdukToFotoProdukResp parses the fotoproduk database data into fotoproduk respond data
+func FotoProdukToFotoProdukResp(data *daos.FotoProduk) (res *dto.FotoProdukResp) {
+	res = &dto.FotoProdukResp{
+		ID:       data.ID,
+		ProdukId: data.IdProduk,
+		Url:      data.Url,
 	}
 
-	hargaKonsumen, err := strconv.Atoi(data.HargaKonsumen)
-	if err != nil {
-		return nil, err
+	if len(data.Sizes) > 0 {
+		res.Sizes = map[string]*dto.FotoSizeResp{}
+		for name, v := range data.Sizes {
+			res.Sizes[name] = &dto.FotoSizeResp{
+				Url:    v.Url,
+				Width:  v.Width,
+				Height: v.Height,
+			}
+		}
 	}
 
+	return res
+}
+
+// LogProdukToLogProdukResp parses the logproduk database data into logproduk respond data
+func LogProdukToLogProdukResp(data *daos.LogProduk) (res *dto.LogProdukResp, err error) {
 	photos := []*dto.FotoProdukResp{}
 	for _, v := range data.Produk.FotoProduks {
-		photos = append(photos, &dto.FotoProdukResp{
-			ID:       v.ID,
-			ProdukId: v.IdProduk,
-			Url:      v.Url,
-		})
+		photos = append(photos, FotoProdukToFotoProdukResp(v))
 	}
 
 	res = &dto.LogProdukResp{
 		Id:            data.ID,
 		NamaProduk:    data.NamaProduk,
 		Slug:          data.Slug,
-		HargaReseller: hargaReseller,
-		HargaKonsumen: hargaKonsumen,
+		VariantId:     data.IdVariant,
+		Sku:           data.Sku,
+		Opsi:          data.Opsi,
+		HargaReseller: data.HargaReseller,
+		HargaKonsumen: data.HargaKonsumen,
 		Deskripsi:     data.Deskripsi,
 		Toko: &dto.TokoResp{
 			ID:       data.Toko.ID,
@@ -172,10 +293,23 @@ func TokoToTokoResp(data *daos.Toko) (res *dto.TokoResp) {
 	res = &dto.TokoResp{
 		ID:       data.ID,
 		NamaToko: data.NamaToko,
+		Slug:     data.Slug,
 		UrlFoto:  data.UrlFoto,
 		UserId:   data.IdUser,
 	}
 
+	if data.RatingCounts != nil {
+		res.Rating = RatingCountsToRatingResp(data.RatingCounts)
+	}
+
+	if data.FollowerCounts != nil {
+		jumlahFollower := int64(0)
+		for _, v := range data.FollowerCounts {
+			jumlahFollower += v.Jumlah
+		}
+		res.JumlahFollower = &jumlahFollower
+	}
+
 	return res
 }
 
@@ -239,6 +373,9 @@ func UserToUserResp(data *daos.User) (res *dto.UserResp) {
 		Pekerjaan:    data.Pekerjaan,
 		Email:        data.Email,
 		Alamats:      alamats,
+
+		NotelpVerified: data.NotelpVerifiedAt != nil,
+		EmailVerified:  data.EmailVerifiedAt != nil,
 	}
 
 	return res
@@ -256,3 +393,252 @@ func AlamatToAlamatResp(data *daos.Alamat) (res *dto.AlamatResp) {
 
 	return res
 }
+
+// TrxStatusHistoryToTrxStatusHistoryResp parses the trx status history database data into trx status history respond data
+func TrxStatusHistoryToTrxStatusHistoryResp(data *daos.TrxStatusHistory) (res *dto.TrxStatusHistoryResp) {
+	res = &dto.TrxStatusHistoryResp{
+		Id:         data.ID,
+		UserId:     data.IdUser,
+		TokoId:     data.IdToko,
+		Peran:      data.Peran,
+		StatusLama: data.StatusLama,
+		StatusBaru: data.StatusBaru,
+		Alasan:     data.Alasan,
+		CreatedAt:  data.CreatedAt,
+	}
+
+	return res
+}
+
+// CartToCartResp parses the cart database data into cart respond data grouped by toko, re-validating the price and stok of every item
+func CartToCartResp(data *daos.Cart) (res *dto.CartResp, err error) {
+	res = &dto.CartResp{
+		Tokos:        []*dto.CartTokoResp{},
+		Checkoutable: len(data.CartItems) > 0,
+	}
+
+	tokoResps := map[uint]*dto.CartTokoResp{}
+	for _, v := range data.CartItems {
+		itemResp := &dto.CartItemResp{
+			Id:                   v.ID,
+			ProductId:            v.IdProduk,
+			VariantId:            v.IdVariant,
+			Kuantitas:            v.Kuantitas,
+			HargaSaatDitambahkan: v.HargaKonsumen,
+			Photos:               []*dto.FotoProdukResp{},
+		}
+
+		var tokoResp *dto.TokoResp
+		if v.Produk == nil {
+			itemResp.Pesan = "produk sudah tidak tersedia"
+		} else {
+			itemResp.NamaProduk = v.Produk.NamaProduk
+			itemResp.Slug = v.Produk.Slug
+			hargaKonsumen, stok := v.Produk.HargaKonsumen, v.Produk.Stok
+			if v.Variant != nil {
+				itemResp.Sku = v.Variant.Sku
+				itemResp.Opsi = v.Variant.Opsi
+				hargaKonsumen, stok = v.Variant.HargaKonsumenOf(v.Produk), v.Variant.Stok
+			}
+
+			itemResp.HargaKonsumen = hargaKonsumen
+			itemResp.HargaBerubah = hargaKonsumen != v.HargaKonsumen
+			itemResp.Stok = stok
+			itemResp.Tersedia = stok >= v.Kuantitas
+			if v.IdVariant != nil && v.Variant == nil {
+				itemResp.Tersedia = false
+				itemResp.Pesan = "varian produk sudah tidak tersedia"
+			} else if !itemResp.Tersedia {
+				itemResp.Pesan = fmt.Sprintf("stok tidak mencukupi, tersisa %d", stok)
+			} else {
+				itemResp.HargaTotal = hargaKonsumen.Mul(v.Kuantitas).Int()
+			}
+
+			for _, foto := range v.Produk.FotoProduks {
+				itemResp.Photos = append(itemResp.Photos, FotoProdukToFotoProdukResp(foto))
+			}
+
+			if v.Produk.Toko != nil {
+				tokoResp = TokoToTokoResp(v.Produk.Toko)
+			}
+		}
+
+		var tokoId uint
+		if tokoResp != nil {
+			tokoId = tokoResp.ID
+		}
+
+		cartTokoResp, ok := tokoResps[tokoId]
+		if !ok {
+			cartTokoResp = &dto.CartTokoResp{
+				Toko:  tokoResp,
+				Items: []*dto.CartItemResp{},
+			}
+			tokoResps[tokoId] = cartTokoResp
+			res.Tokos = append(res.Tokos, cartTokoResp)
+		}
+
+		cartTokoResp.Items = append(cartTokoResp.Items, itemResp)
+		cartTokoResp.HargaTotal += itemResp.HargaTotal
+		res.HargaTotal += itemResp.HargaTotal
+		res.TotalKuantitas += v.Kuantitas
+		if !itemResp.Tersedia {
+			res.Checkoutable = false
+		}
+	}
+
+	return res, nil
+}
+
+// DetailTrxToTokoOrderResp parses the detailtrx database data into toko order respond data, giving the status of the pengiriman of the toko when the trx has one
+func DetailTrxToTokoOrderResp(data *daos.DetailTrx) (res *dto.TokoOrderResp, err error) {
+	logProdukResp, err := LogProdukToLogProdukResp(data.LogProduk)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &dto.TokoOrderResp{
+		Id:          data.ID,
+		TrxId:       data.IdTrx,
+		KodeInvoice: data.Trx.KodeInvoice,
+		Status:      data.Trx.Status,
+		MethodBayar: data.Trx.MethodBayar,
+		TanggalTrx:  data.Trx.CreatedAt,
+		LogProduk:   logProdukResp,
+		Kuantitas:   data.Kuantitas,
+		HargaTotal:  data.HargaTotal,
+		Diskon:      data.Diskon,
+	}
+
+	if data.Trx.Alamat != nil {
+		res.AlamatKirim = AlamatToAlamatResp(data.Trx.Alamat)
+	}
+
+	for _, v := range data.Trx.Pengirimans {
+		if v.IdToko == data.IdToko {
+			res.Status = v.Status
+		}
+	}
+
+	return res, nil
+}
+
+// VoucherToVoucherResp parses the voucher database data into voucher respond data
+func VoucherToVoucherResp(data *daos.Voucher) (res *dto.VoucherResp) {
+	res = &dto.VoucherResp{
+		Id:            data.ID,
+		Kode:          data.Kode,
+		Deskripsi:     data.Deskripsi,
+		Tipe:          data.Tipe,
+		Nilai:         data.Nilai,
+		MinBelanja:    data.MinBelanja,
+		MaxDiskon:     data.MaxDiskon,
+		KuotaGlobal:   data.KuotaGlobal,
+		KuotaPerUser:  data.KuotaPerUser,
+		Terpakai:      data.Terpakai,
+		BerlakuMulai:  DateToString(data.BerlakuMulai),
+		BerlakuSampai: DateToString(data.BerlakuSampai),
+		Aktif:         data.Aktif == nil || *data.Aktif,
+	}
+
+	if data.Toko != nil {
+		res.Toko = &dto.TokoResp{
+			ID:       data.Toko.ID,
+			NamaToko: data.Toko.NamaToko,
+			UrlFoto:  data.Toko.UrlFoto,
+		}
+	}
+
+	if data.Category != nil {
+		res.Category = &dto.CategoryResp{
+			ID:           data.Category.ID,
+			NamaCategory: data.Category.NamaCategory,
+		}
+	}
+	return res
+}
+
+// RatingCountsToRatingResp aggregates the rating counts into rating respond data
+func RatingCountsToRatingResp(data []*daos.RatingCount) (res *dto.RatingResp) {
+	res = &dto.RatingResp{
+		PerBintang: map[int]int64{},
+	}
+	for rating := daos.RatingMin; rating <= daos.RatingMax; rating++ {
+		res.PerBintang[rating] = 0
+	}
+
+	total := int64(0)
+	for _, v := range data {
+		res.PerBintang[v.Rating] += v.Jumlah
+		res.Jumlah += v.Jumlah
+		total += int64(v.Rating) * v.Jumlah
+	}
+
+	if res.Jumlah > 0 {
+		res.RataRata = math.Round(float64(total)/float64(res.Jumlah)*10) / 10
+	}
+
+	return res
+}
+
+// ReviewToReviewResp parses the review database data into review respond data
+func ReviewToReviewResp(data *daos.Review) (res *dto.ReviewResp) {
+	res = &dto.ReviewResp{
+		Id:          data.ID,
+		DetailTrxId: data.IdDetailTrx,
+		ProdukId:    data.IdProduk,
+		TokoId:      data.IdToko,
+		UserId:      data.IdUser,
+		Rating:      data.Rating,
+		Ulasan:      data.Ulasan,
+		Balasan:     data.Balasan,
+		DibalasAt:   data.DibalasAt,
+		CreatedAt:   data.CreatedAt,
+		Photos:      []*dto.FotoReviewResp{},
+	}
+
+	if data.User != nil {
+		res.NamaUser = data.User.Nama
+	}
+
+	for _, v := range data.FotoReviews {
+		res.Photos = append(res.Photos, &dto.FotoReviewResp{
+			ID:       v.ID,
+			ReviewId: v.IdReview,
+			Url:      v.Url,
+		})
+	}
+
+	return res
+}
+
+// WishlistToWishlistResp parses the wishlist database data along with its current produk data into wishlist respond data
+func WishlistToWishlistResp(data *daos.Wishlist) (res *dto.WishlistResp) {
+	res = &dto.WishlistResp{
+		Id:        data.ID,
+		ProductId: data.IdProduk,
+		CreatedAt: data.CreatedAt,
+		Photos:    []dto.FotoProdukResp{},
+	}
+
+	if data.Produk == nil {
+		res.Dihapus = true
+		return res
+	}
+
+	res.NamaProduk = data.Produk.NamaProduk
+	res.Slug = data.Produk.Slug
+	res.HargaKonsumen = data.Produk.HargaKonsumen
+	res.Stok = data.Produk.Stok
+	res.Dihapus = data.Produk.DeletedAt.Valid
+
+	if data.Produk.Toko != nil {
+		res.Toko = TokoToTokoResp(data.Produk.Toko)
+	}
+
+	for _, v := range data.Produk.FotoProduks {
+		res.Photos = append(res.Photos, *FotoProdukToFotoProdukResp(v))
+	}
+
+	return res
+}